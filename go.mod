@@ -0,0 +1,5 @@
+module bitbucket.org/tshannon/gonewton
+
+go 1.23
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -13,30 +13,30 @@ void setBodyLeaveWorldCB(NewtonWorld* newtonWorld) {
 	NewtonSetBodyLeaveWorldEvent(newtonWorld, (NewtonBodyLeaveWorld)goBodyLeaveWorldCB);
 }
 
-void setJointIteratorCB(NewtonWorld* newtonWorld, void* userData) {
-	NewtonWorldForEachJointDo(newtonWorld, (NewtonJointIterator)goJointIteratorCB, userData);
+void setJointIteratorCB(NewtonWorld* newtonWorld, uintptr_t userData) {
+	NewtonWorldForEachJointDo(newtonWorld, (NewtonJointIterator)goJointIteratorCB, (void*)userData);
 }
 
-void setBodyIteratorCB(NewtonWorld* newtonWorld, dFloat* p0, dFloat* p1, void* userData) {
+void setBodyIteratorCB(NewtonWorld* newtonWorld, dFloat* p0, dFloat* p1, uintptr_t userData) {
 	NewtonWorldForEachBodyInAABBDo(newtonWorld, p0, p1, 
-			(NewtonBodyIterator)goBodyIteratorCB, userData);
+			(NewtonBodyIterator)goBodyIteratorCB, (void*)userData);
 }
 
-void RayCast(NewtonWorld* world, dFloat* p0, dFloat* p1, void* userData) {
+void RayCast(NewtonWorld* world, dFloat* p0, dFloat* p1, uintptr_t userData) {
 	NewtonWorldRayCast(world, p0, p1, (NewtonWorldRayFilterCallback)goRayFilterCB, 
-			userData, (NewtonWorldRayPrefilterCallback)goRayPrefilterCB);
+			(void*)userData, (NewtonWorldRayPrefilterCallback)goRayPrefilterCB);
 }
 
 int ConvexCast(NewtonWorld* world, dFloat* matrix, dFloat* target, NewtonCollision* shape, 
-		dFloat* hitParam, void* userData, NewtonWorldConvexCastReturnInfo* info,
+		dFloat* hitParam, uintptr_t userData, NewtonWorldConvexCastReturnInfo* info,
 		 int maxContactsCount, int threadIndex) {
-	NewtonWorldConvexCast(world, matrix, target, shape, hitParam, userData, 
+	return NewtonWorldConvexCast(world, matrix, target, shape, hitParam, (void*)userData, 
 		(NewtonWorldRayPrefilterCallback)goRayPrefilterCB, info, maxContactsCount, threadIndex);
 
 }
 
-void SetCollisionCB(NewtonWorld* world, int id0, int id1, void* userData) {
-	NewtonMaterialSetCollisionCallback(world, id0, id1, userData, (NewtonOnAABBOverlap)goOnAABBOverlapCB, 
+void SetCollisionCB(NewtonWorld* world, int id0, int id1, uintptr_t userData) {
+	NewtonMaterialSetCollisionCallback(world, id0, id1, (void*)userData, (NewtonOnAABBOverlap)goOnAABBOverlapCB, 
 		(NewtonContactsProcess)goContactsProcessCB);
 }
 
@@ -62,9 +62,9 @@ void SetForceAndTorqueCallback(NewtonBody* body) {
 }
 
 void AddBuoyancyForce(NewtonBody* body, dFloat fluidDensity, dFloat fluidLinearViscosity, 
-			dFloat fluidAngularViscosity, dFloat* gravityVector , void* context) {
+			dFloat fluidAngularViscosity, dFloat* gravityVector , uintptr_t context) {
 	NewtonBodyAddBuoyancyForce(body, fluidDensity, fluidLinearViscosity, fluidAngularViscosity,
-			gravityVector, (NewtonGetBuoyancyPlane)goBuoyancyPlaneCallback, context);
+			gravityVector, (NewtonGetBuoyancyPlane)goBuoyancyPlaneCallback, (void*)context);
 }
 
 void SetConstraintDestructor(NewtonJoint* joint) {
@@ -98,14 +98,31 @@ NewtonMesh* MeshApproximateConvexDecomposition(NewtonMesh* mesh, dFloat maxConca
 	return NewtonMeshApproximateConvexDecomposition(mesh, maxConcavity, backFaceDistanceFactor, maxCount, maxVertexPerHull, (NewtonReportProgress)goReportProgress);
 }
 
-void setForEachPolygonDo(NewtonCollision* collision, dFloat* matrix, void* userData) {
-	NewtonCollisionForEachPolygonDo(collision, matrix, (NewtonCollisionIterator)goNewtonCollisionIterator, userData);
+void setForEachPolygonDo(NewtonCollision* collision, dFloat* matrix, uintptr_t userData) {
+	NewtonCollisionForEachPolygonDo(collision, matrix, (NewtonCollisionIterator)goNewtonCollisionIterator, (void*)userData);
 }
 
-NewtonCollision* createCollisionFromSerialization(NewtonWorld* world ,void* handle) {
-	return NewtonCreateCollisionFromSerialization(world, (NewtonDeserializeCallback)goNewtonDeserializeCallback, handle);	
+NewtonCollision* createCollisionFromSerialization(NewtonWorld* world, uintptr_t handle) {
+	return NewtonCreateCollisionFromSerialization(world, (NewtonDeserializeCallback)goNewtonDeserializeCallback, (void*)handle);	
 }
 
-void serializeCollision(NewtonWorld* world, NewtonCollision* collision, void* handle) {
-	NewtonCollisionSerialize(world, collision, (NewtonSerializeCallback)goNewtonSerializeCallback, handle);
+void serializeCollision(NewtonWorld* world, NewtonCollision* collision, uintptr_t handle) {
+	NewtonCollisionSerialize(world, collision, (NewtonSerializeCallback)goNewtonSerializeCallback, (void*)handle);
+}
+
+static void collisionCopyConstructor(const NewtonWorld* world, NewtonCollision* collision, const NewtonCollision* sourceCollision) {
+}
+
+void setCollisionDestructorCB(NewtonWorld* world) {
+	NewtonWorldSetCollisionConstructorDestuctorCallback(world, collisionCopyConstructor, 
+		(NewtonCollisionDestructorCallback)goCollisionDestructor);
+}
+
+NewtonCollision* CreateUserMeshCollision(NewtonWorld* world, dFloat* minBox, dFloat* maxBox, uintptr_t userData,
+	int shapeID) {
+	return NewtonCreateUserMeshCollision(world, minBox, maxBox, (void*)userData,
+		(NewtonUserMeshCollisionCollideCallback)goUserMeshCollide,
+		(NewtonUserMeshCollisionRayHitCallback)goUserMeshRayHit,
+		(NewtonUserMeshCollisionDestroyCallback)goUserMeshDestroy, NULL,
+		(NewtonUserMeshCollisionAABBTest)goUserMeshAABBTest, NULL, shapeID);
 }
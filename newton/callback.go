@@ -12,6 +12,7 @@ package newton
 import "C"
 import (
 	"reflect"
+	"sync"
 	"unsafe"
 )
 
@@ -19,9 +20,9 @@ import (
 // be hardcoded to one function, I have to do a look up
 // in the function to the proper function pointer based on
 // the limited data in that callback.
-// callbackOwners will contain a map of functions and their owners
+// The registry (see registry.go) will contain the functions and their owners
 // so the proper callback can be looked up on the return into Go code
-// using only the owners pointer
+// using only the owners pointer, or the userData passed along with a query
 
 type owner unsafe.Pointer
 
@@ -47,31 +48,25 @@ func goBytes(cArray unsafe.Pointer, size int) []byte {
 	return slice
 }
 
+//go16Floats, go3Floats and go4Floats copy the c array into a go array
+
 func go16Floats(cArray *C.dFloat) *[16]float32 {
 	gArray := [16]float32{}
-	slice := gArray[:]
-
-	prepSlice(unsafe.Pointer(&slice), unsafe.Pointer(cArray), 16)
+	copy(gArray[:], goFloats(cArray, 16))
 
 	return &gArray
 }
 
 func go3Floats(cArray *C.dFloat) *[3]float32 {
 	gArray := [3]float32{}
-
-	slice := gArray[:]
-
-	prepSlice(unsafe.Pointer(&slice), unsafe.Pointer(cArray), 3)
+	copy(gArray[:], goFloats(cArray, 3))
 
 	return &gArray
 }
 
 func go4Floats(cArray *C.dFloat) *[4]float32 {
 	gArray := [4]float32{}
-
-	slice := gArray[:]
-
-	prepSlice(unsafe.Pointer(&slice), unsafe.Pointer(cArray), 4)
+	copy(gArray[:], goFloats(cArray, 4))
 
 	return &gArray
 }
@@ -85,101 +80,122 @@ func goFloat64s(cArray *C.double, size int) []float64 {
 
 type GetTicksCountHandler func() uint32
 
-var getTicksCount GetTicksCountHandler
+//Newton doesn't pass anything to the performance clock that could identify the
+// world, so there's only ever one clock shared by every world
+var getTicksCount struct {
+	sync.RWMutex
+	f GetTicksCountHandler
+}
 
 //export goGetTicksCountCB
 func goGetTicksCountCB() uint32 {
-	return getTicksCount()
+	getTicksCount.RLock()
+	defer getTicksCount.RUnlock()
+	return getTicksCount.f()
 }
 
 func (w *World) SetPerformanceClock(f GetTicksCountHandler) {
-	getTicksCount = f
-	C.setGetTicksCountCB(w.handle)
+	getTicksCount.Lock()
+	getTicksCount.f = f
+	getTicksCount.Unlock()
+	C.setGetTicksCountCB(w.ptr())
 }
 
 type BodyLeaveWorldHandler func(body *Body, threadIndex int)
 
-var bodyLeaveWorldOwners = make(map[owner]BodyLeaveWorldHandler)
-
 //export goBodyLeaveWorldCB
 func goBodyLeaveWorldCB(body *C.NewtonBody, threadIndex C.int) {
-	b := &Body{body}
+	b := newBody(body)
 
 	//owner is world, look up world from body
 	// unfortunately needs an additional c call
-	bodyLeaveWorldOwners[owner(b.World().handle)](b, int(threadIndex))
+	cb := b.World().callbacks()
+	cb.RLock()
+	f := cb.bodyLeaveWorld
+	cb.RUnlock()
+
+	if f != nil {
+		f(b, int(threadIndex))
+	}
 }
 
 func (w *World) SetBodyLeaveWorldEvent(f BodyLeaveWorldHandler) {
-	bodyLeaveWorldOwners[owner(w.handle)] = f
-	C.setBodyLeaveWorldCB(w.handle)
+	cb := w.callbacks()
+	cb.Lock()
+	cb.bodyLeaveWorld = f
+	cb.Unlock()
+	C.setBodyLeaveWorldCB(w.ptr())
 }
 
 type JointIteratorHandler func(joint *Joint, userData interface{})
 
-var jointIterator JointIteratorHandler
-
 //export goJointIteratorCB
 func goJointIteratorCB(joint *C.NewtonJoint, userData unsafe.Pointer) {
-	j := &Joint{joint}
+	j := newJoint(joint)
+	c := callFromUserData(userData)
 
-	jointIterator(j, ownerData[owner(userData)])
+	c.jointIterator(j, c.userData)
 }
 
 func (w *World) ForEachJointDo(f JointIteratorHandler, userData interface{}) {
-	jointIterator = f
-	ownerData[owner(&userData)] = userData
-	C.setJointIteratorCB(w.handle, unsafe.Pointer(&userData))
+	c := &call{jointIterator: f, userData: userData}
+	defer c.end()
+	C.setJointIteratorCB(w.ptr(), c.begin())
 }
 
 type BodyIteratorHandler func(body *Body, userData interface{})
 
-var bodyIterator BodyIteratorHandler
-
 //export goBodyIteratorCB
 func goBodyIteratorCB(body *C.NewtonBody, userData unsafe.Pointer) {
-	b := &Body{body}
-	bodyIterator(b, ownerData[owner(userData)])
+	b := newBody(body)
+	c := callFromUserData(userData)
+
+	c.bodyIterator(b, c.userData)
 }
 
 func (w *World) ForEachBodyInAABBDo(p0, p1 *[3]float32, f BodyIteratorHandler, userData interface{}) {
-	bodyIterator = f
-	ownerData[owner(&userData)] = userData
-	C.setBodyIteratorCB(w.handle, (*C.dFloat)(&p0[0]), (*C.dFloat)(&p1[0]), unsafe.Pointer(&userData))
+	c := &call{bodyIterator: f, userData: userData}
+	defer c.end()
+	C.setBodyIteratorCB(w.ptr(), (*C.dFloat)(&p0[0]), (*C.dFloat)(&p1[0]), c.begin())
 }
 
 type RayFilterHandler func(body *Body, hitNormal *[3]float32, collisionID int,
 	userData interface{}, intersectParam float32) float32
 
-var rayFilter RayFilterHandler
-
 //export goRayFilterCB
 func goRayFilterCB(body *C.NewtonBody, hitNormal *C.dFloat, collisionID C.int,
 	userData unsafe.Pointer, intersectParam C.dFloat) C.dFloat {
-	b := &Body{body}
+	b := newBody(body)
+	c := callFromUserData(userData)
 
-	return C.dFloat(rayFilter(b, go3Floats(hitNormal), int(collisionID),
-		ownerData[owner(userData)], float32(intersectParam)))
+	//no filter means the ray keeps going, as if the hit was ignored
+	if c.rayFilter == nil {
+		return intersectParam
+	}
+	return C.dFloat(c.rayFilter(b, go3Floats(hitNormal), int(collisionID),
+		c.userData, float32(intersectParam)))
 }
 
 type RayPrefilterHandler func(body *Body, collision *Collision, userData interface{}) uint
 
-var rayPrefilter RayPrefilterHandler
-
 //export goRayPrefilterCB
 func goRayPrefilterCB(body *C.NewtonBody, collision *C.NewtonCollision, userData unsafe.Pointer) C.unsigned {
-	b := &Body{body}
-	gCollision := &Collision{collision}
+	b := newBody(body)
+	gCollision := newCollision(nil, collision)
+	c := callFromUserData(userData)
 
-	return C.unsigned(rayPrefilter(b, gCollision, ownerData[owner(userData)]))
+	//no prefilter means every body is tested
+	if c.rayPrefilter == nil {
+		return 1
+	}
+	return C.unsigned(c.rayPrefilter(b, gCollision, c.userData))
 }
 
 func (w *World) RayCast(p0 *[3]float32, p1 *[3]float32, filter RayFilterHandler, userData interface{},
 	prefilter RayPrefilterHandler) {
-	rayFilter = filter
-	rayPrefilter = prefilter
-	ownerData[owner(&userData)] = userData
-	C.RayCast(w.handle, (*C.dFloat)(&p0[0]), (*C.dFloat)(&p1[0]), unsafe.Pointer(&userData))
+	c := &call{rayFilter: filter, rayPrefilter: prefilter, userData: userData}
+	defer c.end()
+	C.RayCast(w.ptr(), (*C.dFloat)(&p0[0]), (*C.dFloat)(&p1[0]), c.begin())
 }
 
 type ConvexCastReturnInfo struct {
@@ -196,17 +212,16 @@ type ConvexCastReturnInfo struct {
 func (w *World) ConvexCast(matrix *[16]float32, target *[16]float32, shape *Collision, hitParam *float32,
 	userData interface{}, prefilter RayPrefilterHandler, maxContactsCount int, threadIndex int) []*ConvexCastReturnInfo {
 
-	rayPrefilter = prefilter
-
-	ownerData[owner(&userData)] = userData
+	c := &call{rayPrefilter: prefilter, userData: userData}
+	defer c.end()
 
 	var size int
 	//all of this allocation may be a performance issue
 	// might have to reapproach this
 	cInfo := make([]C.NewtonWorldConvexCastReturnInfo, maxContactsCount)
 
-	size = int(C.ConvexCast(w.handle, (*C.dFloat)(&matrix[0]), (*C.dFloat)(&target[0]), shape.handle,
-		(*C.dFloat)(hitParam), unsafe.Pointer(&userData), &cInfo[0], C.int(maxContactsCount),
+	size = int(C.ConvexCast(w.ptr(), (*C.dFloat)(&matrix[0]), (*C.dFloat)(&target[0]), shape.ptr(),
+		(*C.dFloat)(hitParam), c.begin(), &cInfo[0], C.int(maxContactsCount),
 		C.int(threadIndex)))
 
 	returnInfo := make([]*ConvexCastReturnInfo, size)
@@ -218,7 +233,7 @@ func (w *World) ConvexCast(matrix *[16]float32, target *[16]float32, shape *Coll
 			NormalOnHitPoint: go4Floats(&cInfo[i].m_normalOnHitPoint[0]),
 			Penetration:      float32(cInfo[i].m_penetration),
 			ContactID:        int(cInfo[i].m_contactID),
-			HitBody:          &Body{cInfo[i].m_hitBody},
+			HitBody:          newBody(cInfo[i].m_hitBody),
 		}
 
 	}
@@ -228,358 +243,460 @@ func (w *World) ConvexCast(matrix *[16]float32, target *[16]float32, shape *Coll
 
 type OnAABBOverlapHandler func(material *Material, body0, body1 *Body, threadIndex int) int
 
-//Not associated to a world for now, this may be an issue with
-// multiple worlds
-var onAABBOverlap OnAABBOverlapHandler
-
 //export goOnAABBOverlapCB
 func goOnAABBOverlapCB(material *C.NewtonMaterial, body0, body1 *C.NewtonBody, threadIndex C.int) C.int {
 	gMaterial := &Material{material}
-	b0 := &Body{body0}
-	b1 := &Body{body1}
+	b0 := newBody(body0)
+	b1 := newBody(body1)
+	if !b0.CanCollide(b1) {
+		return 0
+	}
+
+	callback := materialCallbackFromUserData(C.NewtonMaterialGetMaterialPairUserData(material))
+	if callback == nil || callback.onAABBOverlap == nil {
+		return 1
+	}
 
-	return C.int(onAABBOverlap(gMaterial, b0, b1, int(threadIndex)))
+	return C.int(callback.onAABBOverlap(gMaterial, b0, b1, int(threadIndex)))
 }
 
 type ContactsProcessHandler func(contact *Joint, timestep float32, threadIndex int)
 
-var contactsProcess ContactsProcessHandler
-
 //export goContactsProcessCB
 func goContactsProcessCB(contact *C.NewtonJoint, timestep C.dFloat, threadIndex C.int) {
-	j := &Joint{contact}
-	contactsProcess(j, float32(timestep), int(threadIndex))
+	j := newContactJoint(contact)
+
+	//the material pair is found through the contacts' material
+	firstContact := C.NewtonContactJointGetFirstContact(contact)
+	if firstContact == nil {
+		return
+	}
+	//look the pair up before any contacts, and their material, are removed
+	material := C.NewtonContactGetMaterial(firstContact)
+	callback := materialCallbackFromUserData(C.NewtonMaterialGetMaterialPairUserData(material))
+
+	if callback != nil {
+		callback.world.applyOneWay(j)
+	}
+	if j.ContactCount() == 0 {
+		return
+	}
+	applySurfaceVelocity(j, float32(timestep))
+
+	if callback == nil || callback.contactsProcess == nil {
+		return
+	}
+
+	callback.contactsProcess(j, float32(timestep), int(threadIndex))
 }
 
+//SetMaterialCollisionCallback sets the collision handlers for a single material pair.
+// Each pair in each world keeps its own handlers; setting them again for the same
+// pair replaces the previous ones.  A non-nil userData is set as the pair's
+// MaterialUserData, nil leaves any data already set alone
 func (w *World) SetMaterialCollisionCallback(matid0, matid1 int, userData interface{},
 	overlap OnAABBOverlapHandler, contactsProcessor ContactsProcessHandler) {
-	onAABBOverlap = overlap
-	ownerData[owner(&userData)] = userData
-	contactsProcess = contactsProcessor
-	C.SetCollisionCB(w.handle, C.int(matid0), C.int(matid1), unsafe.Pointer(&userData))
-}
-
-type MeshCollisionCollideDesc struct {
-	M_boxP0               *[4]float32
-	M_boxP1               *[4]float32
-	M_boxDistanceTravel   *[4]float32
-	M_threadNumber        int
-	M_faceCount           int
-	M_vertexStrideInBytes int
-	M_skinThickness       float32
-	M_userData            interface{}
-	M_objBody             *Body
-	M_polySoupBody        *Body
-	M_objCollision        *Collision
-	M_polySoupCollision   *Collision
-	M_vertex              *[3]float32
-	M_faceIndexCount      *int
-	M_faceVertexIndex     *int
-}
-
-//Skip user mesh for now
-//If implemented...
-//Separate New user Mesh creation from some callbacks, so that if a user doesn't want to use
-// the callbacks, they aren't tied to the very expensive conversions that would be
-// tied to the global go callbacks
-
-//type MeshCollisionCollideHandler func(collideDescData *MeshCollisionCollideDesc)
-
-//export goMeshCollisionCollideCB
-//func goMeshCollisionCollideCB(collideDescData *C.NewtonUserMeshCollisionCollideDesc) {
-//goCollideDescData := &MeshCollisionCollideDesc{
-
-//}
-//gMeshCollisionCollide(
-//}
-
-//Skip heightfields for now
+	if userData != nil {
+		w.SetMaterialUserData(matid0, matid1, userData)
+	}
+
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	w.setMaterialCallback(cb, matid0, matid1, &materialCallback{
+		onAABBOverlap:   overlap,
+		contactsProcess: contactsProcessor,
+	})
+}
 
 type CollisionTreeRayCastCallback func(body *Body, treeCollision *Collision, interception float32,
 	normal *[3]float32, faceId int, userData interface{}) float32
 
-var collisionTreeRayOwners = make(map[owner]CollisionTreeRayCastCallback)
+var collisionTreeRayOwners = newHandlers[CollisionTreeRayCastCallback]()
 
 //export goCollisionTreeRayCastCallback
 func goCollisionTreeRayCastCallback(body *C.NewtonBody, treeCollision *C.NewtonCollision, interception C.dFloat,
 	normal *C.dFloat, faceId C.int, userData unsafe.Pointer) C.dFloat {
-	b := &Body{body}
-	col := &Collision{handle: treeCollision}
+	b := newBody(body)
+	col := newCollision(nil, treeCollision)
+
+	callback, ok := collisionTreeRayOwners.get(owner(treeCollision))
+	if !ok {
+		return interception
+	}
 
-	return C.dFloat(collisionTreeRayOwners[owner(treeCollision)](b, col, float32(interception), go3Floats(normal),
-		int(faceId), ownerData[owner(userData)]))
+	//userData is the one passed to the ray cast that hit the tree
+	var data interface{}
+	if userData != nil {
+		data = callFromUserData(userData).userData
+	}
+
+	return C.dFloat(callback(b, col, float32(interception), go3Floats(normal), int(faceId), data))
+}
+
+//export goCollisionDestructor
+func goCollisionDestructor(world *C.NewtonWorld, collision *C.NewtonCollision) {
+	collisionTreeRayOwners.remove(owner(collision))
+	treeCollisionOwners.remove(owner(collision))
+	ownerData.remove(owner(collision))
 }
 
 func (c *Collision) SetTreeRayCastCallback(callback CollisionTreeRayCastCallback) {
-	collisionTreeRayOwners[owner(c.handle)] = callback
+	collisionTreeRayOwners.set(owner(c.handle), callback)
 
-	C.SetUserRayCastCallback(c.handle)
+	C.SetUserRayCastCallback(c.ptr())
 }
 
 type TreeCollisionCallback func(bodyWithTreeCollision, body *Body, faceID, vertexCount int,
 	vertex []float32, vertexStrideInBytes int)
 
-var treeCollisionCallback TreeCollisionCallback
+var treeCollisionOwners = newHandlers[TreeCollisionCallback]()
 
 //export goTreeCollisionCallback
 func goTreeCollisionCallback(bodyWithTreeCollision, body *C.NewtonBody, faceID, vertextCount C.int,
 	vertex *C.dFloat, vertexStrideInBytes C.int) {
-	bWithTreeCollision := &Body{bodyWithTreeCollision}
-	b := &Body{body}
-	treeCollisionCallback(bWithTreeCollision, b, int(faceID), int(vertextCount),
+	bWithTreeCollision := newBody(bodyWithTreeCollision)
+	b := newBody(body)
+
+	callback, ok := treeCollisionOwners.get(owner(C.NewtonBodyGetCollision(bodyWithTreeCollision)))
+	if !ok {
+		return
+	}
+
+	callback(bWithTreeCollision, b, int(faceID), int(vertextCount),
 		goFloats(vertex, int(vertextCount*3)), int(vertexStrideInBytes))
 }
 
 func StaticCollisionSetDebugCallback(staticCollision *Collision, userCallback TreeCollisionCallback) {
-	treeCollisionCallback = userCallback
-	C.SetStaticCollisionDebugCallback(staticCollision.handle)
+	treeCollisionOwners.set(owner(staticCollision.handle), userCallback)
+	C.SetStaticCollisionDebugCallback(staticCollision.ptr())
 }
 
 type BodyDestructorCallback func(body *Body)
 
-var bodyDestructorCallbackOwners = make(map[owner]BodyDestructorCallback)
+var bodyDestructorCallbackOwners = newHandlers[BodyDestructorCallback]()
 
 //export goBodyDestructor
 func goBodyDestructor(body *C.NewtonBody) {
-	b := &Body{body}
+	b := destroyedBody(body)
+
+	if callback, ok := bodyDestructorCallbackOwners.get(owner(body)); ok {
+		callback(b)
+	}
 
-	bodyDestructorCallbackOwners[owner(body)](b)
+	//the body is gone, so is everything the go side held for it
+	bodyDestructorCallbackOwners.remove(owner(body))
+	transformCallbackOwners.remove(owner(body))
+	applyForceAndTorqueOwners.remove(owner(body))
+	interpolated.remove(owner(body))
+	forceAndTorqueNames.remove(owner(body))
+	transformNames.remove(owner(body))
+	ownerData.remove(owner(body))
+	surfaceVelocities.remove(owner(body))
+	removeCollisionFilter(b)
+	b.handle = nil
 }
 
 func (b *Body) SetDestructorCallback(callback BodyDestructorCallback) {
-	bodyDestructorCallbackOwners[owner(b.handle)] = callback
-	C.SetBodyDestructor(b.handle)
+	bodyDestructorCallbackOwners.set(owner(b.ptr()), callback)
 }
 
 func (b *Body) DestructorCallback() BodyDestructorCallback {
-	return bodyDestructorCallbackOwners[owner(b.handle)]
+	callback, _ := bodyDestructorCallbackOwners.get(owner(b.handle))
+	return callback
 }
 
 type TransformCallback func(body *Body, matrix *[16]float32, threadIndex int)
 
-var transformCallbackOwners = make(map[owner]TransformCallback)
+var transformCallbackOwners = newHandlers[TransformCallback]()
 
 //export goTransformCallback
 func goTransformCallback(body *C.NewtonBody, matrix *C.dFloat, threadIndex C.int) {
-	b := &Body{body}
+	b := newBody(body)
+	m := go16Floats(matrix)
 
-	transformCallbackOwners[owner(body)](b, go16Floats(matrix), int(threadIndex))
+	if rec, ok := interpolated.get(owner(body)); ok {
+		rec.record(m)
+	}
+	if callback, ok := transformCallbackOwners.get(owner(body)); ok {
+		callback(b, m, int(threadIndex))
+	}
 }
 
 func (b *Body) SetTransformCallback(callback TransformCallback) {
-	transformCallbackOwners[owner(b.handle)] = callback
-	C.SetTransformCallback(b.handle)
+	transformCallbackOwners.set(owner(b.handle), callback)
+	transformNames.remove(owner(b.handle))
+	C.SetTransformCallback(b.ptr())
 }
 
 func (b *Body) TransformCallback() TransformCallback {
-	return transformCallbackOwners[owner(b.handle)]
+	callback, _ := transformCallbackOwners.get(owner(b.handle))
+	return callback
 }
 
 type ApplyForceAndTorque func(body *Body, timestep float32, threadIndex int)
 
-var applyForceAndTorqueOwners = make(map[owner]ApplyForceAndTorque)
+var applyForceAndTorqueOwners = newHandlers[ApplyForceAndTorque]()
 
 //export goApplyForceAndTorque
 func goApplyForceAndTorque(body *C.NewtonBody, timestep C.dFloat, threadIndex C.int) {
-	b := &Body{body}
+	b := newBody(body)
 
-	applyForceAndTorqueOwners[owner(body)](b, float32(timestep), int(threadIndex))
+	if callback, ok := applyForceAndTorqueOwners.get(owner(body)); ok {
+		callback(b, float32(timestep), int(threadIndex))
+	}
 }
 
 func (b *Body) SetForceAndTorqueCallback(callback ApplyForceAndTorque) {
-	applyForceAndTorqueOwners[owner(b.handle)] = callback
+	applyForceAndTorqueOwners.set(owner(b.handle), callback)
+	forceAndTorqueNames.remove(owner(b.handle))
 
-	C.SetForceAndTorqueCallback(b.handle)
+	C.SetForceAndTorqueCallback(b.ptr())
 }
 
 func (b *Body) ForceAndTorqueCallback() ApplyForceAndTorque {
-	return applyForceAndTorqueOwners[owner(b.handle)]
+	callback, _ := applyForceAndTorqueOwners.get(owner(b.handle))
+	return callback
 }
 
 type BuoyancyPlaneHandler func(collisionID int, context interface{}, globalSpaceMatrix *[16]float32, globalSpacePlane *[4]float32) int
 
-var getBuoyancyPlane BuoyancyPlaneHandler
-
 //export goBuoyancyPlaneCallback
 func goBuoyancyPlaneCallback(collisionID C.int, context unsafe.Pointer, globalSpaceMatrix,
 	globalSpacePlane *C.dFloat) C.int {
+	c := callFromUserData(context)
 
-	return C.int(getBuoyancyPlane(int(collisionID), ownerData[owner(context)], go16Floats(globalSpaceMatrix),
-		go4Floats(globalSpacePlane)))
+	//the plane is set by the handler, so it's copied back for Newton
+	plane := go4Floats(globalSpacePlane)
+	result := c.buoyancyPlane(int(collisionID), c.userData, go16Floats(globalSpaceMatrix), plane)
+	copy(goFloats(globalSpacePlane, 4), plane[:])
+	return C.int(result)
 }
 
 func (b *Body) AddBuoyancyForce(fluidDensity, fluidLinearViscosity, fluidAngularViscosity float32,
 	gravityVector *[3]float32, buoyancyPlane BuoyancyPlaneHandler, context interface{}) {
-	getBuoyancyPlane = buoyancyPlane
+	c := &call{buoyancyPlane: buoyancyPlane, userData: context}
+	defer c.end()
 
-	ownerData[owner(&context)] = context
-
-	C.AddBuoyancyForce(b.handle, C.dFloat(fluidDensity), C.dFloat(fluidLinearViscosity),
-		C.dFloat(fluidAngularViscosity), (*C.dFloat)(&gravityVector[0]), unsafe.Pointer(&context))
+	C.AddBuoyancyForce(b.ptr(), C.dFloat(fluidDensity), C.dFloat(fluidLinearViscosity),
+		C.dFloat(fluidAngularViscosity), (*C.dFloat)(&gravityVector[0]), c.begin())
 }
 
 //Joint callbacks
 
 type ConstraintDestructor func(me *Joint)
 
-var constraintDestructorOwners = make(map[owner]ConstraintDestructor)
+var constraintDestructorOwners = newHandlers[ConstraintDestructor]()
 
 //export goConstraintDestructor
 func goConstraintDestructor(me *C.NewtonJoint) {
-	joint := &Joint{me}
-	constraintDestructorOwners[owner(me)](joint)
+	joint := destroyedJoint(me)
+
+	if destructor, ok := constraintDestructorOwners.get(owner(me)); ok {
+		destructor(joint)
+	}
+
+	constraintDestructorOwners.remove(owner(me))
+	removeJointCallbacks(owner(me))
+	jointSpecs.remove(owner(me))
+	ownerData.remove(owner(me))
+	joint.handle = nil
 }
 
 func (j *Joint) SetDestructor(destructor ConstraintDestructor) {
-	constraintDestructorOwners[owner(j.handle)] = destructor
-	C.SetConstraintDestructor(j.handle)
+	constraintDestructorOwners.set(owner(j.ptr()), destructor)
+}
+
+//removeJointCallbacks removes any joint type specific callback set on the joint
+func removeJointCallbacks(o owner) {
+	ballCallbackOwners.remove(o)
+	hingeCallbackOwners.remove(o)
+	sliderCallbackOwners.remove(o)
+	corkscrewCallbackOwners.remove(o)
+	universalCallbackOwners.remove(o)
 }
 
 type BallCallback func(joint *Joint, timestep float32)
 
-var ballCallbackOwners = make(map[owner]BallCallback)
+var ballCallbackOwners = newHandlers[BallCallback]()
 
 //export goBallCallback
 func goBallCallback(joint *C.NewtonJoint, timestep C.dFloat) {
-	j := &Joint{joint}
-	ballCallbackOwners[owner(joint)](j, float32(timestep))
+	j := newJoint(joint)
+	if callback, ok := ballCallbackOwners.get(owner(joint)); ok {
+		callback(j, float32(timestep))
+	}
 }
 
 func SetBallCallback(joint *Joint, callback BallCallback) {
-	ballCallbackOwners[owner(joint.handle)] = callback
-	C.BallSetUserCallback(joint.handle)
+	ballCallbackOwners.set(owner(joint.handle), callback)
+	C.BallSetUserCallback(joint.ptr())
 }
 
 type HingeCallback func(joint *Joint, desc *HingeSliderUpdateDesc) uint
 
-var HingeCallbackOwners = make(map[owner]HingeCallback)
+var hingeCallbackOwners = newHandlers[HingeCallback]()
 
 //export goHingeCallback
 func goHingeCallback(joint *C.NewtonJoint, desc *C.NewtonHingeSliderUpdateDesc) C.unsigned {
-	j := &Joint{joint}
+	j := newJoint(joint)
 	gDesc := &HingeSliderUpdateDesc{desc}
-	return C.unsigned(HingeCallbackOwners[owner(joint)](j, gDesc))
+
+	callback, ok := hingeCallbackOwners.get(owner(joint))
+	if !ok {
+		return 0
+	}
+	return C.unsigned(callback(j, gDesc))
 }
 
 func SetHingeCallback(joint *Joint, callback HingeCallback) {
-	HingeCallbackOwners[owner(joint.handle)] = callback
-	C.HingeSetUserCallback(joint.handle)
+	hingeCallbackOwners.set(owner(joint.handle), callback)
+	C.HingeSetUserCallback(joint.ptr())
 }
 
 type SliderCallback func(joint *Joint, desc *HingeSliderUpdateDesc) uint
 
-var SliderCallbackOwners = make(map[owner]SliderCallback)
+var sliderCallbackOwners = newHandlers[SliderCallback]()
 
 //export goSliderCallback
 func goSliderCallback(joint *C.NewtonJoint, desc *C.NewtonHingeSliderUpdateDesc) C.unsigned {
-	j := &Joint{joint}
+	j := newJoint(joint)
 	gDesc := &HingeSliderUpdateDesc{desc}
-	return C.unsigned(SliderCallbackOwners[owner(joint)](j, gDesc))
+
+	callback, ok := sliderCallbackOwners.get(owner(joint))
+	if !ok {
+		return 0
+	}
+	return C.unsigned(callback(j, gDesc))
 }
 
 func SetSliderCallback(joint *Joint, callback SliderCallback) {
-	SliderCallbackOwners[owner(joint.handle)] = callback
-	C.SliderSetUserCallback(joint.handle)
+	sliderCallbackOwners.set(owner(joint.handle), callback)
+	C.SliderSetUserCallback(joint.ptr())
 }
 
 type CorkscrewCallback func(joint *Joint, desc *HingeSliderUpdateDesc) uint
 
-var CorkscrewCallbackOwners = make(map[owner]CorkscrewCallback)
+var corkscrewCallbackOwners = newHandlers[CorkscrewCallback]()
 
 //export goCorkscrewCallback
 func goCorkscrewCallback(joint *C.NewtonJoint, desc *C.NewtonHingeSliderUpdateDesc) C.unsigned {
-	j := &Joint{joint}
+	j := newJoint(joint)
 	gDesc := &HingeSliderUpdateDesc{desc}
-	return C.unsigned(CorkscrewCallbackOwners[owner(joint)](j, gDesc))
+
+	callback, ok := corkscrewCallbackOwners.get(owner(joint))
+	if !ok {
+		return 0
+	}
+	return C.unsigned(callback(j, gDesc))
 }
 
 func SetCorkscrewCallback(joint *Joint, callback CorkscrewCallback) {
-	CorkscrewCallbackOwners[owner(joint.handle)] = callback
-	C.CorkscrewSetUserCallback(joint.handle)
+	corkscrewCallbackOwners.set(owner(joint.handle), callback)
+	C.CorkscrewSetUserCallback(joint.ptr())
 }
 
 type UniversalCallback func(joint *Joint, desc *HingeSliderUpdateDesc) uint
 
-var UniversalCallbackOwners = make(map[owner]UniversalCallback)
+var universalCallbackOwners = newHandlers[UniversalCallback]()
 
 //export goUniversalCallback
 func goUniversalCallback(joint *C.NewtonJoint, desc *C.NewtonHingeSliderUpdateDesc) C.unsigned {
-	j := &Joint{joint}
+	j := newJoint(joint)
 	gDesc := &HingeSliderUpdateDesc{desc}
-	return C.unsigned(UniversalCallbackOwners[owner(joint)](j, gDesc))
+
+	callback, ok := universalCallbackOwners.get(owner(joint))
+	if !ok {
+		return 0
+	}
+	return C.unsigned(callback(j, gDesc))
 }
 
 func SetUniversalCallback(joint *Joint, callback UniversalCallback) {
-	UniversalCallbackOwners[owner(joint.handle)] = callback
-	C.UniversalSetUserCallback(joint.handle)
+	universalCallbackOwners.set(owner(joint.handle), callback)
+	C.UniversalSetUserCallback(joint.ptr())
 }
 
 type ReportProgress func(progressPercent float32)
 
-var reportProgress ReportProgress
+//Newton's progress report has no userData, so mesh operations that report
+// progress are run one at a time while they hold the lock
+var reportProgress struct {
+	sync.Mutex
+	f ReportProgress
+}
 
 //export goReportProgress
 func goReportProgress(progressPercent C.dFloat) {
-	reportProgress(float32(progressPercent))
+	if reportProgress.f != nil {
+		reportProgress.f(float32(progressPercent))
+	}
 }
 
-func (m *Mesh) Simplify(maxVertexCount int, reportProgressCallback ReportProgress) *Mesh {
-	reportProgress = reportProgressCallback
+func (m *Mesh) Simplify(maxVertexCount int, reportProgressCallback ReportProgress) (*Mesh, error) {
+	reportProgress.Lock()
+	defer reportProgress.Unlock()
+	reportProgress.f = reportProgressCallback
 
-	return &Mesh{C.MeshSimplify(m.handle, C.int(maxVertexCount))}
+	return createdMesh(m.res.trackedWorld(), C.MeshSimplify(m.ptr(), C.int(maxVertexCount)),
+		"simplified mesh")
 }
 
 func (m *Mesh) ApproximateConvexDecomposition(maxConcavity, backFaceDistanceFactor float32,
-	maxCount, maxVertexPerHull int, reportProgressCallback ReportProgress) *Mesh {
-
-	reportProgress = reportProgressCallback
-	return &Mesh{C.MeshApproximateConvexDecomposition(m.handle, C.dFloat(maxConcavity),
-		C.dFloat(backFaceDistanceFactor), C.int(maxCount), C.int(maxVertexPerHull))}
+	maxCount, maxVertexPerHull int, reportProgressCallback ReportProgress) (*Mesh, error) {
+	reportProgress.Lock()
+	defer reportProgress.Unlock()
+	reportProgress.f = reportProgressCallback
 
+	return createdMesh(m.res.trackedWorld(), C.MeshApproximateConvexDecomposition(m.ptr(), C.dFloat(maxConcavity),
+		C.dFloat(backFaceDistanceFactor), C.int(maxCount), C.int(maxVertexPerHull)),
+		"convex decomposition")
 }
 
 type CollisionIterator func(userData interface{}, vertexCount int, faceArray []float32, faceID int)
 
-var newtonCollisionIterator CollisionIterator
-
 //export goNewtonCollisionIterator
 func goNewtonCollisionIterator(userData unsafe.Pointer, vertexCount C.int, faceArray *C.dFloat, faceID C.int) {
-	newtonCollisionIterator(ownerData[owner(userData)], int(vertexCount), goFloats(faceArray, int(vertexCount*3)),
+	c := callFromUserData(userData)
+
+	c.collisionIterator(c.userData, int(vertexCount), goFloats(faceArray, int(vertexCount*3)),
 		int(faceID))
 }
 
 func (c *Collision) ForEachPolygonDo(matrix *[16]float32, callback CollisionIterator, userData interface{}) {
-	newtonCollisionIterator = callback
-	ownerData[owner(&userData)] = userData
-	C.setForEachPolygonDo(c.handle, (*C.dFloat)(&matrix[0]), unsafe.Pointer(&userData))
+	cl := &call{collisionIterator: callback, userData: userData}
+	defer cl.end()
+	C.setForEachPolygonDo(c.ptr(), (*C.dFloat)(&matrix[0]), cl.begin())
 }
 
 type DeserializeCallback func(serializeHandle interface{}, buffer []byte)
 
-var newtonDeserializeCallback DeserializeCallback
-
 //export goNewtonDeserializeCallback
 func goNewtonDeserializeCallback(serializeHandle unsafe.Pointer, buffer unsafe.Pointer, size C.int) {
-	newtonDeserializeCallback(ownerData[owner(&serializeHandle)], goBytes(buffer, int(size)))
+	c := callFromUserData(serializeHandle)
+
+	c.deserialize(c.userData, goBytes(buffer, int(size)))
 }
 
-func (w *World) CreateCollisionFromSerialization(deserializeFunc DeserializeCallback, serializeHandle interface{}) *Collision {
-	ownerData[owner(&serializeHandle)] = serializeHandle
-	return &Collision{C.createCollisionFromSerialization(w.handle, unsafe.Pointer(&serializeHandle))}
+func (w *World) CreateCollisionFromSerialization(deserializeFunc DeserializeCallback,
+	serializeHandle interface{}) (*Collision, error) {
+	c := &call{deserialize: deserializeFunc, userData: serializeHandle}
+	defer c.end()
+	return createdCollision(owner(w.handle), C.createCollisionFromSerialization(w.ptr(), c.begin()), "serialized collision")
 }
 
 type SerializeCallback func(serializeHandle interface{}, buffer []byte)
 
-var newtonSerializeCallback SerializeCallback
-
 //export goNewtonSerializeCallback
 func goNewtonSerializeCallback(serializeHandle unsafe.Pointer, buffer unsafe.Pointer, size C.int) {
-	newtonSerializeCallback(ownerData[owner(&serializeHandle)], goBytes(buffer, int(size)))
+	c := callFromUserData(serializeHandle)
+
+	c.serialize(c.userData, goBytes(buffer, int(size)))
 }
 
 func (w *World) SerializeCollision(collision *Collision, serializeFunc SerializeCallback, serializeHandle interface{}) {
-	ownerData[owner(&serializeHandle)] = serializeHandle
-	newtonSerializeCallback = serializeFunc
-	C.serializeCollision(w.handle, collision.handle, unsafe.Pointer(&serializeHandle))
+	c := &call{serialize: serializeFunc, userData: serializeHandle}
+	defer c.end()
+	C.serializeCollision(w.ptr(), collision.ptr(), c.begin())
 
 }
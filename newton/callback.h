@@ -6,6 +6,7 @@
 #define _CALLBACK_H_
 
 #include <stdlib.h>
+#include <stdint.h>
 //dll for win32?
 #include "Newton.h"
 
@@ -35,21 +36,26 @@ extern void goReportProgress(dFloat);
 extern void goNewtonCollisionIterator(void*, int, dFloat*, int); 
 extern void goNewtonDeserializeCallback(void*, void*, int); 
 extern void goNewtonSerializeCallback(void*, void*, int); 
+extern void goCollisionDestructor(NewtonWorld*, NewtonCollision*);
+extern void goUserMeshCollide(NewtonUserMeshCollisionCollideDesc*);
+extern dFloat goUserMeshRayHit(NewtonUserMeshCollisionRayHitDesc*);
+extern void goUserMeshDestroy(void*);
+extern int goUserMeshAABBTest(void*, dFloat*, dFloat*);
 
 void setGetTicksCountCB(NewtonWorld*);
 void setBodyLeaveWorldCB(NewtonWorld*);
-void setJointIteratorCB(NewtonWorld*, void*);
-void setBodyIteratorCB(NewtonWorld*,dFloat*, dFloat*, void*);
-void RayCast(NewtonWorld*, dFloat*, dFloat*, void*);
-int ConvexCast(NewtonWorld*, dFloat*, dFloat*, NewtonCollision*, dFloat*, void*, 
+void setJointIteratorCB(NewtonWorld*, uintptr_t);
+void setBodyIteratorCB(NewtonWorld*,dFloat*, dFloat*, uintptr_t);
+void RayCast(NewtonWorld*, dFloat*, dFloat*, uintptr_t);
+int ConvexCast(NewtonWorld*, dFloat*, dFloat*, NewtonCollision*, dFloat*, uintptr_t, 
 		NewtonWorldConvexCastReturnInfo*, int, int);
-void SetCollisionCB(NewtonWorld*, int, int, void*);
+void SetCollisionCB(NewtonWorld*, int, int, uintptr_t);
 void SetUserRayCastCallback(NewtonCollision*);
 void SetStaticCollisionDebugCallback(NewtonCollision*);
 void SetBodyDestructor(NewtonBody*); 
 void SetTransformCallback(NewtonBody*);
 void SetForceAndTorqueCallback(NewtonBody*);
-void AddBuoyancyForce(NewtonBody*, dFloat, dFloat, dFloat, dFloat*, void*);
+void AddBuoyancyForce(NewtonBody*, dFloat, dFloat, dFloat, dFloat*, uintptr_t);
 void SetConstraintDestructor(NewtonJoint*);
 void BallSetUserCallback(NewtonJoint*);
 void HingeSetUserCallback(NewtonJoint*);
@@ -58,8 +64,10 @@ void CorkscrewSetUserCallback(NewtonJoint*);
 void UniversalSetUserCallback(NewtonJoint*);
 NewtonMesh* MeshSimplify(NewtonMesh*, int);
 NewtonMesh* MeshApproximateConvexDecomposition(NewtonMesh*, dFloat, dFloat, int, int);
-void setForEachPolygonDo(NewtonCollision*, dFloat*, void*);
+void setForEachPolygonDo(NewtonCollision*, dFloat*, uintptr_t);
 void setNewtonDeserializeCallback(NewtonWorld*, NewtonDeserializeCallback, void*);
-NewtonCollision* createCollisionFromSerialization(NewtonWorld*, void*);
-void serializeCollision(NewtonWorld*, NewtonCollision*, void*);
+NewtonCollision* createCollisionFromSerialization(NewtonWorld*, uintptr_t);
+void serializeCollision(NewtonWorld*, NewtonCollision*, uintptr_t);
+void setCollisionDestructorCB(NewtonWorld*);
+NewtonCollision* CreateUserMeshCollision(NewtonWorld*, dFloat*, dFloat*, uintptr_t, int);
 #endif //_CALLBACK_H_
@@ -0,0 +1,188 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import "unsafe"
+
+//Contact events
+// Contact joints are only valid until the next update, and reading them from a
+// ContactsProcessHandler means doing it from inside Newton's update, possibly on
+// another thread.  Once contact events are turned on, every contact joint is copied
+// into plain Go values after each update, and compared with the last update to tell
+// which pairs of bodies began touching, are still touching or stopped touching.
+
+type ContactState int
+
+const (
+	ContactBegan ContactState = iota
+	ContactPersisted
+	ContactEnded
+)
+
+//ContactPoint is a single point of contact, with its normal as seen from BodyA.
+// Impulse is the size of the impulse along the normal that pushed the bodies apart
+// during the update
+type ContactPoint struct {
+	Position    Vec3
+	Normal      Vec3
+	Impulse     float32
+	NormalSpeed float32
+}
+
+//ContactEvent is a pair of bodies that are touching, or that stopped touching during
+// the update, in which case Points is empty
+type ContactEvent struct {
+	BodyA, BodyB *Body
+	State        ContactState
+	Points       []ContactPoint
+}
+
+type ContactHandler func(event ContactEvent)
+
+//contactReport holds the events from the last update
+type contactReport struct {
+	enabled bool
+	handler ContactHandler
+	events  []ContactEvent
+}
+
+//report returns the world's contact report, creating it if it doesn't exist
+func (cb *worldCallbacks) report() *contactReport {
+	if cb.contacts == nil {
+		cb.contacts = &contactReport{}
+	}
+	return cb.contacts
+}
+
+//SetContactEvents turns collecting contact events after each update on or off.
+// Collecting them walks every contact joint in the world, so it's off by default
+func (w *World) SetContactEvents(enabled bool) {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	r := cb.report()
+	r.enabled = enabled
+	if !enabled {
+		r.events = nil
+	}
+}
+
+//OnContact turns on contact events, and calls handler with each of them after every
+// update, from the goroutine that called Update or WaitForUpdateToFinish
+func (w *World) OnContact(handler ContactHandler) {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	r := cb.report()
+	r.enabled = true
+	r.handler = handler
+}
+
+//Contacts returns the contact events from the last update.  The events are copies,
+// so they stay valid after later updates
+func (w *World) Contacts() []ContactEvent {
+	cb := w.callbacks()
+	cb.RLock()
+	defer cb.RUnlock()
+	if cb.contacts == nil {
+		return nil
+	}
+	return cb.contacts.events
+}
+
+//bodyPair is a pair of bodies in a consistent order
+type bodyPair struct {
+	a, b *Body
+}
+
+func newBodyPair(a, b *Body) bodyPair {
+	if uintptr(unsafe.Pointer(b.handle)) < uintptr(unsafe.Pointer(a.handle)) {
+		return bodyPair{b, a}
+	}
+	return bodyPair{a, b}
+}
+
+//contactPoints copies the contacts of the joint, relative to body
+func contactPoints(joint *Joint, body *Body, timestep float32) []ContactPoint {
+	points := make([]ContactPoint, 0, joint.ContactCount())
+	for contact := range joint.Contacts() {
+		material := contact.Material()
+
+		var position, normal, force [3]float32
+		material.ContactPositionAndNormal(body, &position, &normal)
+		material.ContactForce(body, &force)
+		impulse := Vec3(force).Dot(normal) * timestep
+		if impulse < 0 {
+			impulse = -impulse
+		}
+		points = append(points, ContactPoint{
+			Position:    position,
+			Normal:      normal,
+			Impulse:     impulse,
+			NormalSpeed: material.ContactNormalSpeed(),
+		})
+	}
+	return points
+}
+
+//updateContacts collects the contact events for the update that just finished
+func (w *World) updateContacts(timestep float32) {
+	cb := w.callbacks()
+
+	cb.Lock()
+	r := cb.contacts
+	if r == nil || !r.enabled {
+		cb.Unlock()
+		return
+	}
+
+	previous := make(map[bodyPair]bool, len(r.events))
+	for _, e := range r.events {
+		if e.State != ContactEnded {
+			previous[bodyPair{e.BodyA, e.BodyB}] = true
+		}
+	}
+
+	var events []ContactEvent
+	touching := make(map[bodyPair]bool)
+	seen := make(map[owner]bool)
+	for body := range w.Bodies() {
+		for joint := range body.ContactJoints() {
+			if seen[owner(joint.handle)] || joint.ContactCount() == 0 {
+				continue
+			}
+			seen[owner(joint.handle)] = true
+
+			pair := newBodyPair(joint.Body0(), joint.Body1())
+			state := ContactBegan
+			if previous[pair] {
+				state = ContactPersisted
+			}
+			touching[pair] = true
+			events = append(events, ContactEvent{
+				BodyA:  pair.a,
+				BodyB:  pair.b,
+				State:  state,
+				Points: contactPoints(joint, pair.a, timestep),
+			})
+		}
+	}
+
+	for _, e := range r.events {
+		pair := bodyPair{e.BodyA, e.BodyB}
+		if !previous[pair] || touching[pair] || e.BodyA.handle == nil || e.BodyB.handle == nil {
+			continue
+		}
+		events = append(events, ContactEvent{BodyA: e.BodyA, BodyB: e.BodyB, State: ContactEnded})
+	}
+	r.events = events
+	handler := r.handler
+	cb.Unlock()
+
+	if handler != nil {
+		for _, e := range events {
+			handler(e)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+//exportFace is a single polygon of the geometry being written
+type exportFace struct {
+	indices  []int
+	material int
+}
+
+//geometry returns the vertices and closed faces of the mesh
+func (m *Mesh) geometry() ([]Vec3, []exportFace) {
+	count := m.VertexCount()
+	stride := m.VertexStrideInByte() / 8
+	array := m.VertexArray(count * stride)
+
+	vertices := make([]Vec3, count)
+	for i := range vertices {
+		v := array[i*stride:]
+		vertices[i] = Vec3{float32(v[0]), float32(v[1]), float32(v[2])}
+	}
+
+	var faces []exportFace
+	for face := m.FirstFace(); face != nil; face = m.NextFace(face) {
+		if m.IsFaceOpen(face) {
+			continue
+		}
+		indices := make([]int, m.FaceIndexCount(face))
+		m.FaceIndices(face, indices)
+		faces = append(faces, exportFace{indices: indices, material: m.FaceMaterial(face)})
+	}
+	return vertices, faces
+}
+
+//writeOBJ writes the vertices and faces in Wavefront OBJ format, faces are given
+// a usemtl of material followed by their material index
+func writeOBJ(w io.Writer, vertices []Vec3, faces []exportFace) error {
+	out := bufio.NewWriter(w)
+	for _, v := range vertices {
+		fmt.Fprintf(out, "v %g %g %g\n", v[0], v[1], v[2])
+	}
+
+	material := math.MinInt
+	for _, face := range faces {
+		if face.material != material {
+			material = face.material
+			fmt.Fprintf(out, "usemtl material%d\n", material)
+		}
+		out.WriteString("f")
+		for _, i := range face.indices {
+			fmt.Fprintf(out, " %d", i+1)
+		}
+		out.WriteString("\n")
+	}
+	return out.Flush()
+}
+
+//WriteOBJ writes the mesh's faces in Wavefront OBJ format
+func (m *Mesh) WriteOBJ(w io.Writer) error {
+	vertices, faces := m.geometry()
+	return writeOBJ(w, vertices, faces)
+}
+
+//WriteSTL writes the mesh's faces as triangles in STL format, either binary or ASCII
+func (m *Mesh) WriteSTL(w io.Writer, binary bool) error {
+	vertices, faces := m.geometry()
+
+	//STL only holds triangles, so polygons are split into fans
+	var triangles [][3]Vec3
+	for _, face := range faces {
+		for i := 2; i < len(face.indices); i++ {
+			triangles = append(triangles, [3]Vec3{
+				vertices[face.indices[0]],
+				vertices[face.indices[i-1]],
+				vertices[face.indices[i]],
+			})
+		}
+	}
+
+	if binary {
+		return writeBinarySTL(w, triangles)
+	}
+	return writeASCIISTL(w, triangles)
+}
+
+func triangleNormal(t [3]Vec3) Vec3 {
+	return t[1].Sub(t[0]).Cross(t[2].Sub(t[0])).Normalize()
+}
+
+func writeASCIISTL(w io.Writer, triangles [][3]Vec3) error {
+	out := bufio.NewWriter(w)
+	out.WriteString("solid newton\n")
+	for _, t := range triangles {
+		n := triangleNormal(t)
+		fmt.Fprintf(out, "facet normal %g %g %g\n outer loop\n", n[0], n[1], n[2])
+		for _, v := range t {
+			fmt.Fprintf(out, "  vertex %g %g %g\n", v[0], v[1], v[2])
+		}
+		out.WriteString(" endloop\nendfacet\n")
+	}
+	out.WriteString("endsolid newton\n")
+	return out.Flush()
+}
+
+//stlTriangle is a triangle record of a binary STL file
+type stlTriangle struct {
+	Normal    [3]float32
+	Vertices  [3][3]float32
+	Attribute uint16
+}
+
+func writeBinarySTL(w io.Writer, triangles [][3]Vec3) error {
+	out := bufio.NewWriter(w)
+
+	header := [80]byte{}
+	copy(header[:], "newton")
+	out.Write(header[:])
+	if err := binary.Write(out, binary.LittleEndian, uint32(len(triangles))); err != nil {
+		return err
+	}
+
+	for _, t := range triangles {
+		record := stlTriangle{
+			Normal:   triangleNormal(t),
+			Vertices: [3][3]float32{t[0], t[1], t[2]},
+		}
+		if err := binary.Write(out, binary.LittleEndian, &record); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+//WriteOBJ writes the collision's polygons, placed at matrix, in Wavefront OBJ format.
+// A nil matrix is the identity.  The face ID of each polygon is written as its
+// material
+func (c *Collision) WriteOBJ(w io.Writer, matrix *[16]float32) error {
+	if matrix == nil {
+		identity := [16]float32(Identity())
+		matrix = &identity
+	}
+
+	var vertices []Vec3
+	var faces []exportFace
+	c.ForEachPolygonDo(matrix, func(userData interface{}, vertexCount int, faceArray []float32, faceID int) {
+		face := exportFace{material: faceID}
+		for i := 0; i < vertexCount; i++ {
+			face.indices = append(face.indices, len(vertices))
+			vertices = append(vertices, Vec3{faceArray[i*3], faceArray[i*3+1], faceArray[i*3+2]})
+		}
+		faces = append(faces, face)
+	}, nil)
+
+	return writeOBJ(w, vertices, faces)
+}
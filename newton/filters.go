@@ -0,0 +1,135 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+//Collision filtering
+// Material groups decide how surfaces behave when they touch, layers decide what
+// touches at all.  Every body is on one or more layers, and has a mask of the layers
+// it collides with.  A pair of bodies only collides when each is on a layer in the
+// other's mask, and neither ignores the other.  The check is made in the AABB overlap
+// callback, which is installed for every material pair once any body in the world
+// is filtered (see installPairCallbacks).
+
+//DefaultCollisionLayer and DefaultCollisionMask are used by bodies that haven't had
+// theirs set, so by default everything collides with everything
+const (
+	DefaultCollisionLayer uint32 = 1
+	DefaultCollisionMask  uint32 = 0xFFFFFFFF
+)
+
+//collisionFilter is the layers and ignore list of a single body
+type collisionFilter struct {
+	layer, mask uint32
+	ignore      map[*Body]bool
+}
+
+var collisionFilters = newHandlers[*collisionFilter]()
+
+//filter returns the body's filter, or the default one
+func (b *Body) filter() collisionFilter {
+	collisionFilters.RLock()
+	defer collisionFilters.RUnlock()
+	if f, ok := collisionFilters.m[owner(b.handle)]; ok {
+		return *f
+	}
+	return collisionFilter{layer: DefaultCollisionLayer, mask: DefaultCollisionMask}
+}
+
+//setFilter updates the body's filter, and turns on filtering for its world
+func (b *Body) setFilter(set func(f *collisionFilter)) {
+	b.World().installPairCallbacks()
+
+	collisionFilters.Lock()
+	defer collisionFilters.Unlock()
+	f, ok := collisionFilters.m[owner(b.ptr())]
+	if !ok {
+		f = &collisionFilter{layer: DefaultCollisionLayer, mask: DefaultCollisionMask}
+		collisionFilters.m[owner(b.handle)] = f
+	}
+	set(f)
+}
+
+func (b *Body) CollisionLayer() uint32 { return b.filter().layer }
+func (b *Body) CollisionMask() uint32  { return b.filter().mask }
+
+//SetCollisionLayer sets the layers the body is on
+func (b *Body) SetCollisionLayer(layer uint32) {
+	b.setFilter(func(f *collisionFilter) { f.layer = layer })
+}
+
+//SetCollisionMask sets the layers the body collides with
+func (b *Body) SetCollisionMask(mask uint32) {
+	b.setFilter(func(f *collisionFilter) { f.mask = mask })
+}
+
+//IgnoreCollisionWith stops the two bodies colliding, whatever their layers
+func (b *Body) IgnoreCollisionWith(other *Body) {
+	b.setIgnore(other, true)
+	other.setIgnore(b, true)
+}
+
+//RestoreCollisionWith undoes IgnoreCollisionWith
+func (b *Body) RestoreCollisionWith(other *Body) {
+	b.setIgnore(other, false)
+	other.setIgnore(b, false)
+}
+
+func (b *Body) setIgnore(other *Body, ignore bool) {
+	b.setFilter(func(f *collisionFilter) {
+		if !ignore {
+			delete(f.ignore, other)
+			return
+		}
+		if f.ignore == nil {
+			f.ignore = make(map[*Body]bool)
+		}
+		f.ignore[other] = true
+	})
+}
+
+//IgnoresCollisionWith is whether IgnoreCollisionWith was called for the two bodies
+func (b *Body) IgnoresCollisionWith(other *Body) bool {
+	return b.filter().ignore[other]
+}
+
+//CanCollide is whether the layers and ignore lists of the two bodies let them collide
+func (b *Body) CanCollide(other *Body) bool {
+	collisionFilters.RLock()
+	defer collisionFilters.RUnlock()
+	f0, ok0 := collisionFilters.m[owner(b.handle)]
+	f1, ok1 := collisionFilters.m[owner(other.handle)]
+	if !ok0 && !ok1 {
+		return true
+	}
+
+	layer0, mask0 := DefaultCollisionLayer, DefaultCollisionMask
+	if ok0 {
+		if f0.ignore[other] {
+			return false
+		}
+		layer0, mask0 = f0.layer, f0.mask
+	}
+	layer1, mask1 := DefaultCollisionLayer, DefaultCollisionMask
+	if ok1 {
+		layer1, mask1 = f1.layer, f1.mask
+	}
+	return layer0&mask1 != 0 && layer1&mask0 != 0
+}
+
+//removeCollisionFilter forgets the destroyed body, and removes it from the ignore
+// lists of the bodies it ignored
+func removeCollisionFilter(b *Body) {
+	collisionFilters.Lock()
+	defer collisionFilters.Unlock()
+	f, ok := collisionFilters.m[owner(b.handle)]
+	if !ok {
+		return
+	}
+	delete(collisionFilters.m, owner(b.handle))
+	for other := range f.ignore {
+		if o, ok := collisionFilters.m[owner(other.handle)]; ok {
+			delete(o.ignore, b)
+		}
+	}
+}
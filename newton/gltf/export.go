@@ -0,0 +1,301 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package gltf
+
+import (
+	"encoding/binary"
+	"math"
+
+	"bitbucket.org/tshannon/gonewton/newton"
+)
+
+//exporter is the state of a single Export
+type exporter struct {
+	d         *Document
+	w         *newton.World
+	bodies    map[*newton.Body]int //node of each body
+	materials map[int]int          //physics material of each material group
+	joints    map[string]int       //physics joint of each kind of joint
+}
+
+//Export writes the world's bodies and joints as a glTF document using
+// KHR_physics_rigid_bodies.
+//
+// Each body is a node with its motion, and a collider made from the polygons of its
+// collision, which is also the node's mesh.  Compound and scene collisions are written as a
+// child node for each of their parts.  Convex collisions are written as convex hulls,
+// so shapes such as boxes and spheres load back as hulls of the same size.
+//
+// A body's material group is written as a physics material, from the defaults set for
+// the group with itself.  Joints are written if they were created by one of the
+// World.Create joint functions
+func Export(w *newton.World) *Document {
+	e := &exporter{
+		d: &Document{
+			Asset:          Asset{Version: "2.0", Generator: "gonewton"},
+			ExtensionsUsed: []string{extensionPhysics},
+		},
+		w:         w,
+		bodies:    make(map[*newton.Body]int),
+		materials: make(map[int]int),
+		joints:    make(map[string]int),
+	}
+	physics := &Physics{}
+	e.d.Extensions = &Extensions{Physics: physics}
+
+	var roots []int
+	for body := range w.Bodies() {
+		roots = append(roots, e.body(body, physics))
+	}
+	w.ForEachJointDo(func(joint *newton.Joint, userData interface{}) {
+		if anchor, ok := e.joint(joint, physics); ok {
+			roots = append(roots, anchor)
+		}
+	}, nil)
+
+	scene := 0
+	e.d.Scene = &scene
+	e.d.Scenes = []Scene{{Nodes: roots}}
+	return e.d
+}
+
+//addNode appends a node, as a child of parent if it isn't -1
+func (e *exporter) addNode(node Node, parent int) int {
+	e.d.Nodes = append(e.d.Nodes, node)
+	n := len(e.d.Nodes) - 1
+	if parent >= 0 {
+		e.d.Nodes[parent].Children = append(e.d.Nodes[parent].Children, n)
+	}
+	return n
+}
+
+func nodePhysics(physics *NodePhysics) *NodeExtensions {
+	return &NodeExtensions{Physics: physics}
+}
+
+//body adds a node for the body and returns it
+func (e *exporter) body(body *newton.Body, physics *Physics) int {
+	matrix := [16]float32(body.Transform())
+	node := e.addNode(Node{Matrix: &matrix}, -1)
+	e.bodies[body] = node
+
+	np := &NodePhysics{}
+	var mass, ixx, iyy, izz float32
+	body.MassMatrix(&mass, &ixx, &iyy, &izz)
+	kinematic := body.Type() == newton.BodyKinematic
+	if mass != 0 || kinematic {
+		var com [3]float32
+		body.CentreOfMass(&com)
+		velocity := [3]float32(body.LinearVelocity())
+		omega := [3]float32(body.AngularVelocity())
+		np.Motion = &Motion{
+			IsKinematic:     kinematic,
+			Mass:            &mass,
+			CenterOfMass:    &com,
+			InertiaDiagonal: &[3]float32{ixx, iyy, izz},
+			LinearVelocity:  &velocity,
+			AngularVelocity: &omega,
+		}
+	}
+	e.d.Nodes[node].Extensions = nodePhysics(np)
+
+	material := e.material(body.MaterialGroupID(), physics)
+	collision := body.Collision()
+	switch collision.Type() {
+	case newton.CollisionCompound:
+		for sub := range collision.CompoundNodes() {
+			e.subCollider(collision.CompoundCollisionFromNode(sub), node, material)
+		}
+	case newton.CollisionScene:
+		for sub := range collision.SceneNodes() {
+			e.subCollider(collision.SceneCollisionFromNode(sub), node, material)
+		}
+	default:
+		np.Collider = e.collider(collision, node, material)
+	}
+	return node
+}
+
+//subCollider adds a child node of parent for part of a compound or scene collision
+func (e *exporter) subCollider(collision *newton.Collision, parent int, material *int) {
+	node := e.addNode(Node{}, parent)
+	if collider := e.collider(collision, node, material); collider != nil {
+		e.d.Nodes[node].Extensions = nodePhysics(&NodePhysics{Collider: collider})
+	}
+}
+
+//convex is whether a collision of the type is convex
+func convex(collisionType int) bool {
+	switch collisionType {
+	case newton.CollisionNull, newton.CollisionCompound, newton.CollisionTree, newton.CollisionHeightfield,
+		newton.CollisionDeformablemesh, newton.CollisionUsermesh, newton.CollisionScene,
+		newton.CollisionCompoundBreakable:
+		return false
+	}
+	return true
+}
+
+//collider sets the node's mesh to the polygons of the collision, placed by its offset
+// matrix, and returns a collider using it.  Returns nil if the collision has no polygons
+func (e *exporter) collider(collision *newton.Collision, node int, material *int) *Collider {
+	var matrix [16]float32
+	collision.Matrix(&matrix)
+
+	var positions []float32
+	var indices []uint32
+	collision.ForEachPolygonDo(&matrix, func(userData interface{}, vertexCount int, faceArray []float32, faceID int) {
+		base := uint32(len(positions) / 3)
+		positions = append(positions, faceArray[:vertexCount*3]...)
+		for i := uint32(2); i < uint32(vertexCount); i++ {
+			indices = append(indices, base, base+i-1, base+i)
+		}
+	}, nil)
+	if len(indices) == 0 {
+		return nil
+	}
+
+	mesh := e.mesh(positions, indices)
+	e.d.Nodes[node].Mesh = &mesh
+	return &Collider{
+		Geometry:        Geometry{Node: &node, ConvexHull: convex(collision.Type())},
+		PhysicsMaterial: material,
+	}
+}
+
+//mesh adds a mesh with a single triangle primitive
+func (e *exporter) mesh(positions []float32, indices []uint32) int {
+	lower := []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	upper := []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for i, v := range positions {
+		lower[i%3] = min(lower[i%3], v)
+		upper[i%3] = max(upper[i%3], v)
+	}
+
+	positionData, _ := binary.Append(nil, binary.LittleEndian, positions)
+	indexData, _ := binary.Append(nil, binary.LittleEndian, indices)
+	position := e.d.addAccessor(positionData, ComponentFloat, len(positions)/3, "VEC3", lower, upper)
+	index := e.d.addAccessor(indexData, ComponentUnsignedInt, len(indices), "SCALAR", nil, nil)
+
+	e.d.Meshes = append(e.d.Meshes, Mesh{Primitives: []Primitive{{
+		Attributes: map[string]int{"POSITION": position},
+		Indices:    &index,
+	}}})
+	return len(e.d.Meshes) - 1
+}
+
+//material returns the physics material for the material group, or nil for the default
+// group
+func (e *exporter) material(group int, physics *Physics) *int {
+	if group == e.w.DefaultMaterialGroupID() {
+		return nil
+	}
+	if i, ok := e.materials[group]; ok {
+		return &i
+	}
+
+	m := PhysicsMaterial{}
+	if static, kinetic, ok := e.w.MaterialDefaultFriction(group, group); ok {
+		m.StaticFriction, m.DynamicFriction = &static, &kinetic
+	}
+	if elasticity, ok := e.w.MaterialDefaultElasticity(group, group); ok {
+		m.Restitution = elasticity
+	}
+	physics.PhysicsMaterials = append(physics.PhysicsMaterials, m)
+	i := len(physics.PhysicsMaterials) - 1
+	e.materials[group] = i
+	return &i
+}
+
+//locked returns a limit that holds the axes still
+func locked(linear, angular []int) JointLimit {
+	var zero float32
+	return JointLimit{LinearAxes: linear, AngularAxes: angular, Min: &zero, Max: &zero}
+}
+
+//jointLimits are the locked axes of each kind of joint, with its first pin along X
+// and its second along Y
+var jointLimits = map[string][]JointLimit{
+	newton.JointBall:      {locked([]int{0, 1, 2}, nil)},
+	newton.JointHinge:     {locked([]int{0, 1, 2}, []int{1, 2})},
+	newton.JointUniversal: {locked([]int{0, 1, 2}, []int{2})},
+	newton.JointSlider:    {locked([]int{1, 2}, []int{0, 1, 2})},
+	newton.JointCorkscrew: {locked([]int{1, 2}, []int{1, 2})},
+	newton.JointUpVector:  {locked(nil, []int{1, 2})},
+}
+
+//jointFrame returns the frame of the joint in the child's space, with its first pin
+// along X and its second along Y
+func jointFrame(def *newton.JointDefinition) newton.Mat4 {
+	front := newton.Vec3{1, 0, 0}
+	if len(def.Pins) > 0 {
+		front = def.Pins[0].Normalize()
+	}
+
+	var up newton.Vec3
+	if len(def.Pins) > 1 {
+		up = def.Pins[1].Sub(front.Scale(def.Pins[1].Dot(front)))
+	}
+	if up.Len() < 1e-6 {
+		//any direction at right angles to the first pin
+		up = newton.Vec3{0, 1, 0}
+		if math.Abs(float64(front[1])) > 0.9 {
+			up = newton.Vec3{0, 0, 1}
+		}
+		up = up.Sub(front.Scale(up.Dot(front)))
+	}
+	up = up.Normalize()
+	right := front.Cross(up)
+
+	m := newton.Identity()
+	copy(m[0:3], front[:])
+	copy(m[4:7], up[:])
+	copy(m[8:11], right[:])
+	m.SetPosition(def.Pivot)
+	return m
+}
+
+//joint adds a node with the joint's frame to the child body, and the frame it connects
+// to on the parent body.  For joints attached to the world it returns a root node to
+// connect to
+func (e *exporter) joint(joint *newton.Joint, physics *Physics) (anchor int, isRoot bool) {
+	def, ok := joint.Definition()
+	if !ok {
+		return 0, false
+	}
+	child, ok := e.bodies[def.Child]
+	if !ok {
+		return 0, false
+	}
+
+	index, ok := e.joints[def.Kind]
+	if !ok {
+		physics.PhysicsJoints = append(physics.PhysicsJoints, PhysicsJoint{Limits: jointLimits[def.Kind]})
+		index = len(physics.PhysicsJoints) - 1
+		e.joints[def.Kind] = index
+	}
+
+	frame := jointFrame(&def)
+	world := frame.Mul(def.Child.Transform())
+
+	connected := -1
+	if parent, ok := e.bodies[def.Parent]; ok && def.Parent != nil {
+		local := [16]float32(world.Mul(def.Parent.Transform().Inverse()))
+		connected = e.addNode(Node{Matrix: &local}, parent)
+	} else {
+		anchor := [16]float32(world)
+		connected = e.addNode(Node{Matrix: &anchor}, -1)
+		isRoot = true
+	}
+
+	local := [16]float32(frame)
+	e.addNode(Node{
+		Matrix: &local,
+		Extensions: nodePhysics(&NodePhysics{Joint: &NodeJoint{
+			ConnectedNode:   connected,
+			Joint:           index,
+			EnableCollision: joint.CollisionState() != 0,
+		}}),
+	}, child)
+	return connected, isRoot
+}
@@ -0,0 +1,594 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//Package gltf reads and writes glTF 2.0 scenes with the KHR_physics_rigid_bodies and
+// KHR_implicit_shapes extensions, creating newton meshes, collisions, bodies and joints
+// from them, and writing a world back out in the same form
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var (
+	ErrFormat      = errors.New("gltf: invalid gltf")
+	ErrUnsupported = errors.New("gltf: unsupported")
+)
+
+const (
+	extensionPhysics = "KHR_physics_rigid_bodies"
+	extensionShapes  = "KHR_implicit_shapes"
+)
+
+//Document is a glTF 2.0 file, only the parts needed for geometry and physics
+// are kept
+type Document struct {
+	Asset              Asset        `json:"asset"`
+	ExtensionsUsed     []string     `json:"extensionsUsed,omitempty"`
+	ExtensionsRequired []string     `json:"extensionsRequired,omitempty"`
+	Scene              *int         `json:"scene,omitempty"`
+	Scenes             []Scene      `json:"scenes,omitempty"`
+	Nodes              []Node       `json:"nodes,omitempty"`
+	Meshes             []Mesh       `json:"meshes,omitempty"`
+	Accessors          []Accessor   `json:"accessors,omitempty"`
+	BufferViews        []BufferView `json:"bufferViews,omitempty"`
+	Buffers            []Buffer     `json:"buffers,omitempty"`
+	Extensions         *Extensions  `json:"extensions,omitempty"`
+
+	data [][]byte //contents of each buffer
+}
+
+type Asset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type Scene struct {
+	Name  string `json:"name,omitempty"`
+	Nodes []int  `json:"nodes,omitempty"`
+}
+
+//Node is placed by either Matrix or Translation, Rotation and Scale.  Rotation is
+// x, y, z, w
+type Node struct {
+	Name        string          `json:"name,omitempty"`
+	Children    []int           `json:"children,omitempty"`
+	Mesh        *int            `json:"mesh,omitempty"`
+	Matrix      *[16]float32    `json:"matrix,omitempty"`
+	Translation *[3]float32     `json:"translation,omitempty"`
+	Rotation    *[4]float32     `json:"rotation,omitempty"`
+	Scale       *[3]float32     `json:"scale,omitempty"`
+	Extensions  *NodeExtensions `json:"extensions,omitempty"`
+}
+
+type Mesh struct {
+	Name       string      `json:"name,omitempty"`
+	Primitives []Primitive `json:"primitives"`
+}
+
+type Primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Mode       *int           `json:"mode,omitempty"`
+	Material   *int           `json:"material,omitempty"`
+}
+
+//primitive modes
+const (
+	ModeTriangles     = 4
+	ModeTriangleStrip = 5
+	ModeTriangleFan   = 6
+)
+
+type Accessor struct {
+	BufferView    *int      `json:"bufferView,omitempty"`
+	ByteOffset    int       `json:"byteOffset,omitempty"`
+	ComponentType int       `json:"componentType"`
+	Normalized    bool      `json:"normalized,omitempty"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+	Sparse        any       `json:"sparse,omitempty"`
+}
+
+//accessor component types
+const (
+	ComponentByte          = 5120
+	ComponentUnsignedByte  = 5121
+	ComponentShort         = 5122
+	ComponentUnsignedShort = 5123
+	ComponentUnsignedInt   = 5125
+	ComponentFloat         = 5126
+)
+
+type BufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset,omitempty"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride,omitempty"`
+	Target     int `json:"target,omitempty"`
+}
+
+type Buffer struct {
+	URI        string `json:"uri,omitempty"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type Extensions struct {
+	Physics *Physics `json:"KHR_physics_rigid_bodies,omitempty"`
+	Shapes  *Shapes  `json:"KHR_implicit_shapes,omitempty"`
+}
+
+type Physics struct {
+	PhysicsMaterials []PhysicsMaterial `json:"physicsMaterials,omitempty"`
+	PhysicsJoints    []PhysicsJoint    `json:"physicsJoints,omitempty"`
+}
+
+//PhysicsMaterial frictions default to 0.6 when they aren't given
+type PhysicsMaterial struct {
+	StaticFriction     *float32 `json:"staticFriction,omitempty"`
+	DynamicFriction    *float32 `json:"dynamicFriction,omitempty"`
+	Restitution        float32  `json:"restitution,omitempty"`
+	FrictionCombine    string   `json:"frictionCombine,omitempty"`
+	RestitutionCombine string   `json:"restitutionCombine,omitempty"`
+}
+
+func (m *PhysicsMaterial) friction() (static, dynamic float32) {
+	static, dynamic = 0.6, 0.6
+	if m.StaticFriction != nil {
+		static = *m.StaticFriction
+	}
+	if m.DynamicFriction != nil {
+		dynamic = *m.DynamicFriction
+	}
+	return static, dynamic
+}
+
+//PhysicsJoint is a set of limits on the 6 degrees of freedom between two joint frames
+type PhysicsJoint struct {
+	Limits []JointLimit `json:"limits,omitempty"`
+}
+
+//JointLimit limits the listed axes to between Min and Max, a missing Min or Max is
+// unbounded
+type JointLimit struct {
+	LinearAxes  []int    `json:"linearAxes,omitempty"`
+	AngularAxes []int    `json:"angularAxes,omitempty"`
+	Min         *float32 `json:"min,omitempty"`
+	Max         *float32 `json:"max,omitempty"`
+	Stiffness   *float32 `json:"stiffness,omitempty"`
+	Damping     float32  `json:"damping,omitempty"`
+}
+
+//locked is whether the limit holds its axes still
+func (l *JointLimit) locked() bool {
+	return l.Min != nil && l.Max != nil && *l.Min == *l.Max && l.Stiffness == nil
+}
+
+type Shapes struct {
+	Shapes []Shape `json:"shapes"`
+}
+
+//Shape is an implicit shape, Type is sphere, box, capsule or cylinder and says
+// which of the other fields is set.  Capsules and cylinders run along Y, and a capsule's
+// height doesn't include its caps
+type Shape struct {
+	Type     string    `json:"type"`
+	Sphere   *Sphere   `json:"sphere,omitempty"`
+	Box      *Box      `json:"box,omitempty"`
+	Capsule  *Capsule  `json:"capsule,omitempty"`
+	Cylinder *Cylinder `json:"cylinder,omitempty"`
+}
+
+type Sphere struct {
+	Radius float32 `json:"radius"`
+}
+
+type Box struct {
+	Size [3]float32 `json:"size"`
+}
+
+type Capsule struct {
+	Height       float32 `json:"height"`
+	RadiusTop    float32 `json:"radiusTop"`
+	RadiusBottom float32 `json:"radiusBottom"`
+}
+
+type Cylinder struct {
+	Height       float32 `json:"height"`
+	RadiusTop    float32 `json:"radiusTop"`
+	RadiusBottom float32 `json:"radiusBottom"`
+}
+
+type NodeExtensions struct {
+	Physics *NodePhysics `json:"KHR_physics_rigid_bodies,omitempty"`
+}
+
+//NodePhysics makes a node a rigid body if it has Motion, gives it a collider, or makes
+// it the frame of a joint
+type NodePhysics struct {
+	Motion   *Motion    `json:"motion,omitempty"`
+	Collider *Collider  `json:"collider,omitempty"`
+	Joint    *NodeJoint `json:"joint,omitempty"`
+}
+
+//Motion is in the node's space, except for the velocities which are in world space.
+// A missing mass is 1
+type Motion struct {
+	IsKinematic     bool        `json:"isKinematic,omitempty"`
+	Mass            *float32    `json:"mass,omitempty"`
+	CenterOfMass    *[3]float32 `json:"centerOfMass,omitempty"`
+	InertiaDiagonal *[3]float32 `json:"inertiaDiagonal,omitempty"`
+	LinearVelocity  *[3]float32 `json:"linearVelocity,omitempty"`
+	AngularVelocity *[3]float32 `json:"angularVelocity,omitempty"`
+}
+
+type Collider struct {
+	Geometry        Geometry `json:"geometry"`
+	PhysicsMaterial *int     `json:"physicsMaterial,omitempty"`
+}
+
+//Geometry is either an implicit Shape or the mesh of a Node
+type Geometry struct {
+	Shape      *int `json:"shape,omitempty"`
+	Node       *int `json:"node,omitempty"`
+	ConvexHull bool `json:"convexHull,omitempty"`
+}
+
+//NodeJoint joins the body the node belongs to with the body ConnectedNode belongs to,
+// with the node's frame as the joint frame
+type NodeJoint struct {
+	ConnectedNode   int  `json:"connectedNode"`
+	Joint           int  `json:"joint"`
+	EnableCollision bool `json:"enableCollision,omitempty"`
+}
+
+//glb encoding
+// a header of magic, version and total length, followed by a JSON chunk and an
+// optional binary chunk holding the first buffer.  Chunks are padded to 4 bytes
+const (
+	glbMagic   = 0x46546C67 //glTF
+	glbVersion = 2
+	chunkJSON  = 0x4E4F534A
+	chunkBIN   = 0x004E4942
+)
+
+type glbHeader struct {
+	Magic, Version, Length uint32
+}
+
+type chunkHeader struct {
+	Length, Type uint32
+}
+
+//Decode reads a .gltf or .glb file.  Buffers must be in the .glb or embedded as data
+// URIs, use Open for files that refer to other files
+func Decode(r io.Reader) (*Document, error) {
+	return decode(r, nil, "")
+}
+
+//Open reads a .gltf or .glb file from fsys, along with any buffers it refers to
+func Open(fsys fs.FS, name string) (*Document, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decode(f, fsys, path.Dir(name))
+}
+
+func decode(r io.Reader, fsys fs.FS, dir string) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var bin []byte
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == glbMagic {
+		data, bin, err = readGLB(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d := &Document{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if !strings.HasPrefix(d.Asset.Version, "2.") {
+		return nil, fmt.Errorf("%w: version %q", ErrUnsupported, d.Asset.Version)
+	}
+	for _, ext := range d.ExtensionsRequired {
+		if ext != extensionPhysics && ext != extensionShapes {
+			return nil, fmt.Errorf("%w: required extension %s", ErrUnsupported, ext)
+		}
+	}
+
+	d.data = make([][]byte, len(d.Buffers))
+	for i, b := range d.Buffers {
+		switch {
+		case b.URI == "" && i == 0 && bin != nil:
+			d.data[i] = bin
+		case strings.HasPrefix(b.URI, "data:"):
+			comma := strings.IndexByte(b.URI, ',')
+			if comma < 0 || !strings.HasSuffix(b.URI[:comma], ";base64") {
+				return nil, fmt.Errorf("%w: buffer %d has an invalid data uri", ErrFormat, i)
+			}
+			if d.data[i], err = base64.StdEncoding.DecodeString(b.URI[comma+1:]); err != nil {
+				return nil, fmt.Errorf("%w: buffer %d: %v", ErrFormat, i, err)
+			}
+		case b.URI != "" && fsys != nil:
+			name, err := url.PathUnescape(b.URI)
+			if err != nil {
+				return nil, fmt.Errorf("%w: buffer %d: %v", ErrFormat, i, err)
+			}
+			if d.data[i], err = fs.ReadFile(fsys, path.Join(dir, name)); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: buffer %d can't be loaded", ErrFormat, i)
+		}
+		if len(d.data[i]) < b.ByteLength {
+			return nil, fmt.Errorf("%w: buffer %d is too short", ErrFormat, i)
+		}
+	}
+	return d, nil
+}
+
+//readGLB returns the JSON and binary chunks of a glb file
+func readGLB(data []byte) (jsonChunk, bin []byte, err error) {
+	var header glbHeader
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return nil, nil, ErrFormat
+	}
+	size := uint32(binary.Size(header))
+	if header.Version != glbVersion || header.Length < size || int64(header.Length) > int64(len(data)) {
+		return nil, nil, ErrFormat
+	}
+
+	//only the chunks within the header's length are read, anything after it isn't part
+	// of the file
+	r := bytes.NewReader(data[size:header.Length])
+	for r.Len() > 0 {
+		var chunk chunkHeader
+		if err := binary.Read(r, binary.LittleEndian, &chunk); err != nil {
+			return nil, nil, ErrFormat
+		}
+		if int(chunk.Length) > r.Len() {
+			return nil, nil, ErrFormat
+		}
+		content := make([]byte, chunk.Length)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return nil, nil, ErrFormat
+		}
+
+		switch {
+		case chunk.Type == chunkJSON && jsonChunk == nil:
+			jsonChunk = content
+		case chunk.Type == chunkBIN && bin == nil:
+			bin = content
+		}
+		//unknown chunks are skipped
+	}
+	if jsonChunk == nil {
+		return nil, nil, ErrFormat
+	}
+	return jsonChunk, bin, nil
+}
+
+//Encode writes the document as a .gltf file with its buffers embedded as data URIs
+func (d *Document) Encode(w io.Writer) error {
+	out := *d
+	out.Buffers = make([]Buffer, len(d.Buffers))
+	for i := range d.Buffers {
+		out.Buffers[i] = Buffer{
+			URI:        "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(d.data[i]),
+			ByteLength: len(d.data[i]),
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&out)
+}
+
+//EncodeGLB writes the document as a .glb file.  Only the first buffer is stored in the
+// binary chunk, any others are embedded as data URIs
+func (d *Document) EncodeGLB(w io.Writer) error {
+	out := *d
+	out.Buffers = make([]Buffer, len(d.Buffers))
+	for i := range d.Buffers {
+		out.Buffers[i].ByteLength = len(d.data[i])
+		if i > 0 {
+			out.Buffers[i].URI = "data:application/octet-stream;base64," +
+				base64.StdEncoding.EncodeToString(d.data[i])
+		}
+	}
+
+	jsonChunk, err := json.Marshal(&out)
+	if err != nil {
+		return err
+	}
+	jsonChunk = pad(jsonChunk, ' ')
+	var bin []byte
+	if len(d.data) > 0 {
+		bin = pad(append([]byte(nil), d.data[0]...), 0)
+	}
+
+	length := binary.Size(glbHeader{}) + binary.Size(chunkHeader{}) + len(jsonChunk)
+	if bin != nil {
+		length += binary.Size(chunkHeader{}) + len(bin)
+	}
+
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, &glbHeader{glbMagic, glbVersion, uint32(length)})
+	binary.Write(buf, binary.LittleEndian, &chunkHeader{uint32(len(jsonChunk)), chunkJSON})
+	buf.Write(jsonChunk)
+	if bin != nil {
+		binary.Write(buf, binary.LittleEndian, &chunkHeader{uint32(len(bin)), chunkBIN})
+		buf.Write(bin)
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func pad(b []byte, with byte) []byte {
+	for len(b)%4 != 0 {
+		b = append(b, with)
+	}
+	return b
+}
+
+var componentCounts = map[string]int{
+	"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16,
+}
+
+var componentSizes = map[int]int{
+	ComponentByte: 1, ComponentUnsignedByte: 1, ComponentShort: 2,
+	ComponentUnsignedShort: 2, ComponentUnsignedInt: 4, ComponentFloat: 4,
+}
+
+//read calls value with each component of every element of the accessor, as a float64.
+// Normalized integers are converted to -1 to 1 or 0 to 1
+func (d *Document) read(accessor int, components int, value func(v float64)) (count int, err error) {
+	if accessor < 0 || accessor >= len(d.Accessors) {
+		return 0, fmt.Errorf("%w: accessor %d doesn't exist", ErrFormat, accessor)
+	}
+	a := &d.Accessors[accessor]
+	if a.Sparse != nil {
+		return 0, fmt.Errorf("%w: sparse accessor %d", ErrUnsupported, accessor)
+	}
+	if componentCounts[a.Type] != components {
+		return 0, fmt.Errorf("%w: accessor %d is %s", ErrFormat, accessor, a.Type)
+	}
+	size, ok := componentSizes[a.ComponentType]
+	if !ok {
+		return 0, fmt.Errorf("%w: accessor %d has component type %d", ErrFormat, accessor, a.ComponentType)
+	}
+
+	if a.Count < 0 || a.ByteOffset < 0 {
+		return 0, fmt.Errorf("%w: accessor %d has a negative count or offset", ErrFormat, accessor)
+	}
+
+	if a.BufferView == nil {
+		//no buffer view is all zeros
+		for i := 0; i < a.Count*components; i++ {
+			value(0)
+		}
+		return a.Count, nil
+	}
+	if *a.BufferView < 0 || *a.BufferView >= len(d.BufferViews) {
+		return 0, fmt.Errorf("%w: buffer view %d doesn't exist", ErrFormat, *a.BufferView)
+	}
+	view := &d.BufferViews[*a.BufferView]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteStride < 0 {
+		return 0, fmt.Errorf("%w: buffer view %d has a negative offset, length or stride", ErrFormat, *a.BufferView)
+	}
+	if view.Buffer < 0 || view.Buffer >= len(d.data) ||
+		view.ByteOffset+view.ByteLength > len(d.data[view.Buffer]) {
+		return 0, fmt.Errorf("%w: buffer view %d is out of range", ErrFormat, *a.BufferView)
+	}
+	data := d.data[view.Buffer][view.ByteOffset : view.ByteOffset+view.ByteLength]
+
+	stride := view.ByteStride
+	if stride == 0 {
+		stride = size * components
+	}
+	if a.Count > 0 && a.ByteOffset+(a.Count-1)*stride+size*components > len(data) {
+		return 0, fmt.Errorf("%w: accessor %d is out of range", ErrFormat, accessor)
+	}
+
+	for i := 0; i < a.Count; i++ {
+		element := data[a.ByteOffset+i*stride:]
+		for c := 0; c < components; c++ {
+			b := element[c*size:]
+			var v float64
+			switch a.ComponentType {
+			case ComponentByte:
+				v = float64(int8(b[0]))
+				if a.Normalized {
+					v = math.Max(v/127, -1)
+				}
+			case ComponentUnsignedByte:
+				v = float64(b[0])
+				if a.Normalized {
+					v /= 255
+				}
+			case ComponentShort:
+				v = float64(int16(binary.LittleEndian.Uint16(b)))
+				if a.Normalized {
+					v = math.Max(v/32767, -1)
+				}
+			case ComponentUnsignedShort:
+				v = float64(binary.LittleEndian.Uint16(b))
+				if a.Normalized {
+					v /= 65535
+				}
+			case ComponentUnsignedInt:
+				v = float64(binary.LittleEndian.Uint32(b))
+			case ComponentFloat:
+				v = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+			}
+			value(v)
+		}
+	}
+	return a.Count, nil
+}
+
+//floats reads a float accessor with the given number of components per element
+func (d *Document) floats(accessor int, components int) ([]float32, error) {
+	var values []float32
+	_, err := d.read(accessor, components, func(v float64) { values = append(values, float32(v)) })
+	return values, err
+}
+
+//indices reads an index accessor, checking each index is below vertexCount
+func (d *Document) indices(accessor int, vertexCount int) ([]int, error) {
+	var values []int
+	_, err := d.read(accessor, 1, func(v float64) { values = append(values, int(v)) })
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range values {
+		if i >= vertexCount {
+			return nil, fmt.Errorf("%w: accessor %d has an index out of range", ErrFormat, accessor)
+		}
+	}
+	return values, nil
+}
+
+//addAccessor appends data to the document's first buffer and returns an accessor for it
+func (d *Document) addAccessor(data []byte, componentType, count int, typ string, min, max []float32) int {
+	if len(d.Buffers) == 0 {
+		d.Buffers = append(d.Buffers, Buffer{})
+		d.data = append(d.data, nil)
+	}
+	offset := len(d.data[0])
+	d.data[0] = pad(append(d.data[0], data...), 0)
+	d.Buffers[0].ByteLength = len(d.data[0])
+
+	d.BufferViews = append(d.BufferViews, BufferView{ByteOffset: offset, ByteLength: len(data)})
+	view := len(d.BufferViews) - 1
+	d.Accessors = append(d.Accessors, Accessor{
+		BufferView:    &view,
+		ComponentType: componentType,
+		Count:         count,
+		Type:          typ,
+		Min:           min,
+		Max:           max,
+	})
+	return len(d.Accessors) - 1
+}
@@ -0,0 +1,279 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func littleEndian(t *testing.T, values any) []byte {
+	t.Helper()
+	data, err := binary.Append(nil, binary.LittleEndian, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func ptr[T any](v T) *T { return &v }
+
+//testDocument is a triangle strip with a box collider, a physics material and a joint
+func testDocument(t *testing.T) *Document {
+	d := &Document{
+		Asset:          Asset{Version: "2.0", Generator: "test"},
+		ExtensionsUsed: []string{extensionPhysics, extensionShapes},
+		Scene:          ptr(0),
+		Scenes:         []Scene{{Nodes: []int{0, 1}}},
+		Extensions: &Extensions{
+			Physics: &Physics{
+				PhysicsMaterials: []PhysicsMaterial{{StaticFriction: ptr[float32](0.4), Restitution: 0.2}},
+				PhysicsJoints: []PhysicsJoint{{Limits: []JointLimit{
+					{LinearAxes: []int{0, 1, 2}, Min: ptr[float32](0), Max: ptr[float32](0)},
+				}}},
+			},
+			Shapes: &Shapes{Shapes: []Shape{{Type: "box", Box: &Box{Size: [3]float32{1, 2, 3}}}}},
+		},
+	}
+
+	position := d.addAccessor(littleEndian(t, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0, 1, 1, 0}),
+		ComponentFloat, 4, "VEC3", []float32{0, 0, 0}, []float32{1, 1, 0})
+	index := d.addAccessor(littleEndian(t, []uint16{0, 1, 2, 3}), ComponentUnsignedShort, 4, "SCALAR", nil, nil)
+	d.Meshes = []Mesh{{Name: "strip", Primitives: []Primitive{{
+		Attributes: map[string]int{"POSITION": position},
+		Indices:    &index,
+		Mode:       ptr(ModeTriangleStrip),
+	}}}}
+
+	d.Nodes = []Node{
+		{
+			Name:        "body",
+			Translation: &[3]float32{1, 2, 3},
+			Rotation:    &[4]float32{0, 0, 0, 1},
+			Extensions: &NodeExtensions{Physics: &NodePhysics{
+				Motion:   &Motion{Mass: ptr[float32](2), LinearVelocity: &[3]float32{0, -1, 0}},
+				Collider: &Collider{Geometry: Geometry{Shape: ptr(0)}, PhysicsMaterial: ptr(0)},
+			}},
+		},
+		{
+			Name: "floor",
+			Mesh: ptr(0),
+			Extensions: &NodeExtensions{Physics: &NodePhysics{
+				Collider: &Collider{Geometry: Geometry{Node: ptr(1)}},
+				Joint:    &NodeJoint{ConnectedNode: 0, Joint: 0},
+			}},
+		},
+	}
+	return d
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		encode func(d *Document, buf *bytes.Buffer) error
+	}{
+		{"gltf", func(d *Document, buf *bytes.Buffer) error { return d.Encode(buf) }},
+		{"glb", func(d *Document, buf *bytes.Buffer) error { return d.EncodeGLB(buf) }},
+	}
+
+	for _, test := range tests {
+		want := testDocument(t)
+		buf := &bytes.Buffer{}
+		if err := test.encode(want, buf); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got, err := Decode(buf)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		//buffers are written out with a data uri, or in the glb's binary chunk
+		if len(got.Buffers) != 1 || !bytes.Equal(got.data[0], want.data[0]) {
+			t.Errorf("%s: buffers %v, want %d bytes", test.name, got.Buffers, len(want.data[0]))
+		}
+		got.Buffers, want.Buffers = nil, nil
+		got.data, want.data = nil, nil
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: decoded\n%+v\nwant\n%+v", test.name, got, want)
+		}
+	}
+}
+
+func TestTriangles(t *testing.T) {
+	d := testDocument(t)
+	p := &d.Meshes[0].Primitives[0]
+
+	tests := []struct {
+		name string
+		mode *int
+		want []int
+	}{
+		{"default", nil, []int{0, 1, 2}},
+		{"triangles", ptr(ModeTriangles), []int{0, 1, 2}},
+		{"strip", ptr(ModeTriangleStrip), []int{0, 1, 2, 1, 3, 2}},
+		{"fan", ptr(ModeTriangleFan), []int{1, 2, 0, 2, 3, 0}},
+	}
+
+	for _, test := range tests {
+		p.Mode = test.mode
+		tri, err := d.triangles(p)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(tri.indices, test.want) {
+			t.Errorf("%s: indices %v, want %v", test.name, tri.indices, test.want)
+		}
+		if len(tri.normals) != len(tri.positions) || len(tri.uvs) != len(tri.positions)/3*2 {
+			t.Errorf("%s: %d normals and %d uvs for %d positions", test.name, len(tri.normals),
+				len(tri.uvs), len(tri.positions))
+		}
+	}
+
+	p.Mode = ptr(0) //points
+	if tri, err := d.triangles(p); tri != nil || err != nil {
+		t.Errorf("points: got %v, %v, want nothing", tri, err)
+	}
+}
+
+//accessorDocument is a document with a single buffer view over data, and the accessor
+func accessorDocument(data []byte, view BufferView, a Accessor) *Document {
+	view.ByteLength += len(data)
+	a.BufferView = ptr(0)
+	return &Document{
+		Accessors:   []Accessor{a},
+		BufferViews: []BufferView{view},
+		Buffers:     []Buffer{{ByteLength: len(data)}},
+		data:        [][]byte{data},
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	tests := []struct {
+		name       string
+		d          *Document
+		components int
+		want       []float32
+	}{
+		{"floats", accessorDocument(littleEndian(t, []float32{1, 2, 3, 4}), BufferView{},
+			Accessor{ComponentType: ComponentFloat, Count: 2, Type: "VEC2"}), 2, []float32{1, 2, 3, 4}},
+		{"bytes", accessorDocument([]byte{0xff, 1}, BufferView{},
+			Accessor{ComponentType: ComponentByte, Count: 2, Type: "SCALAR"}), 1, []float32{-1, 1}},
+		{"normalized unsigned bytes", accessorDocument([]byte{0, 255}, BufferView{},
+			Accessor{ComponentType: ComponentUnsignedByte, Normalized: true, Count: 2, Type: "SCALAR"}),
+			1, []float32{0, 1}},
+		{"normalized shorts", accessorDocument(littleEndian(t, []int16{-32768, 32767}), BufferView{},
+			Accessor{ComponentType: ComponentShort, Normalized: true, Count: 2, Type: "SCALAR"}),
+			1, []float32{-1, 1}},
+		{"unsigned ints", accessorDocument(littleEndian(t, []uint32{7, 70000}), BufferView{},
+			Accessor{ComponentType: ComponentUnsignedInt, Count: 2, Type: "SCALAR"}), 1, []float32{7, 70000}},
+		{"offsets and stride", accessorDocument(littleEndian(t, []float32{9, 9, 1, 9, 2, 9}),
+			BufferView{ByteOffset: 4, ByteLength: -4, ByteStride: 8},
+			Accessor{ByteOffset: 4, ComponentType: ComponentFloat, Count: 2, Type: "SCALAR"}),
+			1, []float32{1, 2}},
+		{"no buffer view", &Document{Accessors: []Accessor{
+			{ComponentType: ComponentFloat, Count: 2, Type: "VEC3"}}}, 3, []float32{0, 0, 0, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		got, err := test.d.floats(0, test.components)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAccessorErrors(t *testing.T) {
+	floats := littleEndian(t, []float32{1, 2, 3, 4})
+	float := Accessor{ComponentType: ComponentFloat, Count: 4, Type: "SCALAR"}
+	with := func(change func(view *BufferView, a *Accessor)) *Document {
+		view, a := BufferView{}, float
+		change(&view, &a)
+		return accessorDocument(floats, view, a)
+	}
+
+	tests := []struct {
+		name string
+		d    *Document
+	}{
+		{"missing accessor", &Document{}},
+		{"wrong type", with(func(view *BufferView, a *Accessor) { a.Type = "VEC2" })},
+		{"bad component type", with(func(view *BufferView, a *Accessor) { a.ComponentType = 1 })},
+		{"missing buffer view", func() *Document {
+			d := accessorDocument(floats, BufferView{}, float)
+			d.Accessors[0].BufferView = ptr(1)
+			return d
+		}()},
+		{"too many elements", with(func(view *BufferView, a *Accessor) { a.Count = 5 })},
+		{"view past the buffer", with(func(view *BufferView, a *Accessor) { view.ByteOffset = 4 })},
+		{"negative count", with(func(view *BufferView, a *Accessor) { a.Count = -1 })},
+		{"negative accessor offset", with(func(view *BufferView, a *Accessor) { a.ByteOffset = -4 })},
+		{"negative view offset", with(func(view *BufferView, a *Accessor) { view.ByteOffset = -4 })},
+		{"negative view length", with(func(view *BufferView, a *Accessor) { view.ByteLength = -32 })},
+		{"negative stride", with(func(view *BufferView, a *Accessor) { view.ByteStride = -4 })},
+	}
+
+	for _, test := range tests {
+		if _, err := test.d.floats(0, 1); !errors.Is(err, ErrFormat) {
+			t.Errorf("%s: got error %v, want ErrFormat", test.name, err)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	glb := &bytes.Buffer{}
+	if err := testDocument(t).EncodeGLB(glb); err != nil {
+		t.Fatal(err)
+	}
+	data := base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4})
+	//withLength is the glb with its header's total length changed
+	withLength := func(length uint32) string {
+		b := bytes.Clone(glb.Bytes())
+		binary.LittleEndian.PutUint32(b[8:], length)
+		return string(b)
+	}
+
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"not json", "glTF?", ErrFormat},
+		{"version 1", `{"asset": {"version": "1.0"}}`, ErrUnsupported},
+		{"unknown required extension", `{"asset": {"version": "2.0"}, "extensionsRequired": ["KHR_draco_mesh_compression"]}`,
+			ErrUnsupported},
+		{"bad data uri", `{"asset": {"version": "2.0"}, "buffers": [{"uri": "data:nothing", "byteLength": 4}]}`,
+			ErrFormat},
+		{"external buffer", `{"asset": {"version": "2.0"}, "buffers": [{"uri": "mesh.bin", "byteLength": 4}]}`,
+			ErrFormat},
+		{"short buffer", `{"asset": {"version": "2.0"}, "buffers": [{"uri": "data:application/octet-stream;base64,` +
+			data + `", "byteLength": 8}]}`, ErrFormat},
+		{"truncated glb", glb.String()[:glb.Len()-8], ErrFormat},
+		{"glb length inside the header", withLength(8), ErrFormat},
+		{"glb length past the data", withLength(uint32(glb.Len() + 4)), ErrFormat},
+		{"glb length inside a chunk", withLength(uint32(glb.Len() - 8)), ErrFormat},
+	}
+
+	for _, test := range tests {
+		if _, err := Decode(strings.NewReader(test.data)); !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	//anything after the glb's length isn't part of it
+	if _, err := Decode(strings.NewReader(glb.String() + "trailing data")); err != nil {
+		t.Errorf("glb with trailing data: %v", err)
+	}
+}
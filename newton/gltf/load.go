@@ -0,0 +1,768 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package gltf
+
+import (
+	"fmt"
+	"math"
+
+	"bitbucket.org/tshannon/gonewton/newton"
+)
+
+//Instance is what Load created in the world
+type Instance struct {
+	Meshes map[int][]*newton.Mesh //by glTF mesh, one for each primitive, nil for ones without triangles
+	Bodies map[int]*newton.Body   //by the node the body is on
+	Joints map[int]*newton.Joint  //by the node with the joint's frame
+}
+
+//loader is the state of a single Load
+type loader struct {
+	d        *Document
+	w        *newton.World
+	in       *Instance
+	parents  []int         //-1 for root nodes
+	matrices []newton.Mat4 //world transform of each node in the scene
+	inScene  []bool
+	groups   []int //material group of each physics material
+}
+
+//Load creates the document's meshes, and the bodies and joints of its default scene, in w.
+//
+// Every node with motion becomes a body, with the colliders of it and the nodes below it.
+// Colliders that aren't below a node with motion become static bodies.  Bodies are
+// created without a force and torque callback, so set one to apply gravity.
+//
+// Joints are matched to the Newton joint with the same locked axes: a ball, hinge,
+// universal, slider, corkscrew or up vector joint.  Limits with a range, rather than
+// locking an axis, are ignored.
+//
+// Triangle mesh colliders can only be used on static bodies, use convex hulls for
+// anything that moves
+func (d *Document) Load(w *newton.World) (in *Instance, err error) {
+	l := &loader{
+		d: d,
+		w: w,
+		in: &Instance{
+			Meshes: make(map[int][]*newton.Mesh),
+			Bodies: make(map[int]*newton.Body),
+			Joints: make(map[int]*newton.Joint),
+		},
+	}
+	defer func() {
+		if err != nil {
+			l.in.destroy(w)
+		}
+	}()
+
+	if err := l.nodes(); err != nil {
+		return nil, err
+	}
+	if err := l.meshes(); err != nil {
+		return nil, err
+	}
+	l.materials()
+	if err := l.bodies(); err != nil {
+		return nil, err
+	}
+	if err := l.joints(); err != nil {
+		return nil, err
+	}
+	return l.in, nil
+}
+
+func (in *Instance) destroy(w *newton.World) {
+	for _, joint := range in.Joints {
+		w.DestroyJoint(joint)
+	}
+	for _, body := range in.Bodies {
+		w.DestroyBody(body)
+	}
+	for _, meshes := range in.Meshes {
+		for _, mesh := range meshes {
+			if mesh != nil {
+				mesh.Destroy()
+			}
+		}
+	}
+}
+
+//local returns the node's transform relative to its parent
+func (n *Node) local() newton.Mat4 {
+	if n.Matrix != nil {
+		return newton.Mat4(*n.Matrix)
+	}
+
+	m := newton.Identity()
+	if r := n.Rotation; r != nil {
+		m = newton.Quat{r[3], r[0], r[1], r[2]}.Normalize().Mat4()
+	}
+	if s := n.Scale; s != nil {
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				m[row*4+col] *= s[row]
+			}
+		}
+	}
+	if t := n.Translation; t != nil {
+		m.SetPosition(newton.Vec3(*t))
+	}
+	return m
+}
+
+//rigid splits m into a transform without scale, and the scale along each of its axes
+func rigid(m newton.Mat4) (newton.Mat4, newton.Vec3) {
+	r := newton.Identity()
+	var scale newton.Vec3
+	for row := 0; row < 3; row++ {
+		axis := newton.Vec3{m[row*4], m[row*4+1], m[row*4+2]}
+		scale[row] = axis.Len()
+		if scale[row] != 0 {
+			axis = axis.Scale(1 / scale[row])
+		}
+		copy(r[row*4:row*4+3], axis[:])
+	}
+
+	//a mirrored transform keeps the mirror in the scale
+	if r.Front().Cross(r.Up()).Dot(r.Right()) < 0 {
+		scale[0] = -scale[0]
+		r[0], r[1], r[2] = -r[0], -r[1], -r[2]
+	}
+	r.SetPosition(m.Position())
+	return r, scale
+}
+
+//physics returns the node's physics extension, or an empty one
+func (d *Document) physics(node int) *NodePhysics {
+	if e := d.Nodes[node].Extensions; e != nil && e.Physics != nil {
+		return e.Physics
+	}
+	return &NodePhysics{}
+}
+
+func (d *Document) physicsExtension() *Physics {
+	if d.Extensions != nil && d.Extensions.Physics != nil {
+		return d.Extensions.Physics
+	}
+	return &Physics{}
+}
+
+//nodes finds each node's parent, and the world transform of the nodes in the scene
+func (l *loader) nodes() error {
+	d := l.d
+	l.parents = make([]int, len(d.Nodes))
+	for i := range l.parents {
+		l.parents[i] = -1
+	}
+	for i := range d.Nodes {
+		for _, child := range d.Nodes[i].Children {
+			if child < 0 || child >= len(d.Nodes) || l.parents[child] != -1 {
+				return fmt.Errorf("%w: node %d has an invalid child %d", ErrFormat, i, child)
+			}
+			l.parents[child] = i
+		}
+	}
+
+	var roots []int
+	switch {
+	case len(d.Scenes) > 0:
+		scene := 0
+		if d.Scene != nil {
+			scene = *d.Scene
+		}
+		if scene < 0 || scene >= len(d.Scenes) {
+			return fmt.Errorf("%w: scene %d doesn't exist", ErrFormat, scene)
+		}
+		roots = d.Scenes[scene].Nodes
+	default:
+		for i := range d.Nodes {
+			if l.parents[i] == -1 {
+				roots = append(roots, i)
+			}
+		}
+	}
+
+	l.matrices = make([]newton.Mat4, len(d.Nodes))
+	l.inScene = make([]bool, len(d.Nodes))
+	var place func(node int, parent newton.Mat4) error
+	place = func(node int, parent newton.Mat4) error {
+		if node < 0 || node >= len(d.Nodes) || l.inScene[node] {
+			return fmt.Errorf("%w: invalid node %d in scene", ErrFormat, node)
+		}
+		l.inScene[node] = true
+		l.matrices[node] = d.Nodes[node].local().Mul(parent)
+		for _, child := range d.Nodes[node].Children {
+			if err := place(child, l.matrices[node]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, root := range roots {
+		if root >= 0 && root < len(d.Nodes) && l.parents[root] != -1 {
+			return fmt.Errorf("%w: scene root %d isn't a root node", ErrFormat, root)
+		}
+		if err := place(root, newton.Identity()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//meshes creates a newton mesh for each triangle primitive
+func (l *loader) meshes() error {
+	for i := range l.d.Meshes {
+		meshes := make([]*newton.Mesh, len(l.d.Meshes[i].Primitives))
+		l.in.Meshes[i] = meshes
+		for p := range l.d.Meshes[i].Primitives {
+			mesh, err := l.mesh(&l.d.Meshes[i].Primitives[p])
+			if err != nil {
+				return fmt.Errorf("mesh %d primitive %d: %w", i, p, err)
+			}
+			meshes[p] = mesh
+		}
+	}
+	return nil
+}
+
+//triangles is the geometry of a primitive, as a list of triangles
+type triangles struct {
+	positions []float32
+	normals   []float32
+	uvs       []float32
+	indices   []int
+}
+
+//triangles reads a primitive, returning nil if it isn't made of triangles
+func (d *Document) triangles(p *Primitive) (*triangles, error) {
+	mode := ModeTriangles
+	if p.Mode != nil {
+		mode = *p.Mode
+	}
+	if mode != ModeTriangles && mode != ModeTriangleStrip && mode != ModeTriangleFan {
+		return nil, nil
+	}
+
+	position, ok := p.Attributes["POSITION"]
+	if !ok {
+		return nil, nil
+	}
+	t := &triangles{}
+	var err error
+	if t.positions, err = d.floats(position, 3); err != nil {
+		return nil, err
+	}
+	count := len(t.positions) / 3
+
+	if normal, ok := p.Attributes["NORMAL"]; ok {
+		if t.normals, err = d.floats(normal, 3); err != nil {
+			return nil, err
+		}
+	}
+	if len(t.normals) != len(t.positions) {
+		t.normals = make([]float32, count*3)
+	}
+	if uv, ok := p.Attributes["TEXCOORD_0"]; ok {
+		if t.uvs, err = d.floats(uv, 2); err != nil {
+			return nil, err
+		}
+	}
+	if len(t.uvs) != count*2 {
+		t.uvs = make([]float32, count*2)
+	}
+
+	var indices []int
+	if p.Indices != nil {
+		if indices, err = d.indices(*p.Indices, count); err != nil {
+			return nil, err
+		}
+	} else {
+		indices = make([]int, count)
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+
+	switch mode {
+	case ModeTriangles:
+		t.indices = indices[:len(indices)/3*3]
+	case ModeTriangleStrip:
+		for i := 0; i+2 < len(indices); i++ {
+			t.indices = append(t.indices, indices[i], indices[i+1+i%2], indices[i+2-i%2])
+		}
+	case ModeTriangleFan:
+		for i := 0; i+2 < len(indices); i++ {
+			t.indices = append(t.indices, indices[i+1], indices[i+2], indices[0])
+		}
+	}
+	if len(t.indices) == 0 {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func (l *loader) mesh(p *Primitive) (*newton.Mesh, error) {
+	t, err := l.d.triangles(p)
+	if t == nil || err != nil {
+		return nil, err
+	}
+
+	material := 0
+	if p.Material != nil {
+		material = *p.Material
+	}
+	faces := len(t.indices) / 3
+	faceIndexCount := make([]int, faces)
+	faceMaterial := make([]int, faces)
+	for i := range faceIndexCount {
+		faceIndexCount[i] = 3
+		faceMaterial[i] = material
+	}
+
+	mesh, err := l.w.CreateMesh()
+	if err != nil {
+		return nil, err
+	}
+	mesh.BuildFromVertexListIndexList(faces, faceIndexCount, faceMaterial,
+		t.positions, 12, t.indices,
+		t.normals, 12, t.indices,
+		t.uvs, 8, t.indices,
+		t.uvs, 8, t.indices)
+	return mesh, nil
+}
+
+//combine modes, in order of precedence when two materials use different ones
+var combineModes = []string{"average", "minimum", "multiply", "maximum"}
+
+func combine(mode0, mode1 string, a, b float32) float32 {
+	mode := 0
+	for i, m := range combineModes {
+		if m == mode0 || m == mode1 {
+			mode = i
+		}
+	}
+
+	switch combineModes[mode] {
+	case "minimum":
+		return min(a, b)
+	case "multiply":
+		return a * b
+	case "maximum":
+		return max(a, b)
+	}
+	return (a + b) / 2
+}
+
+//materials creates a material group for each physics material, with the defaults of
+// each pair combined from the two materials
+func (l *loader) materials() {
+	materials := l.d.physicsExtension().PhysicsMaterials
+	l.groups = make([]int, len(materials))
+	for i := range materials {
+		l.groups[i] = l.w.CreateMaterialGroupID()
+	}
+
+	set := func(id0, id1 int, a, b *PhysicsMaterial) {
+		static0, dynamic0 := a.friction()
+		static1, dynamic1 := b.friction()
+		l.w.SetMaterialDefaultFriction(id0, id1,
+			combine(a.FrictionCombine, b.FrictionCombine, static0, static1),
+			combine(a.FrictionCombine, b.FrictionCombine, dynamic0, dynamic1))
+		l.w.SetMaterialDefaultElasticity(id0, id1,
+			combine(a.RestitutionCombine, b.RestitutionCombine, a.Restitution, b.Restitution))
+	}
+
+	def := l.w.DefaultMaterialGroupID()
+	for i := range materials {
+		//bodies without a material only have this one's properties
+		set(l.groups[i], def, &materials[i], &materials[i])
+		for j := i; j < len(materials); j++ {
+			set(l.groups[i], l.groups[j], &materials[i], &materials[j])
+		}
+	}
+}
+
+//bodyNode returns the node of the body a collider belongs to: the nearest node with
+// motion at or above it, or the collider's own node for a static body
+func (l *loader) bodyNode(node int) int {
+	for n := node; n >= 0; n = l.parents[n] {
+		if l.d.physics(n).Motion != nil {
+			return n
+		}
+	}
+	return node
+}
+
+//bodies creates a body for every node with motion, and every collider that isn't
+// part of one
+func (l *loader) bodies() error {
+	colliders := make(map[int][]int)
+	for n := range l.d.Nodes {
+		if !l.inScene[n] {
+			continue
+		}
+		physics := l.d.physics(n)
+		switch {
+		case physics.Collider != nil:
+			b := l.bodyNode(n)
+			colliders[b] = append(colliders[b], n)
+		case physics.Motion != nil:
+			if _, ok := colliders[n]; !ok {
+				colliders[n] = nil
+			}
+		}
+	}
+
+	//in node order, so bodies are created in the same order each time
+	for n := range l.d.Nodes {
+		if c, ok := colliders[n]; ok {
+			if err := l.body(n, c); err != nil {
+				return fmt.Errorf("node %d: %w", n, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (l *loader) body(node int, colliders []int) error {
+	matrix, _ := rigid(l.matrices[node])
+	motion := l.d.physics(node).Motion
+
+	collision, err := l.collision(colliders, matrix.Inverse(), motion == nil)
+	if err != nil {
+		return err
+	}
+	//the body holds its own reference to the collision
+	defer collision.Destroy()
+
+	m := [16]float32(matrix)
+	var body *newton.Body
+	if motion != nil && motion.IsKinematic {
+		body, err = l.w.CreateKinematicBody(collision, &m)
+	} else {
+		body, err = l.w.CreateDynamicBody(collision, &m)
+	}
+	if err != nil {
+		return err
+	}
+	l.in.Bodies[node] = body
+
+	if motion != nil {
+		mass := float32(1)
+		if motion.Mass != nil {
+			mass = *motion.Mass
+		}
+		if i := motion.InertiaDiagonal; i != nil {
+			body.SetMassMatrix(mass, i[0], i[1], i[2])
+		} else {
+			body.SetMassProperties(mass, collision)
+		}
+		if motion.CenterOfMass != nil {
+			body.SetCentreOfMass(motion.CenterOfMass)
+		}
+		if motion.LinearVelocity != nil {
+			body.SetVelocity(motion.LinearVelocity)
+		}
+		if motion.AngularVelocity != nil {
+			body.SetOmega(motion.AngularVelocity)
+		}
+	}
+
+	for _, c := range colliders {
+		if material := l.d.physics(c).Collider.PhysicsMaterial; material != nil {
+			if *material < 0 || *material >= len(l.groups) {
+				return fmt.Errorf("%w: physics material %d doesn't exist", ErrFormat, *material)
+			}
+			body.SetMaterialGroupID(l.groups[*material])
+			break
+		}
+	}
+	return nil
+}
+
+//collision creates a body's collision from its colliders.  Several colliders are
+// combined into a compound, or a scene collision for static bodies
+func (l *loader) collision(colliders []int, inverse newton.Mat4, static bool) (*newton.Collision, error) {
+	if len(colliders) == 0 {
+		return l.w.CreateNull()
+	}
+
+	subs := make([]*newton.Collision, 0, len(colliders))
+	offsets := make([][16]float32, 0, len(colliders))
+	defer func() {
+		for _, sub := range subs {
+			sub.Destroy()
+		}
+	}()
+	for _, c := range colliders {
+		sub, offset, err := l.collider(c, inverse, static)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+		offsets = append(offsets, offset)
+	}
+
+	if len(subs) == 1 {
+		collision := subs[0]
+		subs = nil
+		return collision, nil
+	}
+
+	if static {
+		scene, err := l.w.CreateSceneCollision(0)
+		if err != nil {
+			return nil, err
+		}
+		scene.SceneBeginAddRemove()
+		for i, sub := range subs {
+			scene.SceneSetSubCollisionMatrix(scene.SceneAddSubCollision(sub), &offsets[i])
+		}
+		scene.SceneEndAddRemove()
+		return scene, nil
+	}
+
+	compound, err := l.w.CreateCompoundCollision(0)
+	if err != nil {
+		return nil, err
+	}
+	compound.CompoundBeginAddRemove()
+	for i, sub := range subs {
+		compound.SetSubCollisionMatrix(compound.CompoundAddSubCollision(sub), &offsets[i])
+	}
+	compound.CompoundEndAddRemove()
+	return compound, nil
+}
+
+//xToY turns Newton's capsules and cylinders, which run along X, to run along Y
+var xToY = newton.RotationMatrix(newton.Vec3{0, 0, 1}, math.Pi/2)
+
+//collider creates the collision of a collider node, placed relative to its body,
+// along with that placement.  The node's index is the collision's shape ID
+func (l *loader) collider(node int, inverse newton.Mat4, static bool) (*newton.Collision, [16]float32, error) {
+	geometry := &l.d.physics(node).Collider.Geometry
+	switch {
+	case geometry.Shape != nil:
+		offset, scale := rigid(l.matrices[node].Mul(inverse))
+		collision, err := l.shape(*geometry.Shape, node, &offset, &scale)
+		return collision, [16]float32(offset), err
+	case geometry.Node != nil:
+		collision, err := l.meshCollider(*geometry.Node, node, inverse, geometry.ConvexHull, static)
+		return collision, [16]float32(newton.Identity()), err
+	}
+	return nil, [16]float32{}, fmt.Errorf("%w: collider %d has no geometry", ErrFormat, node)
+}
+
+//shape creates an implicit shape.  offset and scale are updated for capsules and
+// cylinders, which are turned to run along Y
+func (l *loader) shape(index, shapeID int, offset *newton.Mat4, scale *newton.Vec3) (*newton.Collision, error) {
+	var shapes []Shape
+	if l.d.Extensions != nil && l.d.Extensions.Shapes != nil {
+		shapes = l.d.Extensions.Shapes.Shapes
+	}
+	if index < 0 || index >= len(shapes) {
+		return nil, fmt.Errorf("%w: shape %d doesn't exist", ErrFormat, index)
+	}
+	s := &shapes[index]
+
+	//round shapes are created along X, then turned
+	var radiusTop, radiusBottom, height float32
+	switch {
+	case s.Type == "capsule" && s.Capsule != nil:
+		radiusTop, radiusBottom, height = s.Capsule.RadiusTop, s.Capsule.RadiusBottom, s.Capsule.Height
+	case s.Type == "cylinder" && s.Cylinder != nil:
+		radiusTop, radiusBottom, height = s.Cylinder.RadiusTop, s.Cylinder.RadiusBottom, s.Cylinder.Height
+	}
+	if s.Type == "capsule" || s.Type == "cylinder" {
+		*offset = xToY.Mul(*offset)
+		scale[0], scale[1] = scale[1], scale[0]
+	}
+
+	m := [16]float32(*offset)
+	var collision *newton.Collision
+	var err error
+	switch {
+	case s.Type == "sphere" && s.Sphere != nil:
+		collision, err = l.w.CreateSphere(s.Sphere.Radius, shapeID, &m)
+	case s.Type == "box" && s.Box != nil:
+		collision, err = l.w.CreateBox(s.Box.Size[0], s.Box.Size[1], s.Box.Size[2], shapeID, &m)
+	case s.Type == "capsule" && s.Capsule != nil:
+		//Newton's capsule height includes the caps
+		if radiusTop == radiusBottom {
+			collision, err = l.w.CreateCapsule(radiusTop, height+2*radiusTop, shapeID, &m)
+		} else {
+			collision, err = l.w.CreateTaperedCapsule(radiusBottom, radiusTop, height+radiusTop+radiusBottom,
+				shapeID, &m)
+		}
+	case s.Type == "cylinder" && s.Cylinder != nil:
+		if radiusTop == radiusBottom {
+			collision, err = l.w.CreateCylinder(radiusTop, height, shapeID, &m)
+		} else {
+			collision, err = l.w.CreateTaperedCylinder(radiusBottom, radiusTop, height, shapeID, &m)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s shape", ErrUnsupported, s.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if *scale != (newton.Vec3{1, 1, 1}) {
+		collision.SetScale(scale[0], scale[1], scale[2])
+	}
+	return collision, nil
+}
+
+//meshCollider creates a convex hull or tree collision from the mesh of a node, with
+// the node's transform relative to the body applied to its vertices
+func (l *loader) meshCollider(node, shapeID int, inverse newton.Mat4, convexHull, static bool) (*newton.Collision, error) {
+	if node < 0 || node >= len(l.d.Nodes) || l.d.Nodes[node].Mesh == nil ||
+		*l.d.Nodes[node].Mesh < 0 || *l.d.Nodes[node].Mesh >= len(l.d.Meshes) {
+		return nil, fmt.Errorf("%w: collider geometry node %d has no mesh", ErrFormat, node)
+	}
+	if !convexHull && !static {
+		return nil, fmt.Errorf("%w: triangle mesh collider on a moving body", ErrUnsupported)
+	}
+
+	transform := l.matrices[node].Mul(inverse)
+	var vertices []float32
+	for p := range l.d.Meshes[*l.d.Nodes[node].Mesh].Primitives {
+		t, err := l.d.triangles(&l.d.Meshes[*l.d.Nodes[node].Mesh].Primitives[p])
+		if err != nil {
+			return nil, err
+		}
+		if t == nil {
+			continue
+		}
+		for _, i := range t.indices {
+			v := transform.TransformPoint(newton.Vec3(t.positions[i*3 : i*3+3]))
+			vertices = append(vertices, v[:]...)
+		}
+	}
+	if len(vertices) == 0 {
+		return nil, fmt.Errorf("%w: collider geometry node %d has no triangles", ErrFormat, node)
+	}
+
+	if convexHull {
+		return l.w.CreateConvexHull(len(vertices)/3, vertices, 12, 0, shapeID, nil)
+	}
+
+	tree, err := l.w.CreateTreeCollision(shapeID)
+	if err != nil {
+		return nil, err
+	}
+	tree.BeginTreeBuild()
+	for i := 0; i < len(vertices); i += 9 {
+		tree.AddTreeFace(3, vertices[i:i+9], 12, 0)
+	}
+	tree.EndTreeBuild(true)
+	return tree, nil
+}
+
+//owner returns the body a node belongs to, the nearest body at or above it
+func (l *loader) owner(node int) *newton.Body {
+	for n := node; n >= 0; n = l.parents[n] {
+		if body, ok := l.in.Bodies[n]; ok {
+			return body
+		}
+	}
+	return nil
+}
+
+//joints creates a joint for every node with one
+func (l *loader) joints() error {
+	for n := range l.d.Nodes {
+		if !l.inScene[n] || l.d.physics(n).Joint == nil {
+			continue
+		}
+		joint, err := l.joint(n, l.d.physics(n).Joint)
+		if err != nil {
+			return fmt.Errorf("node %d: %w", n, err)
+		}
+		l.in.Joints[n] = joint
+	}
+	return nil
+}
+
+//freeAxes returns the axes that aren't locked
+func freeAxes(locked [3]bool) []int {
+	var free []int
+	for axis, l := range locked {
+		if !l {
+			free = append(free, axis)
+		}
+	}
+	return free
+}
+
+func (l *loader) joint(node int, nj *NodeJoint) (*newton.Joint, error) {
+	joints := l.d.physicsExtension().PhysicsJoints
+	if nj.Joint < 0 || nj.Joint >= len(joints) {
+		return nil, fmt.Errorf("%w: physics joint %d doesn't exist", ErrFormat, nj.Joint)
+	}
+	if nj.ConnectedNode < 0 || nj.ConnectedNode >= len(l.d.Nodes) {
+		return nil, fmt.Errorf("%w: connected node %d doesn't exist", ErrFormat, nj.ConnectedNode)
+	}
+
+	child := l.owner(node)
+	if child == nil {
+		return nil, fmt.Errorf("%w: joint isn't on a body", ErrFormat)
+	}
+	parent := l.owner(nj.ConnectedNode)
+	if parent == child {
+		return nil, fmt.Errorf("%w: joint connects a body to itself", ErrFormat)
+	}
+
+	var linear, angular [3]bool
+	for _, limit := range joints[nj.Joint].Limits {
+		if !limit.locked() {
+			continue
+		}
+		for _, axis := range limit.LinearAxes {
+			if axis >= 0 && axis < 3 {
+				linear[axis] = true
+			}
+		}
+		for _, axis := range limit.AngularAxes {
+			if axis >= 0 && axis < 3 {
+				angular[axis] = true
+			}
+		}
+	}
+	freeLinear, freeAngular := freeAxes(linear), freeAxes(angular)
+
+	frame, _ := rigid(l.matrices[node])
+	axes := [3][3]float32{frame.Front(), frame.Up(), frame.Right()}
+	pivot := [3]float32(frame.Position())
+
+	var joint *newton.Joint
+	var err error
+	switch {
+	case len(freeLinear) == 0 && len(freeAngular) == 3:
+		joint, err = l.w.CreateBall(&pivot, child, parent)
+	case len(freeLinear) == 0 && len(freeAngular) == 1:
+		joint, err = l.w.CreateHinge(&pivot, &axes[freeAngular[0]], child, parent)
+	case len(freeLinear) == 0 && len(freeAngular) == 2:
+		joint, err = l.w.CreateUniversal(&pivot, &axes[freeAngular[0]], &axes[freeAngular[1]], child, parent)
+	case len(freeLinear) == 1 && len(freeAngular) == 0:
+		joint, err = l.w.CreateSlider(&pivot, &axes[freeLinear[0]], child, parent)
+	case len(freeLinear) == 1 && len(freeAngular) == 1 && freeLinear[0] == freeAngular[0]:
+		joint, err = l.w.CreateCorkscrew(&pivot, &axes[freeLinear[0]], child, parent)
+	case len(freeLinear) == 3 && len(freeAngular) == 1 && parent == nil:
+		joint, err = l.w.CreateUpVector(&axes[freeAngular[0]], child)
+	default:
+		return nil, fmt.Errorf("%w: joint with free linear axes %v and angular axes %v", ErrUnsupported,
+			freeLinear, freeAngular)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if nj.EnableCollision {
+		joint.SetCollisionState(1)
+	} else {
+		joint.SetCollisionState(0)
+	}
+	return joint, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+/*
+#include "Newton.h"
+*/
+import "C"
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+var ErrHeightFieldSize = errors.New("newton: heightfield data doesn't match its size")
+
+//CreateHeightFieldCollision creates a static terrain from a grid of width by height
+// elevations, stored row by row.  Grid points are horizontalScale apart along X and Z,
+// and each elevation is multiplied by verticalScale to give its height along Y.
+// attributes is the face attribute of each grid cell, reported in contacts the same as
+// a tree collision's, and may be nil
+func (w *World) CreateHeightFieldCollision(width, height int, elevations []float32, attributes []int8,
+	horizontalScale, verticalScale float32, shapeID int) (*Collision, error) {
+	if width < 2 || height < 2 || len(elevations) != width*height {
+		return nil, ErrHeightFieldSize
+	}
+	if attributes == nil {
+		attributes = make([]int8, width*height)
+	}
+	if len(attributes) != width*height {
+		return nil, ErrHeightFieldSize
+	}
+
+	return createdCollision(owner(w.handle), C.NewtonCreateHeightFieldCollision(w.ptr(), C.int(width),
+		C.int(height), 0, (*C.dFloat)(&elevations[0]), (*C.char)(&attributes[0]), C.dFloat(verticalScale),
+		C.dFloat(horizontalScale), C.int(shapeID)), "heightfield")
+}
+
+//CreateHeightFieldCollision16 is CreateHeightFieldCollision for 16 bit elevations, such
+// as those from ReadHeightMapPNG and ReadHeightMapRAW
+func (w *World) CreateHeightFieldCollision16(width, height int, elevations []uint16, attributes []int8,
+	horizontalScale, verticalScale float32, shapeID int) (*Collision, error) {
+	if width < 2 || height < 2 || len(elevations) != width*height {
+		return nil, ErrHeightFieldSize
+	}
+	converted := make([]float32, len(elevations))
+	for i, e := range elevations {
+		converted[i] = float32(e)
+	}
+	return w.CreateHeightFieldCollision(width, height, converted, attributes, horizontalScale, verticalScale,
+		shapeID)
+}
+
+//HeightMap is a grid of 16 bit elevations stored row by row
+type HeightMap struct {
+	Width, Height int
+	Elevations    []uint16
+}
+
+//CreateHeightFieldFromMap creates a heightfield from the height map
+func (w *World) CreateHeightFieldFromMap(m *HeightMap, attributes []int8, horizontalScale,
+	verticalScale float32, shapeID int) (*Collision, error) {
+	return w.CreateHeightFieldCollision16(m.Width, m.Height, m.Elevations, attributes, horizontalScale,
+		verticalScale, shapeID)
+}
+
+//ReadHeightMapPNG reads elevations from the brightness of a PNG, which should be a
+// 16 bit greyscale image to keep the full precision
+func ReadHeightMapPNG(r io.Reader) (*HeightMap, error) {
+	img, err := png.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	m := &HeightMap{
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Elevations: make([]uint16, bounds.Dx()*bounds.Dy()),
+	}
+	i := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			m.Elevations[i] = elevation(img, x, y)
+			i++
+		}
+	}
+	return m, nil
+}
+
+func elevation(img image.Image, x, y int) uint16 {
+	if gray, ok := img.(*image.Gray16); ok {
+		return gray.Gray16At(x, y).Y
+	}
+	return color.Gray16Model.Convert(img.At(x, y)).(color.Gray16).Y
+}
+
+//ReadHeightMapRAW reads width by height 16 bit elevations, with no header, in the given
+// byte order
+func ReadHeightMapRAW(r io.Reader, width, height int, order binary.ByteOrder) (*HeightMap, error) {
+	if width < 2 || height < 2 {
+		return nil, ErrHeightFieldSize
+	}
+	m := &HeightMap{Width: width, Height: height, Elevations: make([]uint16, width*height)}
+	if err := binary.Read(r, order, m.Elevations); err != nil {
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			return nil, ErrHeightFieldSize
+		}
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestReadHeightMapPNG(t *testing.T) {
+	gray16 := image.NewGray16(image.Rect(0, 0, 3, 2))
+	gray8 := image.NewGray(image.Rect(0, 0, 3, 2))
+	values := []uint16{0, 1, 0x1234, 0x8000, 0xfffe, 0xffff}
+	for i, v := range values {
+		gray16.SetGray16(i%3, i/3, color.Gray16{Y: v})
+		gray8.SetGray(i%3, i/3, color.Gray{Y: uint8(v >> 8)})
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want []uint16
+	}{
+		{"16 bit", gray16, values},
+		//8 bit values are widened to fill the 16 bit range, 0xab becomes 0xabab
+		{"8 bit", gray8, []uint16{0, 0, 0x1212, 0x8080, 0xffff, 0xffff}},
+	}
+
+	for _, test := range tests {
+		m, err := ReadHeightMapPNG(encodePNG(t, test.img))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if m.Width != 3 || m.Height != 2 {
+			t.Errorf("%s: size is %dx%d, want 3x2", test.name, m.Width, m.Height)
+		}
+		if !reflect.DeepEqual(m.Elevations, test.want) {
+			t.Errorf("%s: elevations %#04x, want %#04x", test.name, m.Elevations, test.want)
+		}
+	}
+}
+
+func TestReadHeightMapRAW(t *testing.T) {
+	data := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+	tests := []struct {
+		name          string
+		data          []byte
+		width, height int
+		order         binary.ByteOrder
+		want          []uint16
+		err           error
+	}{
+		{"little endian", data, 2, 2, binary.LittleEndian, []uint16{0x0201, 0x0403, 0x0605, 0x0807}, nil},
+		{"big endian", data, 2, 2, binary.BigEndian, []uint16{0x0102, 0x0304, 0x0506, 0x0708}, nil},
+		{"short", data[:7], 2, 2, binary.LittleEndian, nil, ErrHeightFieldSize},
+		{"empty", nil, 2, 2, binary.LittleEndian, nil, ErrHeightFieldSize},
+		{"too narrow", data, 1, 4, binary.LittleEndian, nil, ErrHeightFieldSize},
+	}
+
+	for _, test := range tests {
+		m, err := ReadHeightMapRAW(bytes.NewReader(test.data), test.width, test.height, test.order)
+		if !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !reflect.DeepEqual(m.Elevations, test.want) {
+			t.Errorf("%s: elevations %#04x, want %#04x", test.name, m.Elevations, test.want)
+		}
+	}
+}
+
+func TestHeightFieldSize(t *testing.T) {
+	//the size is checked before anything is converted or handed to Newton
+	w := &World{}
+	if _, err := w.CreateHeightFieldCollision16(3, 3, make([]uint16, 8), nil, 1, 1, 0); !errors.Is(err,
+		ErrHeightFieldSize) {
+		t.Errorf("short elevations: got error %v, want ErrHeightFieldSize", err)
+	}
+	if _, err := w.CreateHeightFieldCollision16(1, 4, make([]uint16, 4), nil, 1, 1, 0); !errors.Is(err,
+		ErrHeightFieldSize) {
+		t.Errorf("one column: got error %v, want ErrHeightFieldSize", err)
+	}
+}
@@ -10,43 +10,103 @@ package newton
 #include <stdlib.h>
 */
 import "C"
-import "reflect"
+import "fmt"
+import "iter"
 import "unsafe"
 
 type Joint struct {
 	handle *C.NewtonJoint
 }
 
+func (j *Joint) ptr() *C.NewtonJoint {
+	if j == nil || j.handle == nil {
+		panic(fmt.Sprintf(handlePanic, "Joint"))
+	}
+	return j.handle
+}
+
+//createdJoint returns an error if Newton couldn't create the joint, otherwise
+// the joint's spec is kept so it can be serialized
+func createdJoint(handle *C.NewtonJoint, spec *jointSpec) (*Joint, error) {
+	if handle == nil {
+		return nil, createError(spec.kind + " joint")
+	}
+	joint := newJoint(handle)
+	jointSpecs.set(owner(handle), spec)
+	return joint, nil
+}
+
+//parentPtr returns the parent body's handle, or NULL to attach the joint to the world
+func parentPtr(parent *Body) *C.NewtonBody {
+	if parent == nil {
+		return nil
+	}
+	return parent.ptr()
+}
+
 type Contact struct {
 	handle unsafe.Pointer
 }
 
+//FirstContact and NextContact return nil when there are no more contacts
 func (j *Joint) FirstContact() *Contact {
-	return &Contact{C.NewtonContactJointGetFirstContact(j.handle)}
+	handle := C.NewtonContactJointGetFirstContact(j.ptr())
+	if handle == nil {
+		return nil
+	}
+	return &Contact{handle}
 }
 
 func (j *Joint) NextContact(curContact *Contact) *Contact {
-	return &Contact{C.NewtonContactJointGetNextContact(j.handle, curContact.handle)}
+	handle := C.NewtonContactJointGetNextContact(j.ptr(), curContact.handle)
+	if handle == nil {
+		return nil
+	}
+	return &Contact{handle}
+}
+
+//Contacts iterates over the contacts of a contact joint.  The next contact is found
+// before the current one is yielded, so it's safe to call RemoveContact in the loop
+func (j *Joint) Contacts() iter.Seq[*Contact] {
+	return func(yield func(*Contact) bool) {
+		for contact := j.FirstContact(); contact != nil; {
+			next := j.NextContact(contact)
+			if !yield(contact) {
+				return
+			}
+			contact = next
+		}
+	}
 }
 
 func (j *Joint) ContactCount() int {
-	return int(C.NewtonContactJointGetContactCount(j.handle))
+	return int(C.NewtonContactJointGetContactCount(j.ptr()))
 }
 
 func (j *Joint) RemoveContact(contact *Contact) {
-	C.NewtonContactJointRemoveContact(j.handle, contact.handle)
+	C.NewtonContactJointRemoveContact(j.ptr(), contact.handle)
 }
 
 func (c *Contact) Material() *Material {
 	return &Material{C.NewtonContactGetMaterial(c.handle)}
 }
 
+func (j *Joint) UserData() interface{} {
+	data, _ := ownerData.get(owner(j.handle))
+	return data
+}
+
+//SetUserData sets the joint's user data, it's released when the joint is destroyed
+func (j *Joint) SetUserData(userData interface{}) {
+	ownerData.set(owner(j.ptr()), userData)
+}
+
 func (j *Joint) Body0() *Body {
-	return &Body{C.NewtonJointGetBody0(j.handle)}
+	return newBody(C.NewtonJointGetBody0(j.ptr()))
 }
 
 func (j *Joint) Body1() *Body {
-	return &Body{C.NewtonJointGetBody1(j.handle)}
+	return newBody(C.NewtonJointGetBody1(j.ptr()))
 }
 
 type JointRecord struct {
@@ -64,8 +124,9 @@ type JointRecord struct {
 }
 
 func (j *Joint) Info() *JointRecord {
-	var cInfo *C.NewtonJointRecord
-	C.NewtonJointGetInfo(j.handle, cInfo)
+	var record C.NewtonJointRecord
+	C.NewtonJointGetInfo(j.ptr(), &record)
+	cInfo := &record
 
 	return &JointRecord{
 		AttachmentMatrix0: get4x4Float(cInfo.m_attachmenMatrix_0),
@@ -74,49 +135,44 @@ func (j *Joint) Info() *JointRecord {
 		MaxLinearDof:      go3Floats(&cInfo.m_maxLinearDof[0]),
 		MinAngularDof:     go3Floats(&cInfo.m_minAngularDof[0]),
 		MaxAngularDof:     go3Floats(&cInfo.m_maxAngularDof[0]),
-		AttachBody0:       &Body{cInfo.m_attachBody_0},
-		AttachBody1:       &Body{cInfo.m_attachBody_1},
+		AttachBody0:       newBody(cInfo.m_attachBody_0),
+		AttachBody1:       newBody(cInfo.m_attachBody_1),
 		ExtraParameters:   go16Floats(&cInfo.m_extraParameters[0]),
 		BodiesCollisionOn: int(cInfo.m_bodiesCollisionOn),
 		DescriptionType:   C.GoString(&cInfo.m_descriptionType[0]),
 	}
 }
 
-//Untested, not sure why original lib switched to 4x4 arrays from
-// [16]arrays for matrices
+//get4x4Float copies a 4x4 matrix from Newton into a [16]float32 matrix
 func get4x4Float(array [4][4]C.dFloat) *[16]float32 {
-	gArray := [16]float32{}
-	for i := 0; i < 4; i++ {
-		slice := gArray[i : i+4]
-
-		slcHead := (*reflect.SliceHeader)(unsafe.Pointer(&slice))
-		slcHead.Cap = 16
-		slcHead.Len = 4
-		slcHead.Data = uintptr(unsafe.Pointer(&array[i][0]))
-
+	var m [16]float32
+	for i := range 4 {
+		for k := range 4 {
+			m[i*4+k] = float32(array[i][k])
+		}
 	}
-
-	return &gArray
+	return &m
 }
 
 func (j *Joint) CollisionState() int {
-	return int(C.NewtonJointGetCollisionState(j.handle))
+	return int(C.NewtonJointGetCollisionState(j.ptr()))
 }
 
 func (j *Joint) SetCollisionState(state int) {
-	C.NewtonJointSetCollisionState(j.handle, C.int(state))
+	C.NewtonJointSetCollisionState(j.ptr(), C.int(state))
 }
 
 func (j *Joint) Stiffness() float32 {
-	return float32(C.NewtonJointGetStiffness(j.handle))
+	return float32(C.NewtonJointGetStiffness(j.ptr()))
 }
 
 func (j *Joint) SetStiffness(stiffness float32) {
-	C.NewtonJointSetStiffness(j.handle, C.dFloat(stiffness))
+	C.NewtonJointSetStiffness(j.ptr(), C.dFloat(stiffness))
 }
 
 func (w *World) DestroyJoint(joint *Joint) {
-	C.NewtonDestroyJoint(w.handle, joint.handle)
+	C.NewtonDestroyJoint(w.ptr(), joint.ptr())
+	joint.handle = nil
 }
 
 //Particle Systems interface (soft bodies, pressure bodies, and cloth)
@@ -126,49 +182,63 @@ type DeformableMeshSegment struct {
 	collisionParent *C.NewtonCollision
 }
 
-func (w *World) CreateDeformableMesh(mesh *Mesh, shapeID int) *Collision {
-	return &Collision{C.NewtonCreateDeformableMesh(w.handle, mesh.handle, C.int(shapeID))}
+func (w *World) CreateDeformableMesh(mesh *Mesh, shapeID int) (*Collision, error) {
+	if mesh == nil {
+		return nil, ErrNilMesh
+	}
+	return createdCollision(owner(w.handle), C.NewtonCreateDeformableMesh(w.ptr(), mesh.ptr(), C.int(shapeID)),
+		"deformable mesh")
 }
 
 func (c *Collision) SetDeformableMeshPlasticity(plasticity float32) {
-	C.NewtonDeformableMeshSetPlasticity(c.handle, C.dFloat(plasticity))
+	C.NewtonDeformableMeshSetPlasticity(c.ptr(), C.dFloat(plasticity))
 }
 
 func (c *Collision) SetDeformableMeshStiffness(stiffness float32) {
-	C.NewtonDeformableMeshSetStiffness(c.handle, C.dFloat(stiffness))
+	C.NewtonDeformableMeshSetStiffness(c.ptr(), C.dFloat(stiffness))
 }
 
 func (c *Collision) SetDeformableMeshSkinThickness(skinThickness float32) {
-	C.NewtonDeformableMeshSetSkinThickness(c.handle, C.dFloat(skinThickness))
+	C.NewtonDeformableMeshSetSkinThickness(c.ptr(), C.dFloat(skinThickness))
 }
 
-func (w *World) CreateDeformableBody(deformableMesh *Collision, matrix *[16]float32) *Body {
-	return &Body{C.NewtonCreateDeformableBody(w.handle, deformableMesh.handle,
-		(*C.dFloat)(&matrix[0]))}
+func (w *World) CreateDeformableBody(deformableMesh *Collision, matrix *[16]float32) (*Body, error) {
+	if deformableMesh == nil {
+		return nil, ErrNilCollision
+	}
+	if matrix == nil {
+		identity := [16]float32(Identity())
+		matrix = &identity
+	}
+	handle := C.NewtonCreateDeformableBody(w.ptr(), deformableMesh.ptr(), (*C.dFloat)(&matrix[0]))
+	if handle == nil {
+		return nil, createError("deformable body")
+	}
+	return newBody(handle), nil
 }
 
 func (c *Collision) DeformableMeshUpdateRenderNormals() {
-	C.NewtonDeformableMeshUpdateRenderNormals(c.handle)
+	C.NewtonDeformableMeshUpdateRenderNormals(c.ptr())
 }
 
 func (c *Collision) DeformableMeshVertexCount() int {
-	return int(C.NewtonDeformableMeshGetVertexCount(c.handle))
+	return int(C.NewtonDeformableMeshGetVertexCount(c.ptr()))
 }
 
 func (c *Collision) DeformableMeshVertexStreams(vertexStrideInByte int, vertex []float32,
 	normalStrideInByte int, normal []float32, uvStrideInByte0 int, uv0 []float32,
 	uvStrideInByte1 int, uv1 []float32) {
-	C.NewtonDeformableMeshGetVertexStreams(c.handle, C.int(vertexStrideInByte), (*C.dFloat)(&vertex[0]),
+	C.NewtonDeformableMeshGetVertexStreams(c.ptr(), C.int(vertexStrideInByte), (*C.dFloat)(&vertex[0]),
 		C.int(normalStrideInByte), (*C.dFloat)(&normal[0]), C.int(uvStrideInByte0),
 		(*C.dFloat)(&uv0[0]), C.int(uvStrideInByte1), (*C.dFloat)(&uv1[0]))
 }
 
 func (c *Collision) DeformableMeshFirstSegment() *DeformableMeshSegment {
-	return &DeformableMeshSegment{C.NewtonDeformableMeshGetFirstSegment(c.handle), c.handle}
+	return &DeformableMeshSegment{C.NewtonDeformableMeshGetFirstSegment(c.ptr()), c.ptr()}
 }
 
 func (c *Collision) DeformableMeshNextSegment(curSegment *DeformableMeshSegment) *DeformableMeshSegment {
-	return &DeformableMeshSegment{C.NewtonDeformableMeshGetNextSegment(c.handle, curSegment.handle), c.handle}
+	return &DeformableMeshSegment{C.NewtonDeformableMeshGetNextSegment(c.ptr(), curSegment.handle), c.ptr()}
 }
 
 func (segment *DeformableMeshSegment) MaterialID() int {
@@ -192,26 +262,37 @@ func (segment *DeformableMeshSegment) IndexList() []int {
 
 //Ball and Socket Joint
 
-func (w *World) CreateBall(pivotPoint *[3]float32, child, parent *Body) *Joint {
-	return &Joint{C.NewtonConstraintCreateBall(w.handle, (*C.dFloat)(&pivotPoint[0]),
-		child.handle, parent.handle)}
+//A nil parent attaches the joint to the world
+
+func (w *World) CreateBall(pivotPoint *[3]float32, child, parent *Body) (*Joint, error) {
+	if child == nil {
+		return nil, ErrNilBody
+	}
+	if pivotPoint == nil {
+		return nil, ErrNilPivot
+	}
+	return createdJoint(C.NewtonConstraintCreateBall(w.ptr(), (*C.dFloat)(&pivotPoint[0]),
+		child.ptr(), parentPtr(parent)), newJointSpec(JointBall, child, parent, pivotPoint))
 }
 
 func (j *Joint) BallJointAngle(angle *[3]float32) {
-	C.NewtonBallGetJointAngle(j.handle, (*C.dFloat)(&angle[0]))
+	C.NewtonBallGetJointAngle(j.ptr(), (*C.dFloat)(&angle[0]))
 }
 
 func (j *Joint) BallJointOmega(omega *[3]float32) {
-	C.NewtonBallGetJointOmega(j.handle, (*C.dFloat)(&omega[0]))
+	C.NewtonBallGetJointOmega(j.ptr(), (*C.dFloat)(&omega[0]))
 }
 
 func (j *Joint) BallJointForce(force *[3]float32) {
-	C.NewtonBallGetJointForce(j.handle, (*C.dFloat)(&force[0]))
+	C.NewtonBallGetJointForce(j.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (j *Joint) SetBallConeLimits(pin *[3]float32, maxConeAngle, maxTwistAngle float32) {
-	C.NewtonBallSetConeLimits(j.handle, (*C.dFloat)(&pin[0]), C.dFloat(maxConeAngle),
+	C.NewtonBallSetConeLimits(j.ptr(), (*C.dFloat)(&pin[0]), C.dFloat(maxConeAngle),
 		C.dFloat(maxTwistAngle))
+	if spec, ok := jointSpecs.get(owner(j.handle)); ok {
+		spec.setConeLimits(pin, maxConeAngle, maxTwistAngle)
+	}
 }
 
 //Hinge Joint
@@ -236,130 +317,194 @@ func (d *HingeSliderUpdateDesc) Timestep() float32 {
 	return float32(d.handle.m_timestep)
 }
 
-func (w *World) CreateHinge(pivotPoint, pinDir *[3]float32, child, parent *Body) *Joint {
-	return &Joint{C.NewtonConstraintCreateHinge(w.handle, (*C.dFloat)(&pivotPoint[0]),
-		(*C.dFloat)(&pinDir[0]), child.handle, parent.handle)}
+//SetAcceleration, SetMinFriction and SetMaxFriction are used by joint callbacks, such as
+// to hold a joint at a limit with the value from HingeCalculateStopAlpha
+func (d *HingeSliderUpdateDesc) SetAcceleration(accel float32) {
+	d.handle.m_accel = C.dFloat(accel)
+}
+
+func (d *HingeSliderUpdateDesc) SetMinFriction(friction float32) {
+	d.handle.m_minFriction = C.dFloat(friction)
+}
+
+func (d *HingeSliderUpdateDesc) SetMaxFriction(friction float32) {
+	d.handle.m_maxFriction = C.dFloat(friction)
+}
+
+func (w *World) CreateHinge(pivotPoint, pinDir *[3]float32, child, parent *Body) (*Joint, error) {
+	if child == nil {
+		return nil, ErrNilBody
+	}
+	if pivotPoint == nil {
+		return nil, ErrNilPivot
+	}
+	if err := validPin(pinDir); err != nil {
+		return nil, err
+	}
+	return createdJoint(C.NewtonConstraintCreateHinge(w.ptr(), (*C.dFloat)(&pivotPoint[0]),
+		(*C.dFloat)(&pinDir[0]), child.ptr(), parentPtr(parent)),
+		newJointSpec(JointHinge, child, parent, pivotPoint, pinDir))
 }
 
 func (j *Joint) HingeJointAngle() float32 {
-	return float32(C.NewtonHingeGetJointAngle(j.handle))
+	return float32(C.NewtonHingeGetJointAngle(j.ptr()))
 }
 
 func (j *Joint) HingeJointOmega() float32 {
-	return float32(C.NewtonHingeGetJointOmega(j.handle))
+	return float32(C.NewtonHingeGetJointOmega(j.ptr()))
 }
 
 func (j *Joint) HingeJointForce(force *[3]float32) {
-	C.NewtonHingeGetJointForce(j.handle, (*C.dFloat)(&force[0]))
+	C.NewtonHingeGetJointForce(j.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (j *Joint) HingeCalculateStopAlpha(desc *HingeSliderUpdateDesc, angle float32) float32 {
-	return float32(C.NewtonHingeCalculateStopAlpha(j.handle, desc.handle, C.dFloat(angle)))
+	return float32(C.NewtonHingeCalculateStopAlpha(j.ptr(), desc.handle, C.dFloat(angle)))
 }
 
 //Slider Joint
 
-func (w *World) CreateSlider(pivotPoint, pinDir *[3]float32, child, parent *Body) *Joint {
-	return &Joint{C.NewtonConstraintCreateSlider(w.handle, (*C.dFloat)(&pivotPoint[0]),
-		(*C.dFloat)(&pinDir[0]), child.handle, parent.handle)}
+func (w *World) CreateSlider(pivotPoint, pinDir *[3]float32, child, parent *Body) (*Joint, error) {
+	if child == nil {
+		return nil, ErrNilBody
+	}
+	if pivotPoint == nil {
+		return nil, ErrNilPivot
+	}
+	if err := validPin(pinDir); err != nil {
+		return nil, err
+	}
+	return createdJoint(C.NewtonConstraintCreateSlider(w.ptr(), (*C.dFloat)(&pivotPoint[0]),
+		(*C.dFloat)(&pinDir[0]), child.ptr(), parentPtr(parent)),
+		newJointSpec(JointSlider, child, parent, pivotPoint, pinDir))
 }
 
 func (j *Joint) SliderJointPosit() float32 {
-	return float32(C.NewtonSliderGetJointPosit(j.handle))
+	return float32(C.NewtonSliderGetJointPosit(j.ptr()))
 }
 
 func (j *Joint) SliderJointVeloc() float32 {
-	return float32(C.NewtonSliderGetJointVeloc(j.handle))
+	return float32(C.NewtonSliderGetJointVeloc(j.ptr()))
 }
 
 func (j *Joint) SliderJointForce(force *[3]float32) {
-	C.NewtonSliderGetJointForce(j.handle, (*C.dFloat)(&force[0]))
+	C.NewtonSliderGetJointForce(j.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (j *Joint) SliderCalculateStopAccel(desc *HingeSliderUpdateDesc, position float32) float32 {
-	return float32(C.NewtonSliderCalculateStopAccel(j.handle, desc.handle, C.dFloat(position)))
+	return float32(C.NewtonSliderCalculateStopAccel(j.ptr(), desc.handle, C.dFloat(position)))
 }
 
 //Corkscrew Joint
 
-func (w *World) CreateCorkscrew(pivotPoint, pinDir *[3]float32, child, parent *Body) *Joint {
-	return &Joint{C.NewtonConstraintCreateCorkscrew(w.handle, (*C.dFloat)(&pivotPoint[0]),
-		(*C.dFloat)(&pinDir[0]), child.handle, parent.handle)}
+func (w *World) CreateCorkscrew(pivotPoint, pinDir *[3]float32, child, parent *Body) (*Joint, error) {
+	if child == nil {
+		return nil, ErrNilBody
+	}
+	if pivotPoint == nil {
+		return nil, ErrNilPivot
+	}
+	if err := validPin(pinDir); err != nil {
+		return nil, err
+	}
+	return createdJoint(C.NewtonConstraintCreateCorkscrew(w.ptr(), (*C.dFloat)(&pivotPoint[0]),
+		(*C.dFloat)(&pinDir[0]), child.ptr(), parentPtr(parent)),
+		newJointSpec(JointCorkscrew, child, parent, pivotPoint, pinDir))
 }
 
 func (j *Joint) CorkscrewJointPosit() float32 {
-	return float32(C.NewtonCorkscrewGetJointPosit(j.handle))
+	return float32(C.NewtonCorkscrewGetJointPosit(j.ptr()))
 }
 
 func (j *Joint) CorkscrewJointAngle() float32 {
-	return float32(C.NewtonCorkscrewGetJointAngle(j.handle))
+	return float32(C.NewtonCorkscrewGetJointAngle(j.ptr()))
 }
 
 func (j *Joint) CorkscrewJointVeloc() float32 {
-	return float32(C.NewtonCorkscrewGetJointVeloc(j.handle))
+	return float32(C.NewtonCorkscrewGetJointVeloc(j.ptr()))
 }
 
 func (j *Joint) CorkscrewJointOmega() float32 {
-	return float32(C.NewtonCorkscrewGetJointOmega(j.handle))
+	return float32(C.NewtonCorkscrewGetJointOmega(j.ptr()))
 }
 
 func (j *Joint) CorkscrewJointForce(force *[3]float32) {
-	C.NewtonCorkscrewGetJointForce(j.handle, (*C.dFloat)(&force[0]))
+	C.NewtonCorkscrewGetJointForce(j.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (j *Joint) CorkscrewCalculateStopAccel(desc *HingeSliderUpdateDesc, position float32) float32 {
-	return float32(C.NewtonCorkscrewCalculateStopAccel(j.handle, desc.handle, C.dFloat(position)))
+	return float32(C.NewtonCorkscrewCalculateStopAccel(j.ptr(), desc.handle, C.dFloat(position)))
 }
 
 func (j *Joint) CorkscrewCalculateStopAlpha(desc *HingeSliderUpdateDesc, angle float32) float32 {
-	return float32(C.NewtonCorkscrewCalculateStopAlpha(j.handle, desc.handle, C.dFloat(angle)))
+	return float32(C.NewtonCorkscrewCalculateStopAlpha(j.ptr(), desc.handle, C.dFloat(angle)))
 }
 
 //Univeral Joint
 
-func (w *World) CreateUniversal(pivotPoint, pinDir0, pinDir1 *[3]float32, child, parent *Body) *Joint {
-	return &Joint{C.NewtonConstraintCreateUniversal(w.handle, (*C.dFloat)(&pivotPoint[0]),
-		(*C.dFloat)(&pinDir0[0]), (*C.dFloat)(&pinDir1[0]), child.handle, parent.handle)}
+func (w *World) CreateUniversal(pivotPoint, pinDir0, pinDir1 *[3]float32, child,
+	parent *Body) (*Joint, error) {
+	if child == nil {
+		return nil, ErrNilBody
+	}
+	if pivotPoint == nil {
+		return nil, ErrNilPivot
+	}
+	if err := validPin(pinDir0); err != nil {
+		return nil, err
+	}
+	if err := validPin(pinDir1); err != nil {
+		return nil, err
+	}
+	return createdJoint(C.NewtonConstraintCreateUniversal(w.ptr(), (*C.dFloat)(&pivotPoint[0]),
+		(*C.dFloat)(&pinDir0[0]), (*C.dFloat)(&pinDir1[0]), child.ptr(), parentPtr(parent)),
+		newJointSpec(JointUniversal, child, parent, pivotPoint, pinDir0, pinDir1))
 }
 
 func (j *Joint) UniversalJointAngle0() float32 {
-	return float32(C.NewtonUniversalGetJointAngle0(j.handle))
+	return float32(C.NewtonUniversalGetJointAngle0(j.ptr()))
 }
 
 func (j *Joint) UniversalJointAngle1() float32 {
-	return float32(C.NewtonUniversalGetJointAngle1(j.handle))
+	return float32(C.NewtonUniversalGetJointAngle1(j.ptr()))
 }
 
 func (j *Joint) UniversalJointOmega0() float32 {
-	return float32(C.NewtonUniversalGetJointOmega0(j.handle))
+	return float32(C.NewtonUniversalGetJointOmega0(j.ptr()))
 }
 
 func (j *Joint) UniversalJointOmega1() float32 {
-	return float32(C.NewtonUniversalGetJointOmega1(j.handle))
+	return float32(C.NewtonUniversalGetJointOmega1(j.ptr()))
 }
 
 func (j *Joint) UniversalJointForce(force *[3]float32) {
-	C.NewtonUniversalGetJointForce(j.handle, (*C.dFloat)(&force[0]))
+	C.NewtonUniversalGetJointForce(j.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (j *Joint) UniversalCalculateStopAlpha0(desc *HingeSliderUpdateDesc, angle float32) float32 {
-	return float32(C.NewtonUniversalCalculateStopAlpha0(j.handle, desc.handle, C.dFloat(angle)))
+	return float32(C.NewtonUniversalCalculateStopAlpha0(j.ptr(), desc.handle, C.dFloat(angle)))
 }
 
 func (j *Joint) UniversalCalculateStopAlpha1(desc *HingeSliderUpdateDesc, angle float32) float32 {
-	return float32(C.NewtonUniversalCalculateStopAlpha1(j.handle, desc.handle, C.dFloat(angle)))
+	return float32(C.NewtonUniversalCalculateStopAlpha1(j.ptr(), desc.handle, C.dFloat(angle)))
 }
 
 //Up Vector Joint
-func (w *World) CreateUpVector(pinDir *[3]float32, body *Body) *Joint {
-	return &Joint{C.NewtonConstraintCreateUpVector(w.handle, (*C.dFloat)(&pinDir[0]),
-		body.handle)}
+func (w *World) CreateUpVector(pinDir *[3]float32, body *Body) (*Joint, error) {
+	if body == nil {
+		return nil, ErrNilBody
+	}
+	if err := validPin(pinDir); err != nil {
+		return nil, err
+	}
+	return createdJoint(C.NewtonConstraintCreateUpVector(w.ptr(), (*C.dFloat)(&pinDir[0]),
+		body.ptr()), newJointSpec(JointUpVector, body, nil, nil, pinDir))
 }
 
 func (j *Joint) UpVectorPin(pinDir *[3]float32) {
-	C.NewtonUpVectorGetPin(j.handle, (*C.dFloat)(&pinDir[0]))
+	C.NewtonUpVectorGetPin(j.ptr(), (*C.dFloat)(&pinDir[0]))
 }
 
 func (j *Joint) UpVectorSetPin(pinDir *[3]float32) {
-	C.NewtonUpVectorSetPin(j.handle, (*C.dFloat)(&pinDir[0]))
+	C.NewtonUpVectorSetPin(j.ptr(), (*C.dFloat)(&pinDir[0]))
 }
@@ -0,0 +1,271 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+/*
+#include "Newton.h"
+#include "callback.h"
+*/
+import "C"
+import (
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+//Lifetime tracking
+// Bodies and joints are owned by their world, so the package keeps a single
+// wrapper for each one that's alive.  Newton's destructor callbacks clear the
+// wrapper's handle, so a *Body or *Joint left over from DestroyBody,
+// DestroyAllBodies, World.Destroy, etc panics with a clear message instead of
+// crashing inside Newton.
+//
+// Meshes and collisions are owned by the caller, and are tracked by the world
+// that created them.  Anything not destroyed by the time the world is destroyed
+// is released with it, and can optionally be released as soon as it's
+// unreachable (see SetAutoRelease).
+
+var (
+	bodies = newHandlers[*Body]()
+	joints = newHandlers[*Joint]()
+)
+
+//newBody returns the tracked wrapper for the handle, or nil if there isn't one
+func newBody(handle *C.NewtonBody) *Body {
+	if handle == nil {
+		return nil
+	}
+
+	bodies.Lock()
+	defer bodies.Unlock()
+	if b, ok := bodies.m[owner(handle)]; ok {
+		return b
+	}
+	b := &Body{handle}
+	bodies.m[owner(handle)] = b
+	C.SetBodyDestructor(handle)
+	return b
+}
+
+//destroyedBody returns the body's wrapper and stops tracking it, its handle
+// is cleared once the destructor callbacks have run
+func destroyedBody(handle *C.NewtonBody) *Body {
+	b, ok := bodies.get(owner(handle))
+	if !ok {
+		return &Body{handle}
+	}
+	bodies.remove(owner(handle))
+	return b
+}
+
+//newJoint returns the tracked wrapper for the handle, or nil if there isn't one
+func newJoint(handle *C.NewtonJoint) *Joint {
+	if handle == nil {
+		return nil
+	}
+
+	joints.Lock()
+	defer joints.Unlock()
+	if j, ok := joints.m[owner(handle)]; ok {
+		return j
+	}
+	j := &Joint{handle}
+	joints.m[owner(handle)] = j
+	C.SetConstraintDestructor(handle)
+	return j
+}
+
+//destroyedJoint returns the joint's wrapper and stops tracking it
+func destroyedJoint(handle *C.NewtonJoint) *Joint {
+	j, ok := joints.get(owner(handle))
+	if !ok {
+		return &Joint{handle}
+	}
+	joints.remove(owner(handle))
+	return j
+}
+
+//newContactJoint wraps a contact joint, or returns nil if there isn't one.
+// Contact joints are created and destroyed by Newton every update, so they
+// aren't tracked and are only valid until the next update
+func newContactJoint(handle *C.NewtonJoint) *Joint {
+	if handle == nil {
+		return nil
+	}
+	return &Joint{handle}
+}
+
+//Leak is a mesh or collision that was never destroyed before its world was
+type Leak struct {
+	Kind  string //Collision or Mesh
+	Stack string //where it was created
+}
+
+//resource is a mesh or collision created by a world
+type resource struct {
+	world    owner
+	kind     string
+	release  func()
+	stack    []byte
+	cleanup  runtime.Cleanup
+	released atomic.Bool
+}
+
+//valid returns false if the resource has been released, untracked resources
+// are always valid
+func (r *resource) valid() bool {
+	return r == nil || !r.released.Load()
+}
+
+//trackedWorld returns the world that owns the resource, or nil if it's untracked.
+// Meshes and collisions made from a tracked one belong to the same world
+func (r *resource) trackedWorld() owner {
+	if r == nil {
+		return nil
+	}
+	return r.world
+}
+
+//track registers a mesh or collision against the world, release frees it in Newton.
+// Returns nil if the world isn't known
+func track(world owner, kind string, release func()) *resource {
+	if world == nil {
+		return nil
+	}
+	cb, ok := worlds.get(world)
+	if !ok {
+		return nil
+	}
+
+	r := &resource{world: world, kind: kind, release: release}
+
+	cb.Lock()
+	defer cb.Unlock()
+	if cb.leakReport != nil {
+		r.stack = debug.Stack()
+	}
+	cb.resources[r] = struct{}{}
+	return r
+}
+
+//autoRelease attaches a cleanup to the wrapper if its world releases unreachable
+// meshes and collisions
+func autoRelease[T any](wrapper *T, r *resource) {
+	if r == nil {
+		return
+	}
+	cb, ok := worlds.get(r.world)
+	if !ok {
+		return
+	}
+	cb.RLock()
+	enabled := cb.autoRelease
+	cb.RUnlock()
+	if enabled {
+		r.cleanup = runtime.AddCleanup(wrapper, (*resource).queue, r)
+	}
+}
+
+//queue is run by the garbage collector once the resource's wrapper is unreachable.
+// Newton isn't safe to call from the cleanup goroutine, so the resource is released
+// on the world's next Update instead
+func (r *resource) queue() {
+	cb, ok := worlds.get(r.world)
+	if !ok {
+		return
+	}
+	cb.Lock()
+	cb.pending = append(cb.pending, r)
+	cb.Unlock()
+}
+
+//destroyed is called once the caller has destroyed the resource
+func (r *resource) destroyed() {
+	if r == nil {
+		return
+	}
+	r.cleanup.Stop()
+	r.released.Store(true)
+
+	if cb, ok := worlds.get(r.world); ok {
+		cb.Lock()
+		delete(cb.resources, r)
+		cb.Unlock()
+	}
+}
+
+//SetAutoRelease sets whether meshes and collisions created by the world after the
+// call are released once they are no longer reachable, instead of leaking until
+// the world is destroyed.  Collisions used by a body are kept by Newton for as
+// long as the body needs them, so releasing the Go side is always safe
+func (w *World) SetAutoRelease(enabled bool) {
+	cb := w.callbacks()
+	cb.Lock()
+	cb.autoRelease = enabled
+	cb.Unlock()
+}
+
+//SetLeakReport turns on the world's debug mode.  Where every mesh and collision is
+// created is recorded, and when the world is destroyed report is called with the
+// ones that were never destroyed or collected.  A nil report turns debug mode off
+func (w *World) SetLeakReport(report func(leaks []Leak)) {
+	cb := w.callbacks()
+	cb.Lock()
+	cb.leakReport = report
+	cb.Unlock()
+}
+
+//releasePending releases the meshes and collisions that were collected since the
+// last call
+func (w *World) releasePending() {
+	cb, ok := worlds.get(owner(w.handle))
+	if !ok {
+		return
+	}
+
+	cb.Lock()
+	pending := cb.pending
+	cb.pending = nil
+	for _, r := range pending {
+		delete(cb.resources, r)
+	}
+	cb.Unlock()
+
+	for _, r := range pending {
+		if !r.released.Swap(true) {
+			r.release()
+		}
+	}
+}
+
+//releaseResources releases every mesh and collision the world still tracks, and
+// reports them as leaks if the world is in debug mode
+func (w *World) releaseResources() {
+	w.releasePending()
+
+	cb, ok := worlds.get(owner(w.handle))
+	if !ok {
+		return
+	}
+
+	cb.Lock()
+	report := cb.leakReport
+	remaining := cb.resources
+	cb.resources = make(map[*resource]struct{})
+	cb.Unlock()
+
+	var leaks []Leak
+	for r := range remaining {
+		r.cleanup.Stop()
+		if r.released.Swap(true) {
+			continue
+		}
+		r.release()
+		leaks = append(leaks, Leak{Kind: r.kind, Stack: string(r.stack)})
+	}
+
+	if report != nil && len(leaks) > 0 {
+		report(leaks)
+	}
+}
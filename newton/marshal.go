@@ -0,0 +1,175 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+/*
+#include "Newton.h"
+*/
+import "C"
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+var (
+	ErrCollisionFormat  = errors.New("newton: invalid collision data")
+	ErrCollisionVersion = errors.New("newton: collision data is from a different version")
+	ErrNoWorld          = errors.New("newton: collision doesn't belong to a known world")
+)
+
+//collision encoding
+// magic, format version, the Newton version that wrote it, the length and CRC32 of
+// the payload, and then the payload, which is Newton's own serialization of the
+// collision.  Newton's format changes between versions, so data written by any other
+// version is rejected, and the payload is checked before Newton is given any of it
+var collisionMagic = [4]byte{'N', 'C', 'O', 'L'}
+
+const collisionVersion = 1
+
+type collisionHeader struct {
+	Magic         [4]byte
+	Version       uint8
+	NewtonVersion int32
+	Length        uint32
+	CRC           uint32
+}
+
+//countingWriter keeps the number of bytes written and the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+//WriteTo writes the collision to out in the same form as MarshalBinary
+func (c *Collision) WriteTo(out io.Writer) (int64, error) {
+	if c.world == nil {
+		return 0, ErrNoWorld
+	}
+	w := &World{(*C.NewtonWorld)(c.world)}
+
+	payload := &bytes.Buffer{}
+	w.SerializeCollision(c, func(serializeHandle interface{}, buffer []byte) {
+		payload.Write(buffer)
+	}, nil)
+
+	return writeCollision(out, Version(), payload.Bytes())
+}
+
+//writeCollision writes the header for payload, and then payload
+func writeCollision(out io.Writer, newtonVersion int, payload []byte) (int64, error) {
+	cw := &countingWriter{w: out}
+	header := collisionHeader{
+		Magic:         collisionMagic,
+		Version:       collisionVersion,
+		NewtonVersion: int32(newtonVersion),
+		Length:        uint32(len(payload)),
+		CRC:           crc32.ChecksumIEEE(payload),
+	}
+	if err := binary.Write(cw, binary.LittleEndian, &header); err != nil {
+		return cw.n, err
+	}
+	cw.Write(payload)
+	return cw.n, cw.err
+}
+
+//MarshalBinary returns the cooked collision, so it can be cached and loaded again
+// with World.UnmarshalCollision instead of being built from scratch
+func (c *Collision) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if _, err := c.WriteTo(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+//ReadCollision reads a collision written by Collision.WriteTo or MarshalBinary from r.
+// Only as much as the collision needs is read, so more data can follow it
+func (w *World) ReadCollision(r io.Reader) (*Collision, error) {
+	payload, err := readCollision(r, Version())
+	if err != nil {
+		return nil, err
+	}
+
+	pr := bytes.NewReader(payload)
+	var readErr error
+	collision, err := w.CreateCollisionFromSerialization(func(serializeHandle interface{}, buffer []byte) {
+		if readErr != nil {
+			return
+		}
+		if _, err := io.ReadFull(pr, buffer); err != nil {
+			readErr = err
+			clear(buffer)
+		}
+	}, nil)
+	if readErr != nil {
+		if collision != nil {
+			collision.Destroy()
+		}
+		return nil, fmt.Errorf("%w: %v", ErrCollisionFormat, readErr)
+	}
+	if err == nil && pr.Len() != 0 {
+		collision.Destroy()
+		return nil, fmt.Errorf("%w: %d bytes of the payload weren't used", ErrCollisionFormat, pr.Len())
+	}
+	return collision, err
+}
+
+//readCollision reads a header and its payload from r, and checks the payload is
+// all there and was written by newtonVersion
+func readCollision(r io.Reader, newtonVersion int) ([]byte, error) {
+	var header collisionHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, ErrCollisionFormat
+	}
+	if header.Magic != collisionMagic {
+		return nil, ErrCollisionFormat
+	}
+	if header.Version != collisionVersion || int(header.NewtonVersion) != newtonVersion {
+		return nil, ErrCollisionVersion
+	}
+
+	//read through a LimitReader rather than allocating Length up front, so a corrupt
+	// length can't allocate more than is actually there
+	payload, err := io.ReadAll(io.LimitReader(r, int64(header.Length)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCollisionFormat, err)
+	}
+	if len(payload) != int(header.Length) {
+		return nil, fmt.Errorf("%w: payload is %d bytes, expected %d", ErrCollisionFormat,
+			len(payload), header.Length)
+	}
+	if crc32.ChecksumIEEE(payload) != header.CRC {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCollisionFormat)
+	}
+	return payload, nil
+}
+
+//UnmarshalCollision creates a collision from data returned by Collision.MarshalBinary
+func (w *World) UnmarshalCollision(data []byte) (*Collision, error) {
+	r := bytes.NewReader(data)
+	collision, err := w.ReadCollision(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		collision.Destroy()
+		return nil, ErrCollisionFormat
+	}
+	return collision, nil
+}
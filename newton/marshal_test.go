@@ -0,0 +1,95 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMarshalCollision(t *testing.T) {
+	w, err := CreateWorld()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	box, err := w.CreateBox(1, 2, 3, 7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer box.Destroy()
+
+	data, err := box.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := w.UnmarshalCollision(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Destroy()
+
+	if got.Type() != box.Type() {
+		t.Errorf("type is %d, want %d", got.Type(), box.Type())
+	}
+	if got.UserID() != box.UserID() {
+		t.Errorf("user id is %d, want %d", got.UserID(), box.UserID())
+	}
+	if got.CalculateVolume() != box.CalculateVolume() {
+		t.Errorf("volume is %v, want %v", got.CalculateVolume(), box.CalculateVolume())
+	}
+
+	if _, err := w.UnmarshalCollision(append(data, 0)); !errors.Is(err, ErrCollisionFormat) {
+		t.Errorf("trailing data: got error %v, want ErrCollisionFormat", err)
+	}
+}
+
+func TestReadCollisionHeader(t *testing.T) {
+	const newtonVersion = 314
+	payload := []byte("collision payload")
+
+	encoded := func(change func(data []byte) []byte) []byte {
+		buf := &bytes.Buffer{}
+		if _, err := writeCollision(buf, newtonVersion, payload); err != nil {
+			t.Fatal(err)
+		}
+		return change(buf.Bytes())
+	}
+
+	//header offsets: magic 0, version 4, newton version 5, length 9, crc 13, payload 17
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"bad magic", encoded(func(data []byte) []byte { data[0] = 'X'; return data }), ErrCollisionFormat},
+		{"wrong version", encoded(func(data []byte) []byte { data[4]++; return data }), ErrCollisionVersion},
+		{"wrong newton version", encoded(func(data []byte) []byte { data[5]++; return data }), ErrCollisionVersion},
+		{"truncated length", encoded(func(data []byte) []byte { data[9]++; return data }), ErrCollisionFormat},
+		{"truncated payload", encoded(func(data []byte) []byte { return data[:len(data)-1] }), ErrCollisionFormat},
+		{"truncated header", encoded(func(data []byte) []byte { return data[:10] }), ErrCollisionFormat},
+		{"flipped crc byte", encoded(func(data []byte) []byte { data[13] ^= 0xff; return data }), ErrCollisionFormat},
+		{"flipped payload byte", encoded(func(data []byte) []byte { data[17] ^= 0xff; return data }), ErrCollisionFormat},
+	}
+
+	for _, test := range tests {
+		if _, err := readCollision(bytes.NewReader(test.data), newtonVersion); !errors.Is(err, test.err) {
+			t.Errorf("%s: got error %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	r := bytes.NewReader(append(encoded(func(data []byte) []byte { return data }), "more"...))
+	got, err := readCollision(r, newtonVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("payload is %q, want %q", got, payload)
+	}
+	if r.Len() != len("more") {
+		t.Errorf("%d bytes left after the collision, want %d", r.Len(), len("more"))
+	}
+}
@@ -9,54 +9,218 @@ package newton
 #include <stdlib.h>
 */
 import "C"
+import "iter"
 
 type Material struct {
 	handle *C.NewtonMaterial
 }
 
 func (w *World) CreateMaterialGroupID() int {
-	return int(C.NewtonMaterialCreateGroupID(w.handle))
+	id := int(C.NewtonMaterialCreateGroupID(w.ptr()))
+
+	cb := w.callbacks()
+	cb.Lock()
+	cb.materialGroups++
+	first := w.DefaultMaterialGroupID()
+	for other := first; other <= id; other++ {
+		w.installPairCallback(cb, other, id)
+	}
+	cb.Unlock()
+	return id
 }
 
 func (w *World) DefaultMaterialGroupID() int {
-	return int(C.NewtonMaterialGetDefaultGroupID(w.handle))
+	return int(C.NewtonMaterialGetDefaultGroupID(w.ptr()))
 }
 
 func (w *World) DestroyAllMaterialGroupID() {
-	C.NewtonMaterialDestroyAllGroupID(w.handle)
+	C.NewtonMaterialDestroyAllGroupID(w.ptr())
+
+	cb := w.callbacks()
+	cb.Lock()
+	cb.materialGroups = 0
+	clear(cb.materialDefaults)
+	clear(cb.materialData)
+	clear(cb.materialNames)
+	//Newton has dropped every pair's callbacks along with the groups
+	releaseMaterialCallbacks(cb)
+	w.installPairCallback(cb, w.DefaultMaterialGroupID(), w.DefaultMaterialGroupID())
+	cb.Unlock()
+}
+
+//materialDefaults records the defaults set for a material pair, since Newton
+// can't report them back
+type materialDefaults struct {
+	set             uint8
+	thickness       float32
+	softness        float32
+	elasticity      float32
+	collidable      int
+	staticFriction  float32
+	kineticFriction float32
+}
+
+//bits of materialDefaults.set
+const (
+	materialThickness = 1 << iota
+	materialSoftness
+	materialElasticity
+	materialCollidable
+	materialFriction
+)
+
+//recordMaterial updates the defaults recorded for the material pair
+func (w *World) recordMaterial(matid0, matid1 int, record func(d *materialDefaults)) {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+
+	pair := newMaterialPair(matid0, matid1)
+	d, ok := cb.materialDefaults[pair]
+	if !ok {
+		d = &materialDefaults{}
+		cb.materialDefaults[pair] = d
+	}
+	record(d)
+}
+
+//materialDefault returns a copy of the defaults recorded for the material pair
+func (w *World) materialDefault(matid0, matid1 int) materialDefaults {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+
+	if d, ok := cb.materialDefaults[newMaterialPair(matid0, matid1)]; ok {
+		return *d
+	}
+	return materialDefaults{}
 }
 
+//MaterialDefaultElasticity returns the elasticity set for the material pair, false
+// if it hasn't been set
+func (w *World) MaterialDefaultElasticity(matid0, matid1 int) (float32, bool) {
+	d := w.materialDefault(matid0, matid1)
+	return d.elasticity, d.set&materialElasticity != 0
+}
+
+//MaterialDefaultFriction returns the friction set for the material pair, false
+// if it hasn't been set
+func (w *World) MaterialDefaultFriction(matid0, matid1 int) (static, kinetic float32, ok bool) {
+	d := w.materialDefault(matid0, matid1)
+	return d.staticFriction, d.kineticFriction, d.set&materialFriction != 0
+}
+
+//MaterialUserData returns the data set for the material pair with SetMaterialUserData
+// or SetMaterialCollisionCallback.  Newton's own user data for the pair holds the
+// pair's collision handlers, so the data is kept on the Go side
 func (w *World) MaterialUserData(matid0, matid1 int) interface{} {
-	return (interface{})(C.NewtonMaterialGetUserData(w.handle, C.int(matid0), C.int(matid1)))
+	cb := w.callbacks()
+	cb.RLock()
+	defer cb.RUnlock()
+	return cb.materialData[newMaterialPair(matid0, matid1)]
+}
+
+func (w *World) SetMaterialUserData(matid0, matid1 int, userData interface{}) {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	if userData == nil {
+		delete(cb.materialData, newMaterialPair(matid0, matid1))
+		return
+	}
+	cb.materialData[newMaterialPair(matid0, matid1)] = userData
 }
 
 func (w *World) SetMaterialSurfaceThickness(matid0, matid1 int, thickness float32) {
-	C.NewtonMaterialSetSurfaceThickness(w.handle, C.int(matid0), C.int(matid1), C.dFloat(thickness))
+	C.NewtonMaterialSetSurfaceThickness(w.ptr(), C.int(matid0), C.int(matid1), C.dFloat(thickness))
+	w.recordMaterial(matid0, matid1, func(d *materialDefaults) {
+		d.set |= materialThickness
+		d.thickness = thickness
+	})
 }
 
 func (w *World) SetMaterialDefaultSoftness(matid0, matid1 int, value float32) {
-	C.NewtonMaterialSetDefaultSoftness(w.handle, C.int(matid0), C.int(matid1), C.dFloat(value))
+	C.NewtonMaterialSetDefaultSoftness(w.ptr(), C.int(matid0), C.int(matid1), C.dFloat(value))
+	w.recordMaterial(matid0, matid1, func(d *materialDefaults) {
+		d.set |= materialSoftness
+		d.softness = value
+	})
 }
 
 func (w *World) SetMaterialDefaultElasticity(matid0, matid1 int, elasticCoef float32) {
-	C.NewtonMaterialSetDefaultElasticity(w.handle, C.int(matid0), C.int(matid1), C.dFloat(elasticCoef))
+	C.NewtonMaterialSetDefaultElasticity(w.ptr(), C.int(matid0), C.int(matid1), C.dFloat(elasticCoef))
+	w.recordMaterial(matid0, matid1, func(d *materialDefaults) {
+		d.set |= materialElasticity
+		d.elasticity = elasticCoef
+	})
 }
 
 func (w *World) SetMaterialDefaultCollidable(matid0, matid1, state int) {
-	C.NewtonMaterialSetDefaultCollidable(w.handle, C.int(matid0), C.int(matid1), C.int(state))
+	C.NewtonMaterialSetDefaultCollidable(w.ptr(), C.int(matid0), C.int(matid1), C.int(state))
+	w.recordMaterial(matid0, matid1, func(d *materialDefaults) {
+		d.set |= materialCollidable
+		d.collidable = state
+	})
 }
 
 func (w *World) SetMaterialDefaultFriction(matid0, matid1 int, static, kinetic float32) {
-	C.NewtonMaterialSetDefaultFriction(w.handle, C.int(matid0), C.int(matid1), C.dFloat(static),
+	C.NewtonMaterialSetDefaultFriction(w.ptr(), C.int(matid0), C.int(matid1), C.dFloat(static),
 		C.dFloat(kinetic))
+	w.recordMaterial(matid0, matid1, func(d *materialDefaults) {
+		d.set |= materialFriction
+		d.staticFriction = static
+		d.kineticFriction = kinetic
+	})
 }
 
+//FirstMaterial and NextMaterial return nil when there are no more materials
 func (w *World) FirstMaterial() *Material {
-	return &Material{C.NewtonWorldGetFirstMaterial(w.handle)}
+	handle := C.NewtonWorldGetFirstMaterial(w.ptr())
+	if handle == nil {
+		return nil
+	}
+	return &Material{handle}
 }
 
 func (w *World) NextMaterial(material *Material) *Material {
-	return &Material{C.NewtonWorldGetNextMaterial(w.handle, material.handle)}
+	handle := C.NewtonWorldGetNextMaterial(w.ptr(), material.handle)
+	if handle == nil {
+		return nil
+	}
+	return &Material{handle}
+}
+
+//Materials iterates over every material pair in the world
+func (w *World) Materials() iter.Seq[*Material] {
+	return func(yield func(*Material) bool) {
+		for material := w.FirstMaterial(); material != nil; material = w.NextMaterial(material) {
+			if !yield(material) {
+				return
+			}
+		}
+	}
+}
+
+//GroupIDs returns the material groups of the pair being collided, lowest first.  It's
+// known for pairs with handlers set by SetMaterialCollisionCallback, and for every pair
+// once collision filters, surface velocities or one-way platforms are in use.  ok is
+// false for the rest
+func (m *Material) GroupIDs() (matid0, matid1 int, ok bool) {
+	callback := materialCallbackFromUserData(C.NewtonMaterialGetMaterialPairUserData(m.handle))
+	if callback == nil {
+		return 0, 0, false
+	}
+	return callback.pair.matid0, callback.pair.matid1, true
+}
+
+//UserData returns the MaterialUserData of the pair being collided.  Like GroupIDs it's
+// nil for pairs Newton is colliding without any Go callbacks installed
+func (m *Material) UserData() interface{} {
+	callback := materialCallbackFromUserData(C.NewtonMaterialGetMaterialPairUserData(m.handle))
+	if callback == nil {
+		return nil
+	}
+	return callback.world.MaterialUserData(callback.pair.matid0, callback.pair.matid1)
 }
 
 func (m *Material) ContactFaceAttribute() uint {
@@ -64,8 +228,8 @@ func (m *Material) ContactFaceAttribute() uint {
 }
 
 func (m *Material) BodyCollidingShape(body *Body) *Collision {
-	collision := &Collision{handle: C.NewtonMaterialGetBodyCollidingShape(m.handle, body.handle)}
-	return collision
+	return newCollision(owner(C.NewtonBodyGetWorld(body.ptr())),
+		C.NewtonMaterialGetBodyCollidingShape(m.handle, body.ptr()))
 }
 
 func (m *Material) ContactNormalSpeed() float32 {
@@ -73,16 +237,16 @@ func (m *Material) ContactNormalSpeed() float32 {
 }
 
 func (m *Material) ContactForce(body *Body, force *[3]float32) {
-	C.NewtonMaterialGetContactForce(m.handle, body.handle, (*C.dFloat)(&force[0]))
+	C.NewtonMaterialGetContactForce(m.handle, body.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (m *Material) ContactPositionAndNormal(body *Body, position, normal *[3]float32) {
-	C.NewtonMaterialGetContactPositionAndNormal(m.handle, body.handle, (*C.dFloat)(&position[0]),
+	C.NewtonMaterialGetContactPositionAndNormal(m.handle, body.ptr(), (*C.dFloat)(&position[0]),
 		(*C.dFloat)(&normal[0]))
 }
 
 func (m *Material) ContactTangentDirections(body *Body, dir0, dir1 *[3]float32) {
-	C.NewtonMaterialGetContactTangentDirections(m.handle, body.handle, (*C.dFloat)(&dir0[0]),
+	C.NewtonMaterialGetContactTangentDirections(m.handle, body.ptr(), (*C.dFloat)(&dir0[0]),
 		(*C.dFloat)(&dir1[0]))
 }
 
@@ -0,0 +1,227 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrUnknownMaterial = errors.New("newton: unknown material")
+
+//DefaultMaterialName is the name of the world's default material group in a
+// MaterialTable
+const DefaultMaterialName = "default"
+
+//MaterialTable is a set of named material groups and the defaults for pairs of them.
+// It's read and written as JSON with ReadMaterialTable and WriteJSON, or as YAML with
+// ReadMaterialTableYAML and WriteYAML
+type MaterialTable struct {
+	Materials []string            `json:"materials" yaml:"materials"`
+	Pairs     []MaterialPairTable `json:"pairs,omitempty" yaml:"pairs,omitempty"`
+}
+
+//MaterialPairTable is the defaults for a pair of named materials, only the values
+// that are set are applied
+type MaterialPairTable struct {
+	Materials       [2]string `json:"materials" yaml:"materials"`
+	StaticFriction  *float32  `json:"staticFriction,omitempty" yaml:"staticFriction,omitempty"`
+	KineticFriction *float32  `json:"kineticFriction,omitempty" yaml:"kineticFriction,omitempty"`
+	Elasticity      *float32  `json:"elasticity,omitempty" yaml:"elasticity,omitempty"`
+	Softness        *float32  `json:"softness,omitempty" yaml:"softness,omitempty"`
+	Thickness       *float32  `json:"thickness,omitempty" yaml:"thickness,omitempty"`
+	Collidable      *bool     `json:"collidable,omitempty" yaml:"collidable,omitempty"`
+}
+
+//ReadMaterialTable reads a material table from JSON
+func ReadMaterialTable(r io.Reader) (*MaterialTable, error) {
+	t := &MaterialTable{}
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+//ReadMaterialTableYAML reads a material table from YAML
+func ReadMaterialTableYAML(r io.Reader) (*MaterialTable, error) {
+	t := &MaterialTable{}
+	if err := yaml.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+//WriteYAML writes the table as YAML
+func (t *MaterialTable) WriteYAML(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(t); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+//WriteJSON writes the table as indented JSON
+func (t *MaterialTable) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(t)
+}
+
+//Validate checks that every pair is between materials in the table, and that both
+// frictions are set together
+func (t *MaterialTable) Validate() error {
+	names := map[string]bool{DefaultMaterialName: true}
+	for _, name := range t.Materials {
+		names[name] = true
+	}
+	for _, pair := range t.Pairs {
+		for _, name := range pair.Materials {
+			if !names[name] {
+				return fmt.Errorf("%w: %q", ErrUnknownMaterial, name)
+			}
+		}
+		if (pair.StaticFriction == nil) != (pair.KineticFriction == nil) {
+			return fmt.Errorf("newton: material pair %q, %q needs both static and kinetic friction",
+				pair.Materials[0], pair.Materials[1])
+		}
+	}
+	return nil
+}
+
+//MaterialID returns the id of the named material group, false if there isn't one.
+// The default group is always named DefaultMaterialName
+func (w *World) MaterialID(name string) (int, bool) {
+	if name == DefaultMaterialName {
+		return w.DefaultMaterialGroupID(), true
+	}
+	cb := w.callbacks()
+	cb.RLock()
+	defer cb.RUnlock()
+	id, ok := cb.materialNames[name]
+	return id, ok
+}
+
+//MaterialName returns the name of the material group, or "" if it hasn't got one
+func (w *World) MaterialName(id int) string {
+	if id == w.DefaultMaterialGroupID() {
+		return DefaultMaterialName
+	}
+	cb := w.callbacks()
+	cb.RLock()
+	defer cb.RUnlock()
+	for name, named := range cb.materialNames {
+		if named == id {
+			return name
+		}
+	}
+	return ""
+}
+
+//CreateNamedMaterialGroupID returns the id of the named material group, creating it
+// if it doesn't exist yet
+func (w *World) CreateNamedMaterialGroupID(name string) int {
+	if id, ok := w.MaterialID(name); ok {
+		return id
+	}
+	id := w.CreateMaterialGroupID()
+	cb := w.callbacks()
+	cb.Lock()
+	cb.materialNames[name] = id
+	cb.Unlock()
+	return id
+}
+
+//ApplyMaterialTable creates a material group for each material in the table that the
+// world doesn't have yet, and sets the defaults for each pair
+func (w *World) ApplyMaterialTable(t *MaterialTable) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	for _, name := range t.Materials {
+		w.CreateNamedMaterialGroupID(name)
+	}
+
+	for _, pair := range t.Pairs {
+		id0, _ := w.MaterialID(pair.Materials[0])
+		id1, _ := w.MaterialID(pair.Materials[1])
+		if pair.StaticFriction != nil {
+			w.SetMaterialDefaultFriction(id0, id1, *pair.StaticFriction, *pair.KineticFriction)
+		}
+		if pair.Elasticity != nil {
+			w.SetMaterialDefaultElasticity(id0, id1, *pair.Elasticity)
+		}
+		if pair.Softness != nil {
+			w.SetMaterialDefaultSoftness(id0, id1, *pair.Softness)
+		}
+		if pair.Thickness != nil {
+			w.SetMaterialSurfaceThickness(id0, id1, *pair.Thickness)
+		}
+		if pair.Collidable != nil {
+			w.SetMaterialDefaultCollidable(id0, id1, int(cint[*pair.Collidable]))
+		}
+	}
+	return nil
+}
+
+//MaterialTable returns the world's named materials, and the defaults that have been
+// set for pairs of them
+func (w *World) MaterialTable() *MaterialTable {
+	cb := w.callbacks()
+	cb.RLock()
+	defer cb.RUnlock()
+
+	t := &MaterialTable{}
+	names := map[int]string{w.DefaultMaterialGroupID(): DefaultMaterialName}
+	for name, id := range cb.materialNames {
+		t.Materials = append(t.Materials, name)
+		names[id] = name
+	}
+	sort.Strings(t.Materials)
+
+	for pair, recorded := range cb.materialDefaults {
+		d := *recorded
+		name0, ok0 := names[pair.matid0]
+		name1, ok1 := names[pair.matid1]
+		if !ok0 || !ok1 || d.set == 0 {
+			continue
+		}
+
+		p := MaterialPairTable{Materials: [2]string{name0, name1}}
+		if d.set&materialFriction != 0 {
+			p.StaticFriction, p.KineticFriction = &d.staticFriction, &d.kineticFriction
+		}
+		if d.set&materialElasticity != 0 {
+			p.Elasticity = &d.elasticity
+		}
+		if d.set&materialSoftness != 0 {
+			p.Softness = &d.softness
+		}
+		if d.set&materialThickness != 0 {
+			p.Thickness = &d.thickness
+		}
+		if d.set&materialCollidable != 0 {
+			collidable := d.collidable != 0
+			p.Collidable = &collidable
+		}
+		t.Pairs = append(t.Pairs, p)
+	}
+	sort.Slice(t.Pairs, func(i, j int) bool {
+		if t.Pairs[i].Materials[0] != t.Pairs[j].Materials[0] {
+			return t.Pairs[i].Materials[0] < t.Pairs[j].Materials[0]
+		}
+		return t.Pairs[i].Materials[1] < t.Pairs[j].Materials[1]
+	})
+	return t
+}
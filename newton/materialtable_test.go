@@ -0,0 +1,156 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func float32Ptr(f float32) *float32 { return &f }
+
+func testMaterialTable() *MaterialTable {
+	collidable := false
+	return &MaterialTable{
+		Materials: []string{"ice", "rubber", "metal"},
+		Pairs: []MaterialPairTable{
+			{Materials: [2]string{"ice", "rubber"}, StaticFriction: float32Ptr(0.1),
+				KineticFriction: float32Ptr(0.05)},
+			{Materials: [2]string{"rubber", "rubber"}, Elasticity: float32Ptr(0.9),
+				Softness: float32Ptr(0.2), Thickness: float32Ptr(0.01)},
+			{Materials: [2]string{"metal", DefaultMaterialName}, Collidable: &collidable},
+		},
+	}
+}
+
+func TestReadMaterialTable(t *testing.T) {
+	tests := []struct {
+		name string
+		read func(r *strings.Reader) (*MaterialTable, error)
+		data string
+	}{
+		{"json", func(r *strings.Reader) (*MaterialTable, error) { return ReadMaterialTable(r) }, `{
+	"materials": ["ice", "rubber", "metal"],
+	"pairs": [
+		{"materials": ["ice", "rubber"], "staticFriction": 0.1, "kineticFriction": 0.05},
+		{"materials": ["rubber", "rubber"], "elasticity": 0.9, "softness": 0.2, "thickness": 0.01},
+		{"materials": ["metal", "default"], "collidable": false}
+	]
+}`},
+		{"yaml", func(r *strings.Reader) (*MaterialTable, error) { return ReadMaterialTableYAML(r) }, `
+materials: [ice, rubber, metal]
+pairs:
+  - materials: [ice, rubber]
+    staticFriction: 0.1
+    kineticFriction: 0.05
+  - materials: [rubber, rubber]
+    elasticity: 0.9
+    softness: 0.2
+    thickness: 0.01
+  - materials: [metal, default]
+    collidable: false
+`},
+	}
+
+	want := testMaterialTable()
+	for _, test := range tests {
+		got, err := test.read(strings.NewReader(test.data))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, want)
+		}
+	}
+}
+
+func TestMaterialTableRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(table *MaterialTable, buf *bytes.Buffer) error
+		read  func(buf *bytes.Buffer) (*MaterialTable, error)
+	}{
+		{"json",
+			func(table *MaterialTable, buf *bytes.Buffer) error { return table.WriteJSON(buf) },
+			func(buf *bytes.Buffer) (*MaterialTable, error) { return ReadMaterialTable(buf) }},
+		{"yaml",
+			func(table *MaterialTable, buf *bytes.Buffer) error { return table.WriteYAML(buf) },
+			func(buf *bytes.Buffer) (*MaterialTable, error) { return ReadMaterialTableYAML(buf) }},
+	}
+
+	want := testMaterialTable()
+	for _, test := range tests {
+		buf := &bytes.Buffer{}
+		if err := test.write(want, buf); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got, err := test.read(buf)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, want)
+		}
+	}
+}
+
+func TestMaterialTableValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   MaterialTable
+		unknown bool //the error should be ErrUnknownMaterial
+	}{
+		{"unknown first material", MaterialTable{
+			Materials: []string{"ice"},
+			Pairs:     []MaterialPairTable{{Materials: [2]string{"wood", "ice"}}},
+		}, true},
+		{"unknown second material", MaterialTable{
+			Materials: []string{"ice"},
+			Pairs:     []MaterialPairTable{{Materials: [2]string{"ice", "wood"}}},
+		}, true},
+		{"empty name", MaterialTable{
+			Materials: []string{"ice"},
+			Pairs:     []MaterialPairTable{{Materials: [2]string{"ice"}}},
+		}, true},
+		{"only static friction", MaterialTable{
+			Materials: []string{"ice"},
+			Pairs:     []MaterialPairTable{{Materials: [2]string{"ice", "ice"}, StaticFriction: float32Ptr(0.1)}},
+		}, false},
+		{"only kinetic friction", MaterialTable{
+			Materials: []string{"ice"},
+			Pairs:     []MaterialPairTable{{Materials: [2]string{"ice", "ice"}, KineticFriction: float32Ptr(0.1)}},
+		}, false},
+	}
+
+	for _, test := range tests {
+		err := test.table.Validate()
+		if err == nil {
+			t.Errorf("%s: Validate returned no error", test.name)
+			continue
+		}
+		if errors.Is(err, ErrUnknownMaterial) != test.unknown {
+			t.Errorf("%s: got error %v", test.name, err)
+		}
+	}
+
+	if err := testMaterialTable().Validate(); err != nil {
+		t.Errorf("valid table: %v", err)
+	}
+
+	//tables that don't validate aren't returned by the readers either
+	if _, err := ReadMaterialTable(strings.NewReader(
+		`{"materials": ["ice"], "pairs": [{"materials": ["ice", "wood"]}]}`)); !errors.Is(err, ErrUnknownMaterial) {
+		t.Errorf("ReadMaterialTable with an unknown material: got error %v", err)
+	}
+	if _, err := ReadMaterialTableYAML(strings.NewReader(
+		"materials: [ice]\npairs:\n  - materials: [ice, wood]\n")); !errors.Is(err, ErrUnknownMaterial) {
+		t.Errorf("ReadMaterialTableYAML with an unknown material: got error %v", err)
+	}
+}
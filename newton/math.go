@@ -0,0 +1,342 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import "math"
+
+//The math types share their layout with the float arrays used through the rest
+// of the package, so they can be passed to those methods with a conversion
+// such as (*[16]float32)(&matrix).
+//
+//Matrices follow Newton's convention: four rows of front, up, right and
+// position, with vectors multiplied as rows.  So a.Mul(b) is the transform a
+// followed by b, and the position of a matrix is in elements 12, 13 and 14.
+
+type Vec3 [3]float32
+
+type Vec4 [4]float32
+
+//Quat is a rotation quaternion stored in Newton's order, the scalar
+// part first followed by x, y and z
+type Quat [4]float32
+
+type Mat4 [16]float32
+
+func (v Vec3) Add(o Vec3) Vec3 { return Vec3{v[0] + o[0], v[1] + o[1], v[2] + o[2]} }
+func (v Vec3) Sub(o Vec3) Vec3 { return Vec3{v[0] - o[0], v[1] - o[1], v[2] - o[2]} }
+func (v Vec3) Scale(s float32) Vec3 {
+	return Vec3{v[0] * s, v[1] * s, v[2] * s}
+}
+
+func (v Vec3) Dot(o Vec3) float32 { return v[0]*o[0] + v[1]*o[1] + v[2]*o[2] }
+
+func (v Vec3) Cross(o Vec3) Vec3 {
+	return Vec3{
+		v[1]*o[2] - v[2]*o[1],
+		v[2]*o[0] - v[0]*o[2],
+		v[0]*o[1] - v[1]*o[0],
+	}
+}
+
+func (v Vec3) Len() float32 { return float32(math.Sqrt(float64(v.Dot(v)))) }
+
+//Normalize returns the unit vector in the direction of v, or v if it has no length
+func (v Vec3) Normalize() Vec3 {
+	l := v.Len()
+	if l == 0 {
+		return v
+	}
+	return v.Scale(1 / l)
+}
+
+//Vec4 returns v with the passed in w component
+func (v Vec3) Vec4(w float32) Vec4 { return Vec4{v[0], v[1], v[2], w} }
+
+//Lerp returns the point t of the way from v to o
+func (v Vec3) Lerp(o Vec3, t float32) Vec3 { return v.Add(o.Sub(v).Scale(t)) }
+
+func (v Vec4) Vec3() Vec3 { return Vec3{v[0], v[1], v[2]} }
+
+//IdentityQuat is the quaternion with no rotation
+func IdentityQuat() Quat { return Quat{1, 0, 0, 0} }
+
+//AxisAngleQuat returns the rotation of angle radians around axis
+func AxisAngleQuat(axis Vec3, angle float32) Quat {
+	axis = axis.Normalize()
+	sin, cos := math.Sincos(float64(angle) * 0.5)
+	s := float32(sin)
+	return Quat{float32(cos), axis[0] * s, axis[1] * s, axis[2] * s}
+}
+
+//EulerQuat returns the rotation of pitch, yaw and roll radians around the
+// x, y and z axis, in that order
+func EulerQuat(pitch, yaw, roll float32) Quat {
+	return EulerMatrix(pitch, yaw, roll).Quat()
+}
+
+//Mul returns the rotation q followed by o, matching the order of Mat4.Mul
+func (q Quat) Mul(o Quat) Quat {
+	return Quat{
+		o[0]*q[0] - o[1]*q[1] - o[2]*q[2] - o[3]*q[3],
+		o[0]*q[1] + o[1]*q[0] + o[2]*q[3] - o[3]*q[2],
+		o[0]*q[2] - o[1]*q[3] + o[2]*q[0] + o[3]*q[1],
+		o[0]*q[3] + o[1]*q[2] - o[2]*q[1] + o[3]*q[0],
+	}
+}
+
+func (q Quat) Len() float32 {
+	return float32(math.Sqrt(float64(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])))
+}
+
+func (q Quat) Normalize() Quat {
+	l := q.Len()
+	if l == 0 {
+		return IdentityQuat()
+	}
+	return Quat{q[0] / l, q[1] / l, q[2] / l, q[3] / l}
+}
+
+//Inverse returns the opposite rotation of the unit quaternion q
+func (q Quat) Inverse() Quat { return Quat{q[0], -q[1], -q[2], -q[3]} }
+
+//Slerp returns the rotation t of the way from q to o along the shortest arc
+func (q Quat) Slerp(o Quat, t float32) Quat {
+	dot := q[0]*o[0] + q[1]*o[1] + q[2]*o[2] + q[3]*o[3]
+	if dot < 0 {
+		o = Quat{-o[0], -o[1], -o[2], -o[3]}
+		dot = -dot
+	}
+
+	//nearly the same rotation, a straight line is close enough and avoids dividing by zero
+	if dot > 0.9995 {
+		return Quat{
+			q[0] + (o[0]-q[0])*t,
+			q[1] + (o[1]-q[1])*t,
+			q[2] + (o[2]-q[2])*t,
+			q[3] + (o[3]-q[3])*t,
+		}.Normalize()
+	}
+
+	theta := math.Acos(float64(dot))
+	sin := math.Sin(theta)
+	s0 := float32(math.Sin((1-float64(t))*theta) / sin)
+	s1 := float32(math.Sin(float64(t)*theta) / sin)
+	return Quat{
+		q[0]*s0 + o[0]*s1,
+		q[1]*s0 + o[1]*s1,
+		q[2]*s0 + o[2]*s1,
+		q[3]*s0 + o[3]*s1,
+	}
+}
+
+//Rotate returns v rotated by q
+func (q Quat) Rotate(v Vec3) Vec3 {
+	return q.Mat4().RotateVector(v)
+}
+
+//Mat4 returns the rotation matrix of q with no translation
+func (q Quat) Mat4() Mat4 {
+	w, x, y, z := q[0], q[1], q[2], q[3]
+	return Mat4{
+		1 - 2*(y*y+z*z), 2 * (x*y + w*z), 2 * (x*z - w*y), 0,
+		2 * (x*y - w*z), 1 - 2*(x*x+z*z), 2 * (y*z + w*x), 0,
+		2 * (x*z + w*y), 2 * (y*z - w*x), 1 - 2*(x*x+y*y), 0,
+		0, 0, 0, 1,
+	}
+}
+
+func Identity() Mat4 {
+	return Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+//TranslationMatrix returns a matrix that moves by v with no rotation
+func TranslationMatrix(v Vec3) Mat4 {
+	m := Identity()
+	m.SetPosition(v)
+	return m
+}
+
+//RotationMatrix returns a matrix that rotates angle radians around axis
+func RotationMatrix(axis Vec3, angle float32) Mat4 {
+	return AxisAngleQuat(axis, angle).Mat4()
+}
+
+//EulerMatrix returns a matrix that rotates pitch, yaw and roll radians around
+// the x, y and z axis, in that order
+func EulerMatrix(pitch, yaw, roll float32) Mat4 {
+	sp, cp := sincos(pitch)
+	sy, cy := sincos(yaw)
+	sr, cr := sincos(roll)
+
+	pitchMatrix := Mat4{
+		1, 0, 0, 0,
+		0, cp, sp, 0,
+		0, -sp, cp, 0,
+		0, 0, 0, 1,
+	}
+	yawMatrix := Mat4{
+		cy, 0, -sy, 0,
+		0, 1, 0, 0,
+		sy, 0, cy, 0,
+		0, 0, 0, 1,
+	}
+	rollMatrix := Mat4{
+		cr, sr, 0, 0,
+		-sr, cr, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+
+	return pitchMatrix.Mul(yawMatrix).Mul(rollMatrix)
+}
+
+//LookAtMatrix returns a matrix positioned at eye with its front (x) axis
+// pointing at target, and its up (y) axis as close to up as possible
+func LookAtMatrix(eye, target, up Vec3) Mat4 {
+	front := target.Sub(eye).Normalize()
+	right := front.Cross(up).Normalize()
+	up = right.Cross(front)
+
+	return Mat4{
+		front[0], front[1], front[2], 0,
+		up[0], up[1], up[2], 0,
+		right[0], right[1], right[2], 0,
+		eye[0], eye[1], eye[2], 1,
+	}
+}
+
+func sincos(angle float32) (float32, float32) {
+	s, c := math.Sincos(float64(angle))
+	return float32(s), float32(c)
+}
+
+func (m Mat4) Front() Vec3    { return Vec3{m[0], m[1], m[2]} }
+func (m Mat4) Up() Vec3       { return Vec3{m[4], m[5], m[6]} }
+func (m Mat4) Right() Vec3    { return Vec3{m[8], m[9], m[10]} }
+func (m Mat4) Position() Vec3 { return Vec3{m[12], m[13], m[14]} }
+
+func (m *Mat4) SetPosition(v Vec3) {
+	m[12], m[13], m[14] = v[0], v[1], v[2]
+}
+
+//Mul returns the transform m followed by o
+func (m Mat4) Mul(o Mat4) Mat4 {
+	var r Mat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			r[row*4+col] = m[row*4]*o[col] + m[row*4+1]*o[4+col] +
+				m[row*4+2]*o[8+col] + m[row*4+3]*o[12+col]
+		}
+	}
+	return r
+}
+
+//TransformPoint returns p rotated and moved by m
+func (m Mat4) TransformPoint(p Vec3) Vec3 {
+	return m.RotateVector(p).Add(m.Position())
+}
+
+//RotateVector returns v rotated by m, ignoring its position
+func (m Mat4) RotateVector(v Vec3) Vec3 {
+	return Vec3{
+		v[0]*m[0] + v[1]*m[4] + v[2]*m[8],
+		v[0]*m[1] + v[1]*m[5] + v[2]*m[9],
+		v[0]*m[2] + v[1]*m[6] + v[2]*m[10],
+	}
+}
+
+//Transpose returns m with its rows and columns swapped
+func (m Mat4) Transpose() Mat4 {
+	var r Mat4
+	for row := 0; row < 4; row++ {
+		for col := 0; col < 4; col++ {
+			r[col*4+row] = m[row*4+col]
+		}
+	}
+	return r
+}
+
+//Inverse returns the inverse of m, or the zero matrix if m can't be inverted
+func (m Mat4) Inverse() Mat4 {
+	var inv Mat4
+
+	inv[0] = m[5]*m[10]*m[15] - m[5]*m[11]*m[14] - m[9]*m[6]*m[15] +
+		m[9]*m[7]*m[14] + m[13]*m[6]*m[11] - m[13]*m[7]*m[10]
+	inv[4] = -m[4]*m[10]*m[15] + m[4]*m[11]*m[14] + m[8]*m[6]*m[15] -
+		m[8]*m[7]*m[14] - m[12]*m[6]*m[11] + m[12]*m[7]*m[10]
+	inv[8] = m[4]*m[9]*m[15] - m[4]*m[11]*m[13] - m[8]*m[5]*m[15] +
+		m[8]*m[7]*m[13] + m[12]*m[5]*m[11] - m[12]*m[7]*m[9]
+	inv[12] = -m[4]*m[9]*m[14] + m[4]*m[10]*m[13] + m[8]*m[5]*m[14] -
+		m[8]*m[6]*m[13] - m[12]*m[5]*m[10] + m[12]*m[6]*m[9]
+	inv[1] = -m[1]*m[10]*m[15] + m[1]*m[11]*m[14] + m[9]*m[2]*m[15] -
+		m[9]*m[3]*m[14] - m[13]*m[2]*m[11] + m[13]*m[3]*m[10]
+	inv[5] = m[0]*m[10]*m[15] - m[0]*m[11]*m[14] - m[8]*m[2]*m[15] +
+		m[8]*m[3]*m[14] + m[12]*m[2]*m[11] - m[12]*m[3]*m[10]
+	inv[9] = -m[0]*m[9]*m[15] + m[0]*m[11]*m[13] + m[8]*m[1]*m[15] -
+		m[8]*m[3]*m[13] - m[12]*m[1]*m[11] + m[12]*m[3]*m[9]
+	inv[13] = m[0]*m[9]*m[14] - m[0]*m[10]*m[13] - m[8]*m[1]*m[14] +
+		m[8]*m[2]*m[13] + m[12]*m[1]*m[10] - m[12]*m[2]*m[9]
+	inv[2] = m[1]*m[6]*m[15] - m[1]*m[7]*m[14] - m[5]*m[2]*m[15] +
+		m[5]*m[3]*m[14] + m[13]*m[2]*m[7] - m[13]*m[3]*m[6]
+	inv[6] = -m[0]*m[6]*m[15] + m[0]*m[7]*m[14] + m[4]*m[2]*m[15] -
+		m[4]*m[3]*m[14] - m[12]*m[2]*m[7] + m[12]*m[3]*m[6]
+	inv[10] = m[0]*m[5]*m[15] - m[0]*m[7]*m[13] - m[4]*m[1]*m[15] +
+		m[4]*m[3]*m[13] + m[12]*m[1]*m[7] - m[12]*m[3]*m[5]
+	inv[14] = -m[0]*m[5]*m[14] + m[0]*m[6]*m[13] + m[4]*m[1]*m[14] -
+		m[4]*m[2]*m[13] - m[12]*m[1]*m[6] + m[12]*m[2]*m[5]
+	inv[3] = -m[1]*m[6]*m[11] + m[1]*m[7]*m[10] + m[5]*m[2]*m[11] -
+		m[5]*m[3]*m[10] - m[9]*m[2]*m[7] + m[9]*m[3]*m[6]
+	inv[7] = m[0]*m[6]*m[11] - m[0]*m[7]*m[10] - m[4]*m[2]*m[11] +
+		m[4]*m[3]*m[10] + m[8]*m[2]*m[7] - m[8]*m[3]*m[6]
+	inv[11] = -m[0]*m[5]*m[11] + m[0]*m[7]*m[9] + m[4]*m[1]*m[11] -
+		m[4]*m[3]*m[9] - m[8]*m[1]*m[7] + m[8]*m[3]*m[5]
+	inv[15] = m[0]*m[5]*m[10] - m[0]*m[6]*m[9] - m[4]*m[1]*m[10] +
+		m[4]*m[2]*m[9] + m[8]*m[1]*m[6] - m[8]*m[2]*m[5]
+
+	det := m[0]*inv[0] + m[1]*inv[4] + m[2]*inv[8] + m[3]*inv[12]
+	if det == 0 {
+		return Mat4{}
+	}
+
+	for i := range inv {
+		inv[i] /= det
+	}
+	return inv
+}
+
+//Quat returns the rotation part of m as a quaternion
+func (m Mat4) Quat() Quat {
+	trace := m[0] + m[5] + m[10]
+
+	var q Quat
+	switch {
+	case trace > 0:
+		s := float32(math.Sqrt(float64(trace+1))) * 2
+		q = Quat{0.25 * s, (m[6] - m[9]) / s, (m[8] - m[2]) / s, (m[1] - m[4]) / s}
+	case m[0] > m[5] && m[0] > m[10]:
+		s := float32(math.Sqrt(float64(1+m[0]-m[5]-m[10]))) * 2
+		q = Quat{(m[6] - m[9]) / s, 0.25 * s, (m[4] + m[1]) / s, (m[8] + m[2]) / s}
+	case m[5] > m[10]:
+		s := float32(math.Sqrt(float64(1+m[5]-m[0]-m[10]))) * 2
+		q = Quat{(m[8] - m[2]) / s, (m[4] + m[1]) / s, 0.25 * s, (m[9] + m[6]) / s}
+	default:
+		s := float32(math.Sqrt(float64(1+m[10]-m[0]-m[5]))) * 2
+		q = Quat{(m[1] - m[4]) / s, (m[8] + m[2]) / s, (m[9] + m[6]) / s, 0.25 * s}
+	}
+
+	return q.Normalize()
+}
+
+//Interpolate returns the transform t of the way from m to o, the position is
+// interpolated in a straight line and the rotation along the shortest arc
+func (m Mat4) Interpolate(o Mat4, t float32) Mat4 {
+	result := m.Quat().Slerp(o.Quat(), t).Mat4()
+	result.SetPosition(m.Position().Lerp(o.Position(), t))
+	return result
+}
@@ -0,0 +1,198 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"math"
+	"testing"
+)
+
+const mathTolerance = 1e-5
+
+func nearFloats(a, b []float32) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > mathTolerance {
+			return false
+		}
+	}
+	return true
+}
+
+//nearQuat compares rotations, q and -q are the same rotation
+func nearQuat(a, b Quat) bool {
+	return nearFloats(a[:], b[:]) || nearFloats(a[:], []float32{-b[0], -b[1], -b[2], -b[3]})
+}
+
+var (
+	xAxis = Vec3{1, 0, 0}
+	yAxis = Vec3{0, 1, 0}
+	zAxis = Vec3{0, 0, 1}
+)
+
+func TestQuatRotate(t *testing.T) {
+	tests := []struct {
+		name  string
+		axis  Vec3
+		angle float32
+		v     Vec3
+		want  Vec3
+	}{
+		{"identity", xAxis, 0, Vec3{1, 2, 3}, Vec3{1, 2, 3}},
+		{"x 90", xAxis, math.Pi / 2, yAxis, zAxis},
+		{"y 90", yAxis, math.Pi / 2, zAxis, xAxis},
+		{"z 90", zAxis, math.Pi / 2, xAxis, yAxis},
+		{"z 180", zAxis, math.Pi, xAxis, Vec3{-1, 0, 0}},
+		{"unnormalized axis", Vec3{0, 0, 5}, math.Pi / 2, xAxis, yAxis},
+		{"around itself", Vec3{1, 1, 1}, 2, Vec3{2, 2, 2}, Vec3{2, 2, 2}},
+	}
+
+	for _, test := range tests {
+		q := AxisAngleQuat(test.axis, test.angle)
+		if got := q.Rotate(test.v); !nearFloats(got[:], test.want[:]) {
+			t.Errorf("%s: Quat.Rotate(%v) = %v, want %v", test.name, test.v, got, test.want)
+		}
+		m := RotationMatrix(test.axis, test.angle)
+		if got := m.RotateVector(test.v); !nearFloats(got[:], test.want[:]) {
+			t.Errorf("%s: Mat4.RotateVector(%v) = %v, want %v", test.name, test.v, got, test.want)
+		}
+	}
+}
+
+func TestQuatMat4RoundTrip(t *testing.T) {
+	//the 180 degree rotations have a negative trace, so each of the branches of
+	// Mat4.Quat is covered
+	tests := []struct {
+		name string
+		q    Quat
+	}{
+		{"identity", IdentityQuat()},
+		{"x 90", AxisAngleQuat(xAxis, math.Pi/2)},
+		{"y 90", AxisAngleQuat(yAxis, math.Pi/2)},
+		{"z 90", AxisAngleQuat(zAxis, math.Pi/2)},
+		{"x 180", AxisAngleQuat(xAxis, math.Pi)},
+		{"y 180", AxisAngleQuat(yAxis, math.Pi)},
+		{"z 180", AxisAngleQuat(zAxis, math.Pi)},
+		{"arbitrary", AxisAngleQuat(Vec3{1, -2, 3}, 2.5)},
+		{"euler", EulerQuat(0.3, -1.2, 2.1)},
+	}
+
+	for _, test := range tests {
+		if got := test.q.Mat4().Quat(); !nearQuat(got, test.q) {
+			t.Errorf("%s: %v.Mat4().Quat() = %v", test.name, test.q, got)
+		}
+	}
+}
+
+func TestEulerOrder(t *testing.T) {
+	tests := []struct {
+		pitch, yaw, roll float32
+	}{
+		{0, 0, 0},
+		{math.Pi / 2, 0, 0},
+		{0, math.Pi / 2, 0},
+		{0, 0, math.Pi / 2},
+		{0.3, -1.2, 2.1},
+	}
+
+	for _, test := range tests {
+		want := AxisAngleQuat(xAxis, test.pitch).
+			Mul(AxisAngleQuat(yAxis, test.yaw)).
+			Mul(AxisAngleQuat(zAxis, test.roll))
+		if got := EulerQuat(test.pitch, test.yaw, test.roll); !nearQuat(got, want) {
+			t.Errorf("EulerQuat(%v, %v, %v) = %v, want %v", test.pitch, test.yaw, test.roll, got, want)
+		}
+
+		wantMatrix := want.Mat4()
+		got := EulerMatrix(test.pitch, test.yaw, test.roll)
+		if !nearFloats(got[:], wantMatrix[:]) {
+			t.Errorf("EulerMatrix(%v, %v, %v) = %v, want %v", test.pitch, test.yaw, test.roll,
+				got, wantMatrix)
+		}
+	}
+}
+
+func TestMat4Mul(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     Mat4
+		point Vec3
+		want  Vec3
+	}{
+		{"identity", Identity(), Vec3{1, 2, 3}, Vec3{1, 2, 3}},
+		{"translation", TranslationMatrix(Vec3{1, 2, 3}), Vec3{1, 1, 1}, Vec3{2, 3, 4}},
+		{"move then rotate", TranslationMatrix(xAxis).Mul(RotationMatrix(zAxis, math.Pi/2)),
+			Vec3{}, yAxis},
+		{"rotate then move", RotationMatrix(zAxis, math.Pi/2).Mul(TranslationMatrix(xAxis)),
+			Vec3{}, xAxis},
+		{"rotate then move a point", RotationMatrix(zAxis, math.Pi/2).Mul(TranslationMatrix(xAxis)),
+			xAxis, Vec3{1, 1, 0}},
+	}
+
+	for _, test := range tests {
+		if got := test.m.TransformPoint(test.point); !nearFloats(got[:], test.want[:]) {
+			t.Errorf("%s: TransformPoint(%v) = %v, want %v", test.name, test.point, got, test.want)
+		}
+	}
+}
+
+func TestMat4Inverse(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Mat4
+	}{
+		{"identity", Identity()},
+		{"translation", TranslationMatrix(Vec3{1, -2, 3})},
+		{"rotation", RotationMatrix(Vec3{1, 1, 0}, 1)},
+		{"rotation and translation", EulerMatrix(0.3, -1.2, 2.1).Mul(TranslationMatrix(Vec3{4, 5, 6}))},
+		{"look at", LookAtMatrix(Vec3{1, 2, 3}, Vec3{-4, 0, 2}, yAxis)},
+	}
+
+	identity := Identity()
+	for _, test := range tests {
+		got := test.m.Mul(test.m.Inverse())
+		if !nearFloats(got[:], identity[:]) {
+			t.Errorf("%s: m.Mul(m.Inverse()) = %v, want the identity", test.name, got)
+		}
+	}
+
+	if got := (Mat4{}).Inverse(); got != (Mat4{}) {
+		t.Errorf("inverse of a singular matrix = %v, want the zero matrix", got)
+	}
+}
+
+func TestQuatSlerp(t *testing.T) {
+	z90 := AxisAngleQuat(zAxis, math.Pi/2)
+	tests := []struct {
+		name string
+		q, o Quat
+		t    float32
+		want Quat
+	}{
+		{"start", IdentityQuat(), z90, 0, IdentityQuat()},
+		{"end", IdentityQuat(), z90, 1, z90},
+		{"half", IdentityQuat(), z90, 0.5, AxisAngleQuat(zAxis, math.Pi/4)},
+		{"shortest arc", IdentityQuat(), Quat{-z90[0], -z90[1], -z90[2], -z90[3]}, 0.5,
+			AxisAngleQuat(zAxis, math.Pi/4)},
+		{"nearly the same", IdentityQuat(), AxisAngleQuat(zAxis, 0.001), 0.5,
+			AxisAngleQuat(zAxis, 0.0005)},
+	}
+
+	for _, test := range tests {
+		if got := test.q.Slerp(test.o, test.t); !nearQuat(got, test.want) {
+			t.Errorf("%s: Slerp(%v) = %v, want %v", test.name, test.t, got, test.want)
+		}
+	}
+}
+
+func TestMat4Interpolate(t *testing.T) {
+	from := TranslationMatrix(Vec3{0, 0, 0})
+	to := RotationMatrix(zAxis, math.Pi/2)
+	to.SetPosition(Vec3{2, 4, 6})
+
+	want := RotationMatrix(zAxis, math.Pi/4)
+	want.SetPosition(Vec3{1, 2, 3})
+	if got := from.Interpolate(to, 0.5); !nearFloats(got[:], want[:]) {
+		t.Errorf("Interpolate(0.5) = %v, want %v", got, want)
+	}
+}
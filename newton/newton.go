@@ -1,101 +1,212 @@
 // Copyright 2012 Tim Shannon. All rights reserved.
 // Use of this source code is governed by the MIT license
 // that can be found in the LICENSE file.
+
+//Package newton is a Go binding for the Newton Dynamics physics engine
 package newton
 
 /*
 #cgo   linux LDFLAGS: -L/usr/local/lib -lNewton -lstdc++
 #include "Newton.h"
+#include "callback.h"
 #include <stdlib.h>
 */
 import "C"
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"unsafe"
+)
 
 //used for bools from c interfaces
 var gbool = map[int]bool{0: false, 1: true}
 var cint = map[bool]C.int{false: C.int(0), true: C.int(1)}
 
-//Holds user data in go code, so it doesn't get collected
-var ownerData = make(map[owner]interface{})
+//Holds user data in go code, so it doesn't get collected.
+// Entries are removed when their owner's destructor fires, so
+// destroying a world only releases what belonged to it
+var ownerData = newHandlers[interface{}]()
+
+var (
+	//ErrCreateFailed is returned when Newton couldn't create the requested object,
+	// such as a convex hull from degenerate points
+	ErrCreateFailed = errors.New("newton: failed to create")
+	ErrNoVertices   = errors.New("newton: not enough vertices")
+	ErrZeroPin      = errors.New("newton: pin direction has zero length")
+	ErrNilBody      = errors.New("newton: body is nil")
+	ErrNilPivot     = errors.New("newton: pivot point is nil")
+	ErrNilCollision = errors.New("newton: collision is nil")
+	ErrNilMesh      = errors.New("newton: mesh is nil")
+)
+
+func createError(what string) error {
+	return fmt.Errorf("%w %s", ErrCreateFailed, what)
+}
+
+//handlePanic is the message when a method is called on a nil or destroyed object,
+// which would otherwise crash inside Newton
+const handlePanic = "newton: use of nil or destroyed %s"
+
+//matrixPtr returns the c pointer to matrix, or NULL if matrix is nil.
+// Newton treats a NULL offset matrix as the identity
+func matrixPtr(matrix *[16]float32) *C.dFloat {
+	if matrix == nil {
+		return nil
+	}
+	return (*C.dFloat)(&matrix[0])
+}
+
+//validPin returns an error if the pin direction has no length
+func validPin(pin *[3]float32) error {
+	if pin == nil || Vec3(*pin).Len() == 0 {
+		return ErrZeroPin
+	}
+	return nil
+}
 
 type World struct {
 	handle *C.NewtonWorld
 }
 
+func (w *World) ptr() *C.NewtonWorld {
+	if w == nil || w.handle == nil {
+		panic(fmt.Sprintf(handlePanic, "World"))
+	}
+	return w.handle
+}
+
 func Version() int    { return int(C.NewtonWorldGetVersion()) }
 func MemoryUsed() int { return int(C.NewtonGetMemoryUsed()) }
 
-func CreateWorld() *World {
-	return &World{C.NewtonCreate()}
+func CreateWorld() (*World, error) {
+	handle := C.NewtonCreate()
+	if handle == nil {
+		return nil, createError("world")
+	}
+	w := &World{handle}
+	w.callbacks().world = w
+	C.setCollisionDestructorCB(w.handle)
+	return w, nil
 }
 
+//Destroy destroys the world along with every body and joint in it.  Any mesh or
+// collision created by the world that hasn't been destroyed is released too
 func (w *World) Destroy() {
-	ownerData = make(map[owner]interface{})
-	C.NewtonDestroy(w.handle)
+	w.releaseResources()
+	C.NewtonDestroy(w.ptr())
+	ownerData.remove(owner(w.handle))
+	w.releaseCallbacks()
+	w.handle = nil
 }
 
 func (w *World) DestroyAllBodies() {
-	C.NewtonDestroyAllBodies(w.handle)
+	C.NewtonDestroyAllBodies(w.ptr())
 }
 
-func (w *World) InvalidateCache() { C.NewtonInvalidateCache(w.handle) }
+func (w *World) InvalidateCache() { C.NewtonInvalidateCache(w.ptr()) }
 
 //SetSolverModel sets the solver model for the world
 // 0 is default with perfect accuracy
 // 1 and greater increases iterations. Greater number less peformant but more accurate
-func (w *World) SetSolverModel(model int) { C.NewtonSetSolverModel(w.handle, C.int(model)) }
+func (w *World) SetSolverModel(model int) { C.NewtonSetSolverModel(w.ptr(), C.int(model)) }
 
 //TODO: multithreading
 
 func (w *World) ReadPeformanceTicks(performanceEntry uint) uint {
-	return uint(C.NewtonReadPerformanceTicks(w.handle, C.unsigned(performanceEntry)))
+	return uint(C.NewtonReadPerformanceTicks(w.ptr(), C.unsigned(performanceEntry)))
 }
 
 func (w *World) BroadphaseAlgorithm() int {
-	return int(C.NewtonGetBroadphaseAlgorithm(w.handle))
+	return int(C.NewtonGetBroadphaseAlgorithm(w.ptr()))
 }
 
 func (w *World) SetBroadphaseAlgorithm(algorithmType int) {
-	C.NewtonSelectBroadphaseAlgorithm(w.handle, C.int(algorithmType))
+	C.NewtonSelectBroadphaseAlgorithm(w.ptr(), C.int(algorithmType))
 }
 
 func (w *World) Update(timestep float32) {
-	C.NewtonUpdate(w.handle, C.dFloat(timestep))
+	w.releasePending()
+	C.NewtonUpdate(w.ptr(), C.dFloat(timestep))
+	w.afterUpdate(timestep)
 }
 
+//UpdateAsync starts an update and returns without waiting for it.  Trigger and
+// contact events for the update are sent from WaitForUpdateToFinish
 func (w *World) UpdateAsync(timestep float32) {
-	C.NewtonUpdateAsync(w.handle, C.dFloat(timestep))
+	w.releasePending()
+	cb := w.callbacks()
+	cb.Lock()
+	cb.pendingUpdate = &timestep
+	cb.Unlock()
+	C.NewtonUpdateAsync(w.ptr(), C.dFloat(timestep))
 }
 func (w *World) WaitForUpdateToFinish() {
-	C.NewtonWaitForUpdateToFinish(w.handle)
+	C.NewtonWaitForUpdateToFinish(w.ptr())
+
+	cb := w.callbacks()
+	cb.Lock()
+	timestep := cb.pendingUpdate
+	cb.pendingUpdate = nil
+	cb.Unlock()
+	if timestep != nil {
+		w.afterUpdate(*timestep)
+	}
+}
+
+//afterUpdate counts an update that has finished, and sends its trigger and contact
+// events
+func (w *World) afterUpdate(timestep float32) {
+	w.updateOneWay()
+	w.updateTriggers()
+	w.updateContacts(timestep)
 }
 
 func (w *World) SetFrictionModel(model int) {
-	C.NewtonSetFrictionModel(w.handle, C.int(model))
+	C.NewtonSetFrictionModel(w.ptr(), C.int(model))
 }
 
 func (w *World) SetMinimumFrameRate(frameRate float32) {
-	C.NewtonSetMinimumFrameRate(w.handle, C.dFloat(frameRate))
+	C.NewtonSetMinimumFrameRate(w.ptr(), C.dFloat(frameRate))
 }
 
 func (w *World) BodyCount() int {
-	return int(C.NewtonWorldGetBodyCount(w.handle))
+	return int(C.NewtonWorldGetBodyCount(w.ptr()))
 }
 
 func (w *World) ConstraintCount() int {
-	return int(C.NewtonWorldGetConstraintCount(w.handle))
+	return int(C.NewtonWorldGetConstraintCount(w.ptr()))
 }
 
+//FirstBody and NextBody return nil when there are no more bodies
 func (w *World) FirstBody() *Body {
-	return &Body{C.NewtonWorldGetFirstBody(w.handle)}
+	return newBody(C.NewtonWorldGetFirstBody(w.ptr()))
 }
 
 func (w *World) NextBody(curBody *Body) *Body {
-	return &Body{C.NewtonWorldGetNextBody(w.handle, curBody.handle)}
+	return newBody(C.NewtonWorldGetNextBody(w.ptr(), curBody.ptr()))
 }
 
+//Bodies iterates over every body in the world.  The next body is found before
+// the current one is yielded, so it's safe to destroy the current body in the loop
+func (w *World) Bodies() iter.Seq[*Body] {
+	return func(yield func(*Body) bool) {
+		for body := w.FirstBody(); body != nil; {
+			next := w.NextBody(body)
+			if !yield(body) {
+				return
+			}
+			body = next
+		}
+	}
+}
+
+//SerializeToFile writes Newton's own debug dump of the world to filename, it can't be
+// loaded again.  Use Serialize to save a world that LoadWorld can load
 func (w *World) SerializeToFile(filename string) {
 	cFileName := C.CString(filename)
-	C.NewtonSerializeToFile(w.handle, cFileName)
+	defer C.free(unsafe.Pointer(cFileName))
+	C.NewtonSerializeToFile(w.ptr(), cFileName)
 }
 
 //Low level standalone collision todo later
@@ -113,262 +224,374 @@ type Body struct {
 	handle *C.NewtonBody
 }
 
+func (b *Body) ptr() *C.NewtonBody {
+	if b == nil || b.handle == nil {
+		panic(fmt.Sprintf(handlePanic, "Body"))
+	}
+	return b.handle
+}
+
+//createBody checks the arguments shared by the body constructors
+func createBody(collision *Collision, matrix *[16]float32) (*C.NewtonCollision, *[16]float32, error) {
+	if collision == nil || collision.handle == nil {
+		return nil, nil, ErrNilCollision
+	}
+	if matrix == nil {
+		identity := [16]float32(Identity())
+		matrix = &identity
+	}
+	return collision.handle, matrix, nil
+}
+
 const (
 	BodyDynamic = iota
 	BodyKinematic
 	BodyDeformable
 )
 
-func (w *World) CreateDynamicBody(collision *Collision, matrix *[16]float32) *Body {
-	return &Body{C.NewtonCreateDynamicBody(w.handle, collision.handle, (*C.dFloat)(&matrix[0]))}
-}
-
-func (w *World) CreateKinematicBody(collision *Collision, matrix *[16]float32) *Body {
-	return &Body{C.NewtonCreateKinematicBody(w.handle, collision.handle, (*C.dFloat)(&matrix[0]))}
-}
-
+//CreateDynamicBody creates a body from the collision, a nil matrix places it at the origin
+func (w *World) CreateDynamicBody(collision *Collision, matrix *[16]float32) (*Body, error) {
+	cCollision, matrix, err := createBody(collision, matrix)
+	if err != nil {
+		return nil, err
+	}
+	body := newBody(C.NewtonCreateDynamicBody(w.ptr(), cCollision, (*C.dFloat)(&matrix[0])))
+	if body == nil {
+		return nil, createError("dynamic body")
+	}
+	return body, nil
+}
+
+//CreateKinematicBody creates a body from the collision, a nil matrix places it at the origin
+func (w *World) CreateKinematicBody(collision *Collision, matrix *[16]float32) (*Body, error) {
+	cCollision, matrix, err := createBody(collision, matrix)
+	if err != nil {
+		return nil, err
+	}
+	body := newBody(C.NewtonCreateKinematicBody(w.ptr(), cCollision, (*C.dFloat)(&matrix[0])))
+	if body == nil {
+		return nil, createError("kinematic body")
+	}
+	return body, nil
+}
+
+//DestroyBody destroys the body, the passed in body can't be used afterwards
 func (w *World) DestroyBody(body *Body) {
-	C.NewtonDestroyBody(w.handle, body.handle)
+	C.NewtonDestroyBody(w.ptr(), body.ptr())
+	body.handle = nil
 }
 
 func (w *World) UserData() interface{} {
-	//return (interface{})(C.NewtonWorldGetUserData(w.handle))
-	return ownerData[owner(w.handle)]
+	//return (interface{})(C.NewtonWorldGetUserData(w.ptr()))
+	data, _ := ownerData.get(owner(w.handle))
+	return data
 }
 
 func (w *World) SetUserData(userData interface{}) {
-	//C.NewtonWorldSetUserData(w.handle, unsafe.Pointer(&userData))
-	ownerData[owner(w.handle)] = userData
+	//C.NewtonWorldSetUserData(w.ptr(), unsafe.Pointer(&userData))
+	ownerData.set(owner(w.handle), userData)
 }
 
 func (b *Body) Type() int {
-	return int(C.NewtonBodyGetType(b.handle))
+	return int(C.NewtonBodyGetType(b.ptr()))
 }
 
 func (b *Body) AddForce(force *[3]float32) {
-	C.NewtonBodyAddForce(b.handle, (*C.dFloat)(&force[0]))
+	C.NewtonBodyAddForce(b.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (b *Body) AddTorque(torque *[3]float32) {
-	C.NewtonBodyAddTorque(b.handle, (*C.dFloat)(&torque[0]))
+	C.NewtonBodyAddTorque(b.ptr(), (*C.dFloat)(&torque[0]))
 }
 
 func (b *Body) CalculateInverseDynamicsForce(timestep float32, desiredVeloc, forceOut *[3]float32) {
-	C.NewtonBodyCalculateInverseDynamicsForce(b.handle, C.dFloat(timestep), (*C.dFloat)(&desiredVeloc[0]),
+	C.NewtonBodyCalculateInverseDynamicsForce(b.ptr(), C.dFloat(timestep), (*C.dFloat)(&desiredVeloc[0]),
 		(*C.dFloat)(&forceOut[0]))
 }
 
 func (b *Body) SetCentreOfMass(relativeOffset *[3]float32) {
-	C.NewtonBodySetCentreOfMass(b.handle, (*C.dFloat)(&relativeOffset[0]))
+	C.NewtonBodySetCentreOfMass(b.ptr(), (*C.dFloat)(&relativeOffset[0]))
 }
 
 func (b *Body) SetMassMatrix(mass, Ixx, Iyy, Izz float32) {
-	C.NewtonBodySetMassMatrix___(b.handle, C.dFloat(mass), C.dFloat(Ixx), C.dFloat(Iyy), C.dFloat(Izz))
+	C.NewtonBodySetMassMatrix___(b.ptr(), C.dFloat(mass), C.dFloat(Ixx), C.dFloat(Iyy), C.dFloat(Izz))
 }
 
 func (b *Body) SetMassProperties(mass float32, collision *Collision) {
-	C.NewtonBodySetMassProperties(b.handle, C.dFloat(mass), collision.handle)
+	C.NewtonBodySetMassProperties(b.ptr(), C.dFloat(mass), collision.ptr())
 }
 
 func (b *Body) SetMatrix(matrix *[16]float32) {
-	C.NewtonBodySetMatrix(b.handle, (*C.dFloat)(&matrix[0]))
+	C.NewtonBodySetMatrix(b.ptr(), (*C.dFloat)(&matrix[0]))
+	resetInterpolation(b.handle, Mat4(*matrix))
 }
 
 func (b *Body) SetMatrixRecursive(matrix *[16]float32) {
-	C.NewtonBodySetMatrixRecursive(b.handle, (*C.dFloat)(&matrix[0]))
+	C.NewtonBodySetMatrixRecursive(b.ptr(), (*C.dFloat)(&matrix[0]))
 }
 
 func (b *Body) SetMaterialGroupID(id int) {
-	C.NewtonBodySetMaterialGroupID(b.handle, C.int(id))
+	C.NewtonBodySetMaterialGroupID(b.ptr(), C.int(id))
 }
 
 func (b *Body) SetContinuousCollisionMode(state uint) {
-	C.NewtonBodySetContinuousCollisionMode(b.handle, C.uint(state))
+	C.NewtonBodySetContinuousCollisionMode(b.ptr(), C.uint(state))
 }
 
 func (b *Body) SetJointRecursiveCollision(state uint) {
-	C.NewtonBodySetJointRecursiveCollision(b.handle, C.uint(state))
+	C.NewtonBodySetJointRecursiveCollision(b.ptr(), C.uint(state))
 }
 
 func (b *Body) SetOmega(omega *[3]float32) {
-	C.NewtonBodySetOmega(b.handle, (*C.dFloat)(&omega[0]))
+	C.NewtonBodySetOmega(b.ptr(), (*C.dFloat)(&omega[0]))
 }
 
 func (b *Body) SetVelocity(velocity *[3]float32) {
-	C.NewtonBodySetVelocity(b.handle, (*C.dFloat)(&velocity[0]))
+	C.NewtonBodySetVelocity(b.ptr(), (*C.dFloat)(&velocity[0]))
 }
 
 func (b *Body) SetForce(force *[3]float32) {
-	C.NewtonBodySetForce(b.handle, (*C.dFloat)(&force[0]))
+	C.NewtonBodySetForce(b.ptr(), (*C.dFloat)(&force[0]))
 }
 
 func (b *Body) SetTorque(torque *[3]float32) {
-	C.NewtonBodySetTorque(b.handle, (*C.dFloat)(&torque[0]))
+	C.NewtonBodySetTorque(b.ptr(), (*C.dFloat)(&torque[0]))
 }
 
 func (b *Body) SetLinearDamping(linearDamp float32) {
-	C.NewtonBodySetLinearDamping(b.handle, C.dFloat(linearDamp))
+	C.NewtonBodySetLinearDamping(b.ptr(), C.dFloat(linearDamp))
 }
 
 func (b *Body) SetAngularDamping(angularDamp *[3]float32) {
-	C.NewtonBodySetAngularDamping(b.handle, (*C.dFloat)(&angularDamp[0]))
+	C.NewtonBodySetAngularDamping(b.ptr(), (*C.dFloat)(&angularDamp[0]))
 }
 
 func (b *Body) SetCollision(collision *Collision) {
-	C.NewtonBodySetCollision(b.handle, collision.handle)
+	C.NewtonBodySetCollision(b.ptr(), collision.ptr())
 }
 
 func (b *Body) SetCollisionScale(x, y, z float32) {
-	C.NewtonBodySetCollisionScale(b.handle, C.dFloat(x), C.dFloat(y), C.dFloat(z))
+	C.NewtonBodySetCollisionScale(b.ptr(), C.dFloat(x), C.dFloat(y), C.dFloat(z))
 }
 
 func (b *Body) SleepState() int {
-	return int(C.NewtonBodyGetSleepState(b.handle))
+	return int(C.NewtonBodyGetSleepState(b.ptr()))
 }
 
 func (b *Body) SetSleepState(state int) {
-	C.NewtonBodySetSleepState(b.handle, C.int(state))
+	C.NewtonBodySetSleepState(b.ptr(), C.int(state))
 }
 
 func (b *Body) AutoSleep() int {
-	return int(C.NewtonBodyGetAutoSleep(b.handle))
+	return int(C.NewtonBodyGetAutoSleep(b.ptr()))
 }
 
 func (b *Body) SetAutoSleep(state int) {
-	C.NewtonBodySetAutoSleep(b.handle, C.int(state))
+	C.NewtonBodySetAutoSleep(b.ptr(), C.int(state))
 }
 
 func (b *Body) FreezeState() int {
-	return int(C.NewtonBodyGetFreezeState(b.handle))
+	return int(C.NewtonBodyGetFreezeState(b.ptr()))
 }
 
 func (b *Body) SetFreezeState(state int) {
-	C.NewtonBodySetFreezeState(b.handle, C.int(state))
+	C.NewtonBodySetFreezeState(b.ptr(), C.int(state))
 }
 
-func (b *Body) BodyID() int { return int(C.NewtonBodyGetID(b.handle)) }
+func (b *Body) BodyID() int { return int(C.NewtonBodyGetID(b.ptr())) }
 
 func (b *Body) World() *World {
-	return &World{C.NewtonBodyGetWorld(b.handle)}
+	handle := C.NewtonBodyGetWorld(b.ptr())
+	if cb, ok := worlds.get(owner(handle)); ok && cb.world != nil {
+		return cb.world
+	}
+	return &World{handle}
 }
 
 func (b *Body) Collision() *Collision {
-	return &Collision{handle: C.NewtonBodyGetCollision(b.handle)}
+	return newCollision(owner(C.NewtonBodyGetWorld(b.ptr())), C.NewtonBodyGetCollision(b.ptr()))
 }
 
 func (b *Body) MaterialGroupID() int {
-	return int(C.NewtonBodyGetMaterialGroupID(b.handle))
+	return int(C.NewtonBodyGetMaterialGroupID(b.ptr()))
 }
 
 func (b *Body) ContinuousCollisionMode() int {
-	return int(C.NewtonBodyGetContinuousCollisionMode(b.handle))
+	return int(C.NewtonBodyGetContinuousCollisionMode(b.ptr()))
 }
 
 func (b *Body) JointRecursiveCollision() int {
-	return int(C.NewtonBodyGetJointRecursiveCollision(b.handle))
+	return int(C.NewtonBodyGetJointRecursiveCollision(b.ptr()))
 }
 
 func (b *Body) Matrix(matrix *[16]float32) {
-	C.NewtonBodyGetMatrix(b.handle, (*C.dFloat)(&matrix[0]))
+	C.NewtonBodyGetMatrix(b.ptr(), (*C.dFloat)(&matrix[0]))
 }
 
 //Rotation gets the Quaternion (4) floats
-func (b *Body) Rotation(rotation *[16]float32) {
-	C.NewtonBodyGetRotation(b.handle, (*C.dFloat)(&rotation[0]))
+func (b *Body) Rotation(rotation *[4]float32) {
+	C.NewtonBodyGetRotation(b.ptr(), (*C.dFloat)(&rotation[0]))
+}
+
+//Transform is the value returning version of Matrix
+func (b *Body) Transform() Mat4 {
+	var m Mat4
+	C.NewtonBodyGetMatrix(b.ptr(), (*C.dFloat)(&m[0]))
+	return m
+}
+
+func (b *Body) SetTransform(m Mat4) {
+	C.NewtonBodySetMatrix(b.ptr(), (*C.dFloat)(&m[0]))
+	resetInterpolation(b.handle, m)
+}
+
+func (b *Body) Position() Vec3 {
+	return b.Transform().Position()
+}
+
+//Orientation is the value returning version of Rotation
+func (b *Body) Orientation() Quat {
+	var q Quat
+	C.NewtonBodyGetRotation(b.ptr(), (*C.dFloat)(&q[0]))
+	return q
 }
 
 func (b *Body) MassMatrix(mass, Ixx, Iyy, Izz *float32) {
-	C.NewtonBodyGetMassMatrix(b.handle, (*C.dFloat)(mass), (*C.dFloat)(Ixx),
+	C.NewtonBodyGetMassMatrix(b.ptr(), (*C.dFloat)(mass), (*C.dFloat)(Ixx),
 		(*C.dFloat)(Iyy), (*C.dFloat)(Izz))
 }
 
 func (b *Body) InvMass(invMass, invIxx, invIyy, invIzz *float32) {
-	C.NewtonBodyGetInvMass(b.handle, (*C.dFloat)(invMass), (*C.dFloat)(invIxx),
+	C.NewtonBodyGetInvMass(b.ptr(), (*C.dFloat)(invMass), (*C.dFloat)(invIxx),
 		(*C.dFloat)(invIyy), (*C.dFloat)(invIzz))
 }
 
 func (b *Body) InertiaMatrix(inertiaMatrix *[16]float32) {
-	C.NewtonBodyGetInertiaMatrix(b.handle, (*C.dFloat)(&inertiaMatrix[0]))
+	C.NewtonBodyGetInertiaMatrix(b.ptr(), (*C.dFloat)(&inertiaMatrix[0]))
 }
 
 func (b *Body) InvInertiaMatrix(invInertiaMatrix *[16]float32) {
-	C.NewtonBodyGetInvInertiaMatrix(b.handle, (*C.dFloat)(&invInertiaMatrix[0]))
+	C.NewtonBodyGetInvInertiaMatrix(b.ptr(), (*C.dFloat)(&invInertiaMatrix[0]))
 }
 
 func (b *Body) Omega(vector *[3]float32) {
-	C.NewtonBodyGetOmega(b.handle, (*C.dFloat)(&vector[0]))
+	C.NewtonBodyGetOmega(b.ptr(), (*C.dFloat)(&vector[0]))
 }
 
 func (b *Body) Velocity(vector *[3]float32) {
-	C.NewtonBodyGetVelocity(b.handle, (*C.dFloat)(&vector[0]))
+	C.NewtonBodyGetVelocity(b.ptr(), (*C.dFloat)(&vector[0]))
+}
+
+//LinearVelocity is the value returning version of Velocity
+func (b *Body) LinearVelocity() Vec3 {
+	var v Vec3
+	C.NewtonBodyGetVelocity(b.ptr(), (*C.dFloat)(&v[0]))
+	return v
+}
+
+//AngularVelocity is the value returning version of Omega
+func (b *Body) AngularVelocity() Vec3 {
+	var v Vec3
+	C.NewtonBodyGetOmega(b.ptr(), (*C.dFloat)(&v[0]))
+	return v
 }
 
 func (b *Body) Force(vector *[3]float32) {
-	C.NewtonBodyGetForce(b.handle, (*C.dFloat)(&vector[0]))
+	C.NewtonBodyGetForce(b.ptr(), (*C.dFloat)(&vector[0]))
 }
 
 func (b *Body) Torque(vector *[3]float32) {
-	C.NewtonBodyGetTorque(b.handle, (*C.dFloat)(&vector[0]))
+	C.NewtonBodyGetTorque(b.ptr(), (*C.dFloat)(&vector[0]))
 }
 
 func (b *Body) ForceAcc(vector *[3]float32) {
-	C.NewtonBodyGetForceAcc(b.handle, (*C.dFloat)(&vector[0]))
+	C.NewtonBodyGetForceAcc(b.ptr(), (*C.dFloat)(&vector[0]))
 }
 
 func (b *Body) TorqueAcc(vector *[3]float32) {
-	C.NewtonBodyGetTorqueAcc(b.handle, (*C.dFloat)(&vector[0]))
+	C.NewtonBodyGetTorqueAcc(b.ptr(), (*C.dFloat)(&vector[0]))
 }
 
 func (b *Body) CentreOfMass(com *[3]float32) {
-	C.NewtonBodyGetCentreOfMass(b.handle, (*C.dFloat)(&com[0]))
+	C.NewtonBodyGetCentreOfMass(b.ptr(), (*C.dFloat)(&com[0]))
 }
 
 func (b *Body) AddImpulse(pointDeltaVeloc, pointPosit *[3]float32) {
-	C.NewtonBodyAddImpulse(b.handle, (*C.dFloat)(&pointDeltaVeloc[0]), (*C.dFloat)(&pointPosit[0]))
+	C.NewtonBodyAddImpulse(b.ptr(), (*C.dFloat)(&pointDeltaVeloc[0]), (*C.dFloat)(&pointPosit[0]))
 }
 
 func (b *Body) ApplyImpulsePair(linearImpulse, angularImpulse *[3]float32) {
-	C.NewtonBodyApplyImpulsePair(b.handle, (*C.dFloat)(&linearImpulse[0]), (*C.dFloat)(&angularImpulse[0]))
+	C.NewtonBodyApplyImpulsePair(b.ptr(), (*C.dFloat)(&linearImpulse[0]), (*C.dFloat)(&angularImpulse[0]))
 }
 
 func (b *Body) IntegrateVelocity(timestep float32) {
-	C.NewtonBodyIntegrateVelocity(b.handle, C.dFloat(timestep))
+	C.NewtonBodyIntegrateVelocity(b.ptr(), C.dFloat(timestep))
 }
 
 func (b *Body) LinearDamping() float32 {
-	return float32(C.NewtonBodyGetLinearDamping(b.handle))
+	return float32(C.NewtonBodyGetLinearDamping(b.ptr()))
 }
 
 func (b *Body) AngularDamping(result *[3]float32) {
-	C.NewtonBodyGetAngularDamping(b.handle, (*C.dFloat)(&result[0]))
+	C.NewtonBodyGetAngularDamping(b.ptr(), (*C.dFloat)(&result[0]))
 }
 
 func (b *Body) AABB(p0, p1 *[3]float32) {
-	C.NewtonBodyGetAABB(b.handle, (*C.dFloat)(&p0[0]), (*C.dFloat)(&p1[0]))
+	C.NewtonBodyGetAABB(b.ptr(), (*C.dFloat)(&p0[0]), (*C.dFloat)(&p1[0]))
 }
 
+//The joint and contact joint lists return nil when there are no more joints
+
 func (b *Body) FirstJoint() *Joint {
-	return &Joint{C.NewtonBodyGetFirstJoint(b.handle)}
+	return newJoint(C.NewtonBodyGetFirstJoint(b.ptr()))
 }
 
 func (b *Body) NextJoint(curJoint *Joint) *Joint {
-	return &Joint{C.NewtonBodyGetNextJoint(b.handle, curJoint.handle)}
+	return newJoint(C.NewtonBodyGetNextJoint(b.ptr(), curJoint.ptr()))
 }
 
 func (b *Body) FirstContactJoint() *Joint {
-	return &Joint{C.NewtonBodyGetFirstContactJoint(b.handle)}
+	return newContactJoint(C.NewtonBodyGetFirstContactJoint(b.ptr()))
 }
 
 func (b *Body) NextContactJoint(curJoint *Joint) *Joint {
-	return &Joint{C.NewtonBodyGetNextContactJoint(b.handle, curJoint.handle)}
+	return newContactJoint(C.NewtonBodyGetNextContactJoint(b.ptr(), curJoint.ptr()))
+}
+
+//Joints iterates over every joint attached to the body
+func (b *Body) Joints() iter.Seq[*Joint] {
+	return func(yield func(*Joint) bool) {
+		for joint := b.FirstJoint(); joint != nil; {
+			next := b.NextJoint(joint)
+			if !yield(joint) {
+				return
+			}
+			joint = next
+		}
+	}
+}
+
+//ContactJoints iterates over every contact joint the body is part of
+func (b *Body) ContactJoints() iter.Seq[*Joint] {
+	return func(yield func(*Joint) bool) {
+		for joint := b.FirstContactJoint(); joint != nil; {
+			next := b.NextContactJoint(joint)
+			if !yield(joint) {
+				return
+			}
+			joint = next
+		}
+	}
 }
 
 func (b *Body) UserData() interface{} {
-	return ownerData[owner(b.handle)]
-	//return (interface{})(C.NewtonBodyGetUserData(b.handle))
+	data, _ := ownerData.get(owner(b.handle))
+	return data
+	//return (interface{})(C.NewtonBodyGetUserData(b.ptr()))
 }
 
+//SetUserData sets the body's user data, it's released when the body is destroyed
 func (b *Body) SetUserData(userData interface{}) {
-	//C.NewtonBodySetUserData(b.handle, unsafe.Pointer(&userData))
-	ownerData[owner(b.handle)] = userData
+	//C.NewtonBodySetUserData(b.ptr(), unsafe.Pointer(&userData))
+	ownerData.set(owner(b.ptr()), userData)
 }
@@ -0,0 +1,288 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrOBJFormat = errors.New("newton: invalid obj")
+
+//OBJ is the geometry from a Wavefront OBJ file
+type OBJ struct {
+	Positions []Vec3
+	Normals   []Vec3
+	UVs       [][2]float32
+	Faces     []OBJFace
+
+	Materials []string //names from usemtl, OBJFace.Material indexes into it
+	Objects   []string //names from o
+	Groups    []string //names from g
+}
+
+//OBJFace is a single polygon.  Indexes are 0 based, and Normal and UV are -1
+// for corners that don't have one
+type OBJFace struct {
+	Position []int
+	Normal   []int
+	UV       []int
+	Material int
+	Object   int
+	Group    int
+}
+
+//ParseOBJ reads the positions, normals, texture coordinates and faces of every object
+// and group in an OBJ file.  Faces before any usemtl, o or g statement use a material,
+// object or group named ""
+func ParseOBJ(r io.Reader) (*OBJ, error) {
+	o := &OBJ{}
+	material, object, group := -1, -1, -1
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		var err error
+		switch fields[0] {
+		case "v":
+			var v Vec3
+			v, err = parseOBJVec3(fields[1:])
+			o.Positions = append(o.Positions, v)
+		case "vn":
+			var v Vec3
+			v, err = parseOBJVec3(fields[1:])
+			o.Normals = append(o.Normals, v)
+		case "vt":
+			var uv [2]float32
+			uv, err = parseOBJUV(fields[1:])
+			o.UVs = append(o.UVs, uv)
+		case "f":
+			var face OBJFace
+			face, err = o.parseFace(fields[1:])
+			if material < 0 {
+				material = o.material("")
+			}
+			if object < 0 {
+				object = o.object("")
+			}
+			if group < 0 {
+				group = o.group("")
+			}
+			face.Material, face.Object, face.Group = material, object, group
+			o.Faces = append(o.Faces, face)
+		case "usemtl":
+			material = o.material(strings.Join(fields[1:], " "))
+		case "o":
+			object = o.object(strings.Join(fields[1:], " "))
+		case "g":
+			group = o.group(strings.Join(fields[1:], " "))
+		}
+		//anything else, such as mtllib, smoothing groups, lines and points, doesn't
+		// affect collision geometry
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrOBJFormat, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func parseOBJFloats(fields []string, min int, values []float32) error {
+	if len(fields) < min {
+		return fmt.Errorf("expected %d values, got %d", min, len(fields))
+	}
+	for i := range values {
+		if i >= len(fields) {
+			break
+		}
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return err
+		}
+		values[i] = float32(f)
+	}
+	return nil
+}
+
+func parseOBJVec3(fields []string) (Vec3, error) {
+	var v Vec3
+	err := parseOBJFloats(fields, 3, v[:])
+	return v, err
+}
+
+func parseOBJUV(fields []string) ([2]float32, error) {
+	var uv [2]float32
+	err := parseOBJFloats(fields, 1, uv[:])
+	return uv, err
+}
+
+//parseFace parses the v, v/vt, v//vn and v/vt/vn corners of a face
+func (o *OBJ) parseFace(fields []string) (OBJFace, error) {
+	face := OBJFace{}
+	if len(fields) < 3 {
+		return face, fmt.Errorf("face has %d corners, needs at least 3", len(fields))
+	}
+
+	for _, corner := range fields {
+		parts := strings.Split(corner, "/")
+		if len(parts) > 3 {
+			return face, fmt.Errorf("invalid face corner %q", corner)
+		}
+
+		position, err := objIndex(parts[0], len(o.Positions))
+		if err != nil {
+			return face, err
+		}
+		uv, normal := -1, -1
+		if len(parts) > 1 && parts[1] != "" {
+			if uv, err = objIndex(parts[1], len(o.UVs)); err != nil {
+				return face, err
+			}
+		}
+		if len(parts) > 2 && parts[2] != "" {
+			if normal, err = objIndex(parts[2], len(o.Normals)); err != nil {
+				return face, err
+			}
+		}
+
+		face.Position = append(face.Position, position)
+		face.UV = append(face.UV, uv)
+		face.Normal = append(face.Normal, normal)
+	}
+	return face, nil
+}
+
+//objIndex converts a 1 based, or negative relative, OBJ index to a 0 based one
+func objIndex(field string, count int) (int, error) {
+	i, err := strconv.Atoi(field)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		i += count
+	} else {
+		i--
+	}
+	if i < 0 || i >= count {
+		return 0, fmt.Errorf("index %s out of range", field)
+	}
+	return i, nil
+}
+
+func objName(names *[]string, name string) int {
+	for i := range *names {
+		if (*names)[i] == name {
+			return i
+		}
+	}
+	*names = append(*names, name)
+	return len(*names) - 1
+}
+
+func (o *OBJ) material(name string) int { return objName(&o.Materials, name) }
+func (o *OBJ) object(name string) int   { return objName(&o.Objects, name) }
+func (o *OBJ) group(name string) int    { return objName(&o.Groups, name) }
+
+//Select returns only the faces belonging to the object or group with the given name
+func (o *OBJ) Select(name string) *OBJ {
+	selected := *o
+	selected.Faces = nil
+	for _, face := range o.Faces {
+		if o.Objects[face.Object] == name || o.Groups[face.Group] == name {
+			selected.Faces = append(selected.Faces, face)
+		}
+	}
+	return &selected
+}
+
+//CreateMeshFromOBJ builds a mesh from the OBJ's faces, the usemtl of each face is
+// its material index.  Corners without a normal or texture coordinate get a
+// zero normal and uv
+func (w *World) CreateMeshFromOBJ(o *OBJ) (*Mesh, error) {
+	if len(o.Faces) == 0 {
+		return nil, ErrNoVertices
+	}
+
+	//index 0 is the default for corners without one
+	normals := []float32{0, 0, 0}
+	for _, n := range o.Normals {
+		normals = append(normals, n[:]...)
+	}
+	uvs := []float32{0, 0}
+	for _, uv := range o.UVs {
+		uvs = append(uvs, uv[:]...)
+	}
+	positions := make([]float32, 0, len(o.Positions)*3)
+	for _, p := range o.Positions {
+		positions = append(positions, p[:]...)
+	}
+
+	faceIndexCount := make([]int, len(o.Faces))
+	faceMaterial := make([]int, len(o.Faces))
+	var positionIndex, normalIndex, uvIndex []int
+	for i, face := range o.Faces {
+		faceIndexCount[i] = len(face.Position)
+		faceMaterial[i] = face.Material
+		for c := range face.Position {
+			positionIndex = append(positionIndex, face.Position[c])
+			normalIndex = append(normalIndex, face.Normal[c]+1)
+			uvIndex = append(uvIndex, face.UV[c]+1)
+		}
+	}
+
+	mesh, err := w.CreateMesh()
+	if err != nil {
+		return nil, err
+	}
+	mesh.BuildFromVertexListIndexList(len(o.Faces), faceIndexCount, faceMaterial,
+		positions, 12, positionIndex,
+		normals, 12, normalIndex,
+		uvs, 8, uvIndex,
+		uvs, 8, uvIndex)
+	return mesh, nil
+}
+
+//LoadOBJ reads an OBJ file into a new mesh, see ParseOBJ and CreateMeshFromOBJ
+func (w *World) LoadOBJ(r io.Reader) (*Mesh, error) {
+	o, err := ParseOBJ(r)
+	if err != nil {
+		return nil, err
+	}
+	return w.CreateMeshFromOBJ(o)
+}
+
+//LoadOBJTreeCollision reads an OBJ file into a static tree collision, such as for
+// level geometry.  Each face's material index is its face attribute
+func (w *World) LoadOBJTreeCollision(r io.Reader, shapeID int) (*Collision, error) {
+	mesh, err := w.LoadOBJ(r)
+	if err != nil {
+		return nil, err
+	}
+	defer mesh.Destroy()
+	return w.CreateTreeCollsionFromMesh(mesh, shapeID)
+}
+
+//LoadOBJConvexHull reads an OBJ file into the convex hull around all of its vertices
+func (w *World) LoadOBJConvexHull(r io.Reader, tolerance float32, shapeID int) (*Collision, error) {
+	mesh, err := w.LoadOBJ(r)
+	if err != nil {
+		return nil, err
+	}
+	defer mesh.Destroy()
+	return w.CreateConvexHullFromMesh(mesh, tolerance, shapeID)
+}
@@ -0,0 +1,185 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOBJ(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  string
+		want *OBJ
+	}{
+		{
+			name: "triangle",
+			obj: `# a single triangle
+v 0 0 0
+v 1 0 0
+v 0 1 0
+f 1 2 3
+`,
+			want: &OBJ{
+				Positions: []Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+				Faces: []OBJFace{
+					{Position: []int{0, 1, 2}, Normal: []int{-1, -1, -1}, UV: []int{-1, -1, -1}},
+				},
+				Materials: []string{""},
+				Objects:   []string{""},
+				Groups:    []string{""},
+			},
+		},
+		{
+			name: "corner forms",
+			obj: `v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vt 0 0
+vt 1
+vn 0 0 1
+f 1/1 2/2 3/1/1 4//1
+`,
+			want: &OBJ{
+				Positions: []Vec3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}},
+				Normals:   []Vec3{{0, 0, 1}},
+				UVs:       [][2]float32{{0, 0}, {1, 0}},
+				Faces: []OBJFace{
+					{Position: []int{0, 1, 2, 3}, Normal: []int{-1, -1, 0, 0}, UV: []int{0, 1, 0, -1}},
+				},
+				Materials: []string{""},
+				Objects:   []string{""},
+				Groups:    []string{""},
+			},
+		},
+		{
+			name: "relative indexes",
+			obj: `v 0 0 0
+v 1 0 0
+v 0 1 0
+f -3 -2 -1
+v 0 0 1
+f -4 -3 -1
+`,
+			want: &OBJ{
+				Positions: []Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}},
+				Faces: []OBJFace{
+					{Position: []int{0, 1, 2}, Normal: []int{-1, -1, -1}, UV: []int{-1, -1, -1}},
+					{Position: []int{0, 1, 3}, Normal: []int{-1, -1, -1}, UV: []int{-1, -1, -1}},
+				},
+				Materials: []string{""},
+				Objects:   []string{""},
+				Groups:    []string{""},
+			},
+		},
+		{
+			name: "materials objects and groups",
+			obj: `mtllib scene.mtl
+v 0 0 0
+v 1 0 0
+v 0 1 0
+o floor
+usemtl stone tiles
+f 1 2 3
+g top
+usemtl grass
+s 1
+f 3 2 1
+o wall
+f 1 3 2
+`,
+			want: &OBJ{
+				Positions: []Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+				Faces: []OBJFace{
+					{Position: []int{0, 1, 2}, Normal: []int{-1, -1, -1}, UV: []int{-1, -1, -1},
+						Material: 0, Object: 0, Group: 0},
+					{Position: []int{2, 1, 0}, Normal: []int{-1, -1, -1}, UV: []int{-1, -1, -1},
+						Material: 1, Object: 0, Group: 1},
+					{Position: []int{0, 2, 1}, Normal: []int{-1, -1, -1}, UV: []int{-1, -1, -1},
+						Material: 1, Object: 1, Group: 1},
+				},
+				Materials: []string{"stone tiles", "grass"},
+				Objects:   []string{"floor", "wall"},
+				Groups:    []string{"", "top"},
+			},
+		},
+		{
+			name: "empty",
+			obj:  "# nothing but a comment\n\n",
+			want: &OBJ{},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := ParseOBJ(strings.NewReader(test.obj))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseOBJErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  string
+	}{
+		{"short vertex", "v 0 0\n"},
+		{"bad vertex", "v 0 x 0\n"},
+		{"short normal", "vn 0 1\n"},
+		{"no uv", "vt\n"},
+		{"two corners", "v 0 0 0\nv 1 0 0\nf 1 2\n"},
+		{"index out of range", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 4\n"},
+		{"zero index", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 0 1 2\n"},
+		{"relative out of range", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf -4 -2 -1\n"},
+		{"missing uv", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1/1 2/1 3/1\n"},
+		{"missing normal", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1//1 2//1 3//1\n"},
+		{"too many slashes", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1/1/1/1 2 3\n"},
+		{"bad index", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 two 3\n"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseOBJ(strings.NewReader(test.obj))
+		if !errors.Is(err, ErrOBJFormat) {
+			t.Errorf("%s: got error %v, want ErrOBJFormat", test.name, err)
+		}
+	}
+}
+
+func TestOBJSelect(t *testing.T) {
+	o, err := ParseOBJ(strings.NewReader(`v 0 0 0
+v 1 0 0
+v 0 1 0
+o floor
+f 1 2 3
+o wall
+g trim
+f 3 2 1
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		faces int
+	}{
+		{"floor", 1},
+		{"wall", 1},
+		{"trim", 1},
+		{"roof", 0},
+	}
+	for _, test := range tests {
+		if got := len(o.Select(test.name).Faces); got != test.faces {
+			t.Errorf("Select(%q) has %d faces, want %d", test.name, got, test.faces)
+		}
+	}
+}
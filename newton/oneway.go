@@ -0,0 +1,175 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import "sync"
+
+//One way platforms
+// A one way body only collides with bodies landing on its front, the side its up
+// direction points out of.  When a body first touches the platform, it's checked
+// for landing: moving towards the front, with every contact facing up or down and
+// below the body's origin.  If it isn't landing, all of its contacts are removed
+// until it stops touching the platform, so bodies jumping up through it, or that
+// start inside it, pass all the way through instead of being pushed out.
+
+//oneWayTolerance is the speed away from the front, and the cosine of the angle
+// between a contact normal and the up direction, still counted as landing
+const oneWayTolerance = 0.5
+
+//oneWayPlatform is a one way body and the bodies touching it
+type oneWayPlatform struct {
+	up    Vec3
+	local bool
+
+	sync.Mutex
+	touching map[*Body]*oneWayContact
+}
+
+//oneWayContact is the state of a body touching a platform
+type oneWayContact struct {
+	passing bool   //contacts are removed until it stops touching
+	update  uint64 //the last update it touched the platform in
+}
+
+//SetOneWay makes the body a one way platform, which bodies only collide with when
+// they land on it from the side up points out of, in world space.  A zero up makes it
+// solid from every side again
+func (b *Body) SetOneWay(up Vec3) {
+	b.setOneWay(up, false)
+}
+
+//SetLocalOneWay is SetOneWay with up in the body's space, so it turns with the body
+func (b *Body) SetLocalOneWay(up Vec3) {
+	b.setOneWay(up, true)
+}
+
+func (b *Body) setOneWay(up Vec3, local bool) {
+	w := b.World()
+	if up != (Vec3{}) {
+		w.installPairCallbacks()
+	}
+
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	if up == (Vec3{}) {
+		delete(cb.oneWay, b)
+		return
+	}
+	if cb.oneWay == nil {
+		cb.oneWay = make(map[*Body]*oneWayPlatform)
+	}
+	cb.oneWay[b] = &oneWayPlatform{
+		up:       up.Normalize(),
+		local:    local,
+		touching: make(map[*Body]*oneWayContact),
+	}
+}
+
+//OneWay returns the body's up direction in world space, false if it isn't one way
+func (b *Body) OneWay() (Vec3, bool) {
+	if b.handle == nil {
+		return Vec3{}, false
+	}
+	cb := b.World().callbacks()
+	cb.RLock()
+	p, ok := cb.oneWay[b]
+	cb.RUnlock()
+	if !ok {
+		return Vec3{}, false
+	}
+	return p.worldUp(b), true
+}
+
+func (p *oneWayPlatform) worldUp(body *Body) Vec3 {
+	if p.local {
+		return body.Transform().RotateVector(p.up)
+	}
+	return p.up
+}
+
+//applyOneWay removes the contacts of the joint if one of its bodies is one of the
+// world's one way platforms, and the other isn't landing on it
+func (w *World) applyOneWay(contact *Joint) {
+	cb, ok := worlds.get(owner(w.handle))
+	if !ok {
+		return
+	}
+
+	body0, body1 := contact.Body0(), contact.Body1()
+	platform, other := body0, body1
+	cb.RLock()
+	p, ok := cb.oneWay[body0]
+	if !ok {
+		platform, other = body1, body0
+		p, ok = cb.oneWay[body1]
+	}
+	update := cb.updates
+	cb.RUnlock()
+	if !ok {
+		return
+	}
+
+	p.Lock()
+	c, ok := p.touching[other]
+	if !ok || c.update+1 < update {
+		//a new contact, or one that stopped touching for an update
+		c = &oneWayContact{passing: !landing(contact, platform, other, p.worldUp(platform))}
+		p.touching[other] = c
+	}
+	c.update = update
+	passing := c.passing
+	p.Unlock()
+
+	if passing {
+		for each := range contact.Contacts() {
+			contact.RemoveContact(each)
+		}
+	}
+}
+
+//landing is whether other is landing on the front of the platform
+func landing(contact *Joint, platform, other *Body, up Vec3) bool {
+	if other.LinearVelocity().Sub(platform.LinearVelocity()).Dot(up) > oneWayTolerance {
+		return false
+	}
+
+	origin := other.Position()
+	for each := range contact.Contacts() {
+		var position, normal [3]float32
+		each.Material().ContactPositionAndNormal(platform, &position, &normal)
+		facing := Vec3(normal).Dot(up)
+		if facing < oneWayTolerance && facing > -oneWayTolerance {
+			return false
+		}
+		if origin.Sub(position).Dot(up) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+//updateOneWay counts the update, and forgets the bodies that stopped touching the
+// world's platforms
+func (w *World) updateOneWay() {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	cb.updates++
+	update := cb.updates
+
+	for platform, p := range cb.oneWay {
+		if platform.handle == nil {
+			delete(cb.oneWay, platform)
+			continue
+		}
+		p.Lock()
+		for body, c := range p.touching {
+			if c.update+1 < update || body.handle == nil {
+				delete(p.touching, body)
+			}
+		}
+		p.Unlock()
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"math"
+	"testing"
+)
+
+//dropOnPlatform creates a static one way platform, 0.2 thick with its top at 0.1, and
+// a box with its centre at height, then runs steps updates under gravity and returns
+// the box
+func dropOnPlatform(t *testing.T, height float32, steps int) *Body {
+	t.Helper()
+
+	w, err := CreateWorld()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.Destroy)
+
+	platformShape, err := w.CreateBox(4, 0.2, 4, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer platformShape.Destroy()
+	m := Identity()
+	platform, err := w.CreateDynamicBody(platformShape, (*[16]float32)(&m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform.SetOneWay(Vec3{0, 1, 0})
+
+	boxShape, err := w.CreateBox(1, 1, 1, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boxShape.Destroy()
+	m = TranslationMatrix(Vec3{0, height, 0})
+	box, err := w.CreateDynamicBody(boxShape, (*[16]float32)(&m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	box.SetMassMatrix(1, 1, 1, 1)
+	box.SetForceAndTorqueCallback(func(body *Body, timestep float32, threadIndex int) {
+		force := [3]float32{0, -10, 0}
+		body.SetForce(&force)
+	})
+
+	for i := 0; i < steps; i++ {
+		w.Update(testTimestep)
+	}
+	return box
+}
+
+func TestOneWayStartInside(t *testing.T) {
+	//a second of falling from inside the platform, without it pushing the box out
+	box := dropOnPlatform(t, 0, 60)
+
+	position, velocity := box.Position(), box.LinearVelocity()
+	if position[1] > -4 {
+		t.Errorf("box is at %v, want it to have fallen through the platform", position)
+	}
+	if math.Abs(float64(position[0])) > 1e-3 || math.Abs(float64(position[2])) > 1e-3 {
+		t.Errorf("box is at %v, want it to have fallen straight down", position)
+	}
+	if velocity[1] > -9 {
+		t.Errorf("box velocity is %v, want it falling freely", velocity)
+	}
+}
+
+func TestOneWayLanding(t *testing.T) {
+	box := dropOnPlatform(t, 2, 120)
+
+	position, velocity := box.Position(), box.LinearVelocity()
+	if math.Abs(float64(position[1]-0.6)) > 0.05 {
+		t.Errorf("box is at %v, want it resting on the platform at 0.6", position)
+	}
+	if velocity.Len() > 0.1 {
+		t.Errorf("box velocity is %v, want it at rest", velocity)
+	}
+}
@@ -7,7 +7,11 @@ package newton
 #include <stdlib.h>
 */
 import "C"
-import "unsafe"
+import (
+	"fmt"
+	"iter"
+	"unsafe"
+)
 
 const (
 	CollisionSphere = iota
@@ -28,88 +32,174 @@ const (
 
 type Collision struct {
 	handle *C.NewtonCollision
+	world  owner //the world the collision belongs to, if it's known
+	res    *resource
+}
+
+func (c *Collision) ptr() *C.NewtonCollision {
+	if c == nil || c.handle == nil || !c.res.valid() {
+		panic(fmt.Sprintf(handlePanic, "Collision"))
+	}
+	return c.handle
+}
+
+//newCollision wraps the handle, or returns nil if there isn't one.  world may be nil
+// if it isn't known
+func newCollision(world owner, handle *C.NewtonCollision) *Collision {
+	if handle == nil {
+		return nil
+	}
+	return &Collision{handle: handle, world: world}
+}
+
+//createdCollision returns an error if Newton couldn't create the collision,
+// otherwise the collision is tracked by world
+func createdCollision(world owner, handle *C.NewtonCollision, what string) (*Collision, error) {
+	if handle == nil {
+		return nil, createError(what)
+	}
+	c := &Collision{handle: handle, world: world}
+	c.res = track(world, "Collision", func() { C.NewtonDestroyCollision(handle) })
+	autoRelease(c, c.res)
+	return c, nil
 }
 
 type Mesh struct {
 	handle *C.NewtonMesh
+	res    *resource
+}
+
+func (m *Mesh) ptr() *C.NewtonMesh {
+	if m == nil || m.handle == nil || !m.res.valid() {
+		panic(fmt.Sprintf(handlePanic, "Mesh"))
+	}
+	return m.handle
+}
+
+//newMesh wraps the handle, or returns nil if there isn't one
+func newMesh(handle *C.NewtonMesh) *Mesh {
+	if handle == nil {
+		return nil
+	}
+	return &Mesh{handle: handle}
+}
+
+//createdMesh returns an error if Newton couldn't create the mesh, otherwise the
+// mesh is tracked by world
+func createdMesh(world owner, handle *C.NewtonMesh, what string) (*Mesh, error) {
+	if handle == nil {
+		return nil, createError(what)
+	}
+	m := &Mesh{handle: handle}
+	m.res = track(world, "Mesh", func() { C.NewtonMeshDestroy(handle) })
+	autoRelease(m, m.res)
+	return m, nil
+}
+
+//validVertices returns an error if the vertex slice doesn't hold count vertices
+func validVertices(count int, vertices []float32, strideInBytes int) error {
+	if count <= 0 || len(vertices) == 0 || len(vertices)*4 < (count-1)*strideInBytes+12 {
+		return ErrNoVertices
+	}
+	return nil
 }
 
 // *** Primitive Creation Methods
-func (w *World) CreateNull() *Collision {
-	collision := &Collision{handle: C.NewtonCreateNull(w.handle)}
-	return collision
+// A nil offsetMatrix is the same as the identity matrix
+
+func (w *World) CreateNull() (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateNull(w.ptr()), "null collision")
 }
-func (w *World) CreateSphere(radius float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateSphere(w.handle, C.dFloat(radius), C.int(shapeID),
-		(*C.dFloat)(&offsetMatrix[0]))}
+
+func (w *World) CreateSphere(radius float32, shapeID int, offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateSphere(w.ptr(), C.dFloat(radius), C.int(shapeID),
+		matrixPtr(offsetMatrix)), "sphere")
 }
 
-func (w *World) CreateBox(dx, dy, dz float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateBox(w.handle, C.dFloat(dx), C.dFloat(dy), C.dFloat(dz), C.int(shapeID),
-		(*C.dFloat)(&offsetMatrix[0]))}
+func (w *World) CreateBox(dx, dy, dz float32, shapeID int, offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateBox(w.ptr(), C.dFloat(dx), C.dFloat(dy), C.dFloat(dz), C.int(shapeID),
+		matrixPtr(offsetMatrix)), "box")
 }
 
-func (w *World) CreateCone(radius, height float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateCone(w.handle, C.dFloat(radius), C.dFloat(height), C.int(shapeID),
-		(*C.dFloat)(&offsetMatrix[0]))}
+func (w *World) CreateCone(radius, height float32, shapeID int, offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateCone(w.ptr(), C.dFloat(radius), C.dFloat(height), C.int(shapeID),
+		matrixPtr(offsetMatrix)), "cone")
 }
 
-func (w *World) CreateCapsule(radius, height float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateCapsule(w.handle, C.dFloat(radius), C.dFloat(height), C.int(shapeID),
-		(*C.dFloat)(&offsetMatrix[0]))}
+func (w *World) CreateCapsule(radius, height float32, shapeID int, offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateCapsule(w.ptr(), C.dFloat(radius), C.dFloat(height), C.int(shapeID),
+		matrixPtr(offsetMatrix)), "capsule")
 }
 
-func (w *World) CreateCylinder(radius, height float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateCylinder(w.handle, C.dFloat(radius), C.dFloat(height), C.int(shapeID),
-		(*C.dFloat)(&offsetMatrix[0]))}
+func (w *World) CreateCylinder(radius, height float32, shapeID int, offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateCylinder(w.ptr(), C.dFloat(radius), C.dFloat(height), C.int(shapeID),
+		matrixPtr(offsetMatrix)), "cylinder")
 }
 
-func (w *World) CreateTaperedCapsule(radio0, radio1, height float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateTaperedCapsule(w.handle, C.dFloat(radio0), C.dFloat(radio1),
-		C.dFloat(height), C.int(shapeID), (*C.dFloat)(&offsetMatrix[0]))}
+func (w *World) CreateTaperedCapsule(radio0, radio1, height float32, shapeID int,
+	offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateTaperedCapsule(w.ptr(), C.dFloat(radio0), C.dFloat(radio1),
+		C.dFloat(height), C.int(shapeID), matrixPtr(offsetMatrix)), "tapered capsule")
 }
 
-func (w *World) CreateTaperedCylinder(radio0, radio1, height float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateTaperedCylinder(w.handle, C.dFloat(radio0), C.dFloat(radio1),
-		C.dFloat(height), C.int(shapeID), (*C.dFloat)(&offsetMatrix[0]))}
+func (w *World) CreateTaperedCylinder(radio0, radio1, height float32, shapeID int,
+	offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateTaperedCylinder(w.ptr(), C.dFloat(radio0), C.dFloat(radio1),
+		C.dFloat(height), C.int(shapeID), matrixPtr(offsetMatrix)), "tapered cylinder")
 }
 
-func (w *World) CreateChamferCylinder(radius, height float32, shapeID int, offsetMatrix *[16]float32) *Collision {
-	return &Collision{handle: C.NewtonCreateChamferCylinder(w.handle, C.dFloat(radius), C.dFloat(height),
-		C.int(shapeID), (*C.dFloat)(&offsetMatrix[0]))}
+func (w *World) CreateChamferCylinder(radius, height float32, shapeID int, offsetMatrix *[16]float32) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateChamferCylinder(w.ptr(), C.dFloat(radius), C.dFloat(height),
+		C.int(shapeID), matrixPtr(offsetMatrix)), "chamfer cylinder")
 }
 
+//CreateConvexHull returns an error if there aren't count vertices in vertexCloud, or if
+// Newton can't build a hull from them, such as when they're all on one plane
 func (w *World) CreateConvexHull(count int, vertexCloud []float32, strideInBytes int, tolerance float32, shapeID int,
-	offsetMatrix []float32) *Collision {
-	return &Collision{handle: C.NewtonCreateConvexHull(w.handle, C.int(count), (*C.dFloat)(&vertexCloud[0]),
-		C.int(strideInBytes), C.dFloat(tolerance), C.int(shapeID), (*C.dFloat)(&offsetMatrix[0]))}
+	offsetMatrix []float32) (*Collision, error) {
+	if err := validVertices(count, vertexCloud, strideInBytes); err != nil {
+		return nil, err
+	}
+
+	var cOffsetMatrix *C.dFloat
+	if len(offsetMatrix) >= 16 {
+		cOffsetMatrix = (*C.dFloat)(&offsetMatrix[0])
+	}
+
+	return createdCollision(owner(w.handle), C.NewtonCreateConvexHull(w.ptr(), C.int(count), (*C.dFloat)(&vertexCloud[0]),
+		C.int(strideInBytes), C.dFloat(tolerance), C.int(shapeID), cOffsetMatrix), "convex hull")
 }
 
-func (w *World) CreateConvexHullFromMesh(mesh *Mesh, tolerance float32, shapeID int) *Collision {
-	return &Collision{handle: C.NewtonCreateConvexHullFromMesh(w.handle, mesh.handle, C.dFloat(tolerance),
-		C.int(shapeID))}
+func (w *World) CreateConvexHullFromMesh(mesh *Mesh, tolerance float32, shapeID int) (*Collision, error) {
+	if mesh == nil {
+		return nil, ErrNilMesh
+	}
+	return createdCollision(owner(w.handle), C.NewtonCreateConvexHullFromMesh(w.ptr(), mesh.ptr(), C.dFloat(tolerance),
+		C.int(shapeID)), "convex hull")
 }
 
 // Primitive typed methods
 
-//func (c *Collision) IsTriggerVolume() bool {
-//return gbool[int(C.NewtonCollisionIsTriggerVolume(c.handle))]
-//}
+//IsTriggerVolume is whether the collision only reports overlaps.  Bodies pass through
+// trigger volumes, see World.OnTriggerEnter for events when they do
+func (c *Collision) IsTriggerVolume() bool {
+	return gbool[int(C.NewtonCollisionIsTriggerVolume(c.ptr()))]
+}
 
-//func (c *Collision) SetIsTriggerVolume(value bool) {
-//C.NewtonCollisionSetAsTriggerVolume(c.handle, cint[value])
-//}
+func (c *Collision) SetIsTriggerVolume(value bool) {
+	C.NewtonCollisionSetAsTriggerVolume(c.ptr(), cint[value])
+}
 
 func (c *Collision) FaceIndices(face int, faceIndices []int) int {
-	return int(C.NewtonConvexHullGetFaceIndices(c.handle, C.int(face), (*C.int)(unsafe.Pointer(&faceIndices[0]))))
+	return int(C.NewtonConvexHullGetFaceIndices(c.ptr(), C.int(face), (*C.int)(unsafe.Pointer(&faceIndices[0]))))
 }
 
 func (c *Collision) CalculateVolume() float32 {
-	return float32(C.NewtonConvexCollisionCalculateVolume(c.handle))
+	return float32(C.NewtonConvexCollisionCalculateVolume(c.ptr()))
 }
 
 func (c *Collision) CalculateInertialMatrix(intertia, origin *[3]float32) {
-	C.NewtonConvexCollisionCalculateInertialMatrix(c.handle, (*C.dFloat)(&intertia[0]),
+	C.NewtonConvexCollisionCalculateInertialMatrix(c.ptr(), (*C.dFloat)(&intertia[0]),
 		(*C.dFloat)(&origin[0]))
 }
 
@@ -117,130 +207,174 @@ type Node struct {
 	handle unsafe.Pointer
 }
 
+//newNode wraps the handle, or returns nil if there isn't one
+func newNode(handle unsafe.Pointer) *Node {
+	if handle == nil {
+		return nil
+	}
+	return &Node{handle}
+}
+
 //Compound Collisions
-func (w *World) CreateCompoundCollision(shapeID int) *Collision {
-	return &Collision{handle: C.NewtonCreateCompoundCollision(w.handle, C.int(shapeID))}
+func (w *World) CreateCompoundCollision(shapeID int) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateCompoundCollision(w.ptr(), C.int(shapeID)), "compound collision")
 }
 
 func (w *World) CreateCompoundCollisionFromMesh(mesh *Mesh, hullTolerance float32, shapeID,
-	subShapeID int) *Collision {
-	return &Collision{handle: C.NewtonCreateCompoundCollisionFromMesh(w.handle, mesh.handle,
-		C.dFloat(hullTolerance), C.int(shapeID), C.int(subShapeID))}
+	subShapeID int) (*Collision, error) {
+	if mesh == nil {
+		return nil, ErrNilMesh
+	}
+	return createdCollision(owner(w.handle), C.NewtonCreateCompoundCollisionFromMesh(w.ptr(), mesh.ptr(),
+		C.dFloat(hullTolerance), C.int(shapeID), C.int(subShapeID)), "compound collision")
 }
 
 func (c *Collision) CompoundBeginAddRemove() {
-	C.NewtonCompoundCollisionBeginAddRemove(c.handle)
+	C.NewtonCompoundCollisionBeginAddRemove(c.ptr())
 }
 
 func (c *Collision) CompoundEndAddRemove() {
-	C.NewtonCompoundCollisionEndAddRemove(c.handle)
+	C.NewtonCompoundCollisionEndAddRemove(c.ptr())
 }
 
 func (c *Collision) CompoundAddSubCollision(subCollision *Collision) *Node {
-	return &Node{C.NewtonCompoundCollisionAddSubCollision(c.handle, subCollision.handle)}
+	return &Node{C.NewtonCompoundCollisionAddSubCollision(c.ptr(), subCollision.ptr())}
 }
 
 func (c *Collision) CompoundRemoveSubCollision(collisionNode *Node) {
-	C.NewtonCompoundCollisionRemoveSubCollision(c.handle, collisionNode.handle)
+	C.NewtonCompoundCollisionRemoveSubCollision(c.ptr(), collisionNode.handle)
 }
 
 func (c *Collision) CompoundRemoveSubCollisionByIndex(index int) {
-	C.NewtonCompoundCollisionRemoveSubCollisionByIndex(c.handle, C.int(index))
+	C.NewtonCompoundCollisionRemoveSubCollisionByIndex(c.ptr(), C.int(index))
 }
 
 func (c *Collision) SetSubCollisionMatrix(collisionNode *Node, matrix *[16]float32) {
-	C.NewtonCompoundCollisionSetSubCollisionMatrix(c.handle, collisionNode.handle,
+	C.NewtonCompoundCollisionSetSubCollisionMatrix(c.ptr(), collisionNode.handle,
 		(*C.dFloat)(&matrix[0]))
 }
 
+//CompoundFirstNode and CompoundNextNode return nil when there are no more nodes
 func (c *Collision) CompoundFirstNode() *Node {
-	return &Node{C.NewtonCompoundCollisionGetFirstNode(c.handle)}
+	return newNode(C.NewtonCompoundCollisionGetFirstNode(c.ptr()))
 }
 
 func (c *Collision) CompoundNextNode(curNode *Node) *Node {
-	return &Node{C.NewtonCompoundCollisionGetNextNode(c.handle, curNode.handle)}
+	return newNode(C.NewtonCompoundCollisionGetNextNode(c.ptr(), curNode.handle))
+}
+
+//CompoundNodes iterates over the nodes of a compound collision.  The next node is found
+// before the current one is yielded, so it's safe to remove the current node in the loop
+func (c *Collision) CompoundNodes() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for node := c.CompoundFirstNode(); node != nil; {
+			next := c.CompoundNextNode(node)
+			if !yield(node) {
+				return
+			}
+			node = next
+		}
+	}
 }
 
 func (c *Collision) CompoundNodeByIndex(index int) *Node {
-	return &Node{C.NewtonCompoundCollisionGetNodeByIndex(c.handle,
+	return &Node{C.NewtonCompoundCollisionGetNodeByIndex(c.ptr(),
 		C.int(index))}
 }
 
 func (c *Collision) CompoundNodeIndex(node *Node) int {
-	return int(C.NewtonCompoundCollisionGetNodeIndex(c.handle,
+	return int(C.NewtonCompoundCollisionGetNodeIndex(c.ptr(),
 		node.handle))
 }
 
 func (parent *Collision) CompoundCollisionFromNode(node *Node) *Collision {
-	return &Collision{handle: C.NewtonCompoundCollisionGetCollisionFromNode(parent.handle, node.handle)}
+	return newCollision(parent.world, C.NewtonCompoundCollisionGetCollisionFromNode(parent.ptr(), node.handle))
 }
 
 //SceneCollision
 
-func (w *World) CreateSceneCollision(shapeID int) *Collision {
-	return &Collision{handle: C.NewtonCreateSceneCollision(w.handle, C.int(shapeID))}
+func (w *World) CreateSceneCollision(shapeID int) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateSceneCollision(w.ptr(), C.int(shapeID)), "scene collision")
 }
 
 func (c *Collision) SceneBeginAddRemove() {
-	C.NewtonSceneCollisionBeginAddRemove(c.handle)
+	C.NewtonSceneCollisionBeginAddRemove(c.ptr())
 }
 
 func (c *Collision) SceneEndAddRemove() {
-	C.NewtonSceneCollisionEndAddRemove(c.handle)
+	C.NewtonSceneCollisionEndAddRemove(c.ptr())
 }
 
 func (c *Collision) SceneAddSubCollision(subCollision *Collision) *Node {
-	return &Node{C.NewtonSceneCollisionAddSubCollision(c.handle, subCollision.handle)}
+	return &Node{C.NewtonSceneCollisionAddSubCollision(c.ptr(), subCollision.ptr())}
 }
 
 func (c *Collision) SceneSetSubCollisionMatrix(collisionNode *Node, matrix *[16]float32) {
-	C.NewtonSceneCollisionSetSubCollisionMatrix(c.handle, collisionNode.handle,
+	C.NewtonSceneCollisionSetSubCollisionMatrix(c.ptr(), collisionNode.handle,
 		(*C.dFloat)(&matrix[0]))
 }
 
+//SceneFirstNode and SceneNextNode return nil when there are no more nodes
 func (c *Collision) SceneFirstNode() *Node {
-	return &Node{C.NewtonSceneCollisionGetFirstNode(c.handle)}
+	return newNode(C.NewtonSceneCollisionGetFirstNode(c.ptr()))
 }
 
 func (c *Collision) SceneNextNode(curNode *Node) *Node {
-	return &Node{C.NewtonSceneCollisionGetNextNode(c.handle, curNode.handle)}
+	return newNode(C.NewtonSceneCollisionGetNextNode(c.ptr(), curNode.handle))
+}
+
+//SceneNodes iterates over the nodes of a scene collision
+func (c *Collision) SceneNodes() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for node := c.SceneFirstNode(); node != nil; {
+			next := c.SceneNextNode(node)
+			if !yield(node) {
+				return
+			}
+			node = next
+		}
+	}
 }
 
 func (parent *Collision) SceneCollisionFromNode(node *Node) *Collision {
-	return &Collision{handle: C.NewtonSceneCollisionGetCollisionFromNode(parent.handle, node.handle)}
+	return newCollision(parent.world, C.NewtonSceneCollisionGetCollisionFromNode(parent.ptr(), node.handle))
 }
 
 //TreeCollision
-func (w *World) CreateTreeCollision(shapeID int) *Collision {
-	return &Collision{handle: C.NewtonCreateTreeCollision(w.handle, C.int(shapeID))}
+func (w *World) CreateTreeCollision(shapeID int) (*Collision, error) {
+	return createdCollision(owner(w.handle), C.NewtonCreateTreeCollision(w.ptr(), C.int(shapeID)), "tree collision")
 }
 
-func (w *World) CreateTreeCollsionFromMesh(mesh *Mesh, shapeID int) *Collision {
-	return &Collision{handle: C.NewtonCreateTreeCollisionFromMesh(w.handle, mesh.handle, C.int(shapeID))}
+func (w *World) CreateTreeCollsionFromMesh(mesh *Mesh, shapeID int) (*Collision, error) {
+	if mesh == nil {
+		return nil, ErrNilMesh
+	}
+	return createdCollision(owner(w.handle), C.NewtonCreateTreeCollisionFromMesh(w.ptr(), mesh.ptr(), C.int(shapeID)),
+		"tree collision")
 }
 
 func (c *Collision) BeginTreeBuild() {
-	C.NewtonTreeCollisionBeginBuild(c.handle)
+	C.NewtonTreeCollisionBeginBuild(c.ptr())
 }
 
 func (c *Collision) AddTreeFace(vertexCount int, vertexPtr []float32, strideInBytes, faceAttribute int) {
-	C.NewtonTreeCollisionAddFace(c.handle, C.int(vertexCount), (*C.dFloat)(&vertexPtr[0]), C.int(strideInBytes),
+	C.NewtonTreeCollisionAddFace(c.ptr(), C.int(vertexCount), (*C.dFloat)(&vertexPtr[0]), C.int(strideInBytes),
 		C.int(faceAttribute))
 }
 
 //EndBuild ends the building of a Tree collision primative.
 // Optimize should be set to true for concave meshes
 func (c *Collision) EndTreeBuild(optimize bool) {
-	C.NewtonTreeCollisionEndBuild(c.handle, cint[optimize])
+	C.NewtonTreeCollisionEndBuild(c.ptr(), cint[optimize])
 }
 
 func (c *Collision) TreeFaceAttribute(faceIndexArray []int, indexCount int) int {
-	return int(C.NewtonTreeCollisionGetFaceAtribute(c.handle, (*C.int)(unsafe.Pointer(&faceIndexArray[0])),
+	return int(C.NewtonTreeCollisionGetFaceAtribute(c.ptr(), (*C.int)(unsafe.Pointer(&faceIndexArray[0])),
 		C.int(indexCount)))
 }
 
 func (c *Collision) SetTreeFaceAttribute(faceIndexArray []int, indexCount int, attribute int) {
-	C.NewtonTreeCollisionSetFaceAtribute(c.handle, (*C.int)(unsafe.Pointer(&faceIndexArray[0])),
+	C.NewtonTreeCollisionSetFaceAtribute(c.ptr(), (*C.int)(unsafe.Pointer(&faceIndexArray[0])),
 		C.int(indexCount), C.int(attribute))
 }
 
@@ -249,169 +383,199 @@ func (c *Collision) SetTreeFaceAttribute(faceIndexArray []int, indexCount int, a
 
 //General Purpose collision library functions
 
-func (c *Collision) CreateInstance() *Collision {
-	return &Collision{handle: C.NewtonCollisionCreateInstance(c.handle)}
+func (c *Collision) CreateInstance() (*Collision, error) {
+	return createdCollision(c.world, C.NewtonCollisionCreateInstance(c.ptr()),
+		"collision instance")
 }
 
 func (c *Collision) Type() int {
-	return int(C.NewtonCollisionGetType(c.handle))
+	return int(C.NewtonCollisionGetType(c.ptr()))
 }
 
 func (c *Collision) SetUserID(id uint) {
-	C.NewtonCollisionSetUserID(c.handle, C.unsigned(id))
+	C.NewtonCollisionSetUserID(c.ptr(), C.unsigned(id))
 }
 
 func (c *Collision) UserID() uint {
-	return uint(C.NewtonCollisionGetUserID(c.handle))
+	return uint(C.NewtonCollisionGetUserID(c.ptr()))
 }
 
 func (c *Collision) UserData() interface{} {
 	//redirection necessary for handling instanced collisions
-	//return (interface{})(C.NewtonCollisionGetUserData(c.handle))
-	return ownerData[owner(c.handle)]
+	//return (interface{})(C.NewtonCollisionGetUserData(c.ptr()))
+	data, _ := ownerData.get(owner(c.handle))
+	return data
 }
 
+//SetUserData sets the collision's user data, it's released when Newton destroys the collision
 func (c *Collision) SetUserData(data interface{}) {
-	//C.NewtonCollisionSetUserData(c.handle, unsafe.Pointer(&data))
-	ownerData[owner(c.handle)] = data
+	//C.NewtonCollisionSetUserData(c.ptr(), unsafe.Pointer(&data))
+	ownerData.set(owner(c.handle), data)
 }
 
 func (c *Collision) SetMatrix(matrix *[16]float32) {
-	C.NewtonCollisionSetMatrix(c.handle, (*C.dFloat)(&matrix[0]))
+	C.NewtonCollisionSetMatrix(c.ptr(), (*C.dFloat)(&matrix[0]))
 }
 
 func (c *Collision) Matrix(matrix *[16]float32) {
-	C.NewtonCollisionGetMatrix(c.handle, (*C.dFloat)(&matrix[0]))
+	C.NewtonCollisionGetMatrix(c.ptr(), (*C.dFloat)(&matrix[0]))
 }
 
 func (c *Collision) SetScale(x, y, z float32) {
-	C.NewtonCollisionSetScale(c.handle, C.dFloat(x), C.dFloat(y), C.dFloat(z))
+	C.NewtonCollisionSetScale(c.ptr(), C.dFloat(x), C.dFloat(y), C.dFloat(z))
 }
 
 func (c *Collision) Scale() (x, y, z float32) {
-	C.NewtonCollisionGetScale(c.handle, (*C.dFloat)(&x), (*C.dFloat)(&y), (*C.dFloat)(&z))
+	C.NewtonCollisionGetScale(c.ptr(), (*C.dFloat)(&x), (*C.dFloat)(&y), (*C.dFloat)(&z))
 	return
 }
 
+//CalculateAABB gets the world space bounding box of the collision placed at matrix
+func (c *Collision) CalculateAABB(matrix *[16]float32, p0, p1 *[3]float32) {
+	C.NewtonCollisionCalculateAABB(c.ptr(), (*C.dFloat)(&matrix[0]), (*C.dFloat)(&p0[0]),
+		(*C.dFloat)(&p1[0]))
+}
+
+func (w *World) CollisionIntersectionTest(collisionA *Collision, matrixA *[16]float32, collisionB *Collision,
+	matrixB *[16]float32, threadIndex int) bool {
+	return gbool[int(C.NewtonCollisionIntersectionTest(w.ptr(), collisionA.ptr(), (*C.dFloat)(&matrixA[0]),
+		collisionB.ptr(), (*C.dFloat)(&matrixB[0]), C.int(threadIndex)))]
+}
+
+//Destroy releases the collision, it can't be used afterwards
 func (c *Collision) Destroy() {
-	C.NewtonDestroyCollision(c.handle)
+	C.NewtonDestroyCollision(c.ptr())
+	c.res.destroyed()
+	c.handle = nil
 }
 
 //Mesh
-func (w *World) CreateMesh() *Mesh {
-	return &Mesh{C.NewtonMeshCreate(w.handle)}
+func (w *World) CreateMesh() (*Mesh, error) {
+	return createdMesh(owner(w.handle), C.NewtonMeshCreate(w.ptr()), "mesh")
 }
 
-func (m *Mesh) Duplicate() *Mesh {
-	return &Mesh{C.NewtonMeshCreateFromMesh(m.handle)}
+func (m *Mesh) Duplicate() (*Mesh, error) {
+	return createdMesh(m.res.trackedWorld(), C.NewtonMeshCreateFromMesh(m.ptr()), "mesh")
 }
 
-func (c *Collision) CreateMesh() *Mesh {
-	return &Mesh{C.NewtonMeshCreateFromCollision(c.handle)}
+func (c *Collision) CreateMesh() (*Mesh, error) {
+	return createdMesh(c.world, C.NewtonMeshCreateFromCollision(c.ptr()), "mesh")
 }
 
 func (w *World) CreateConvexMesh(pointCount int, vertexCloud []float32, strideInBytes int,
-	tolerance float32) *Mesh {
-	return &Mesh{C.NewtonMeshCreateConvexHull(w.handle, C.int(pointCount), (*C.dFloat)(&vertexCloud[0]),
-		C.int(strideInBytes), C.dFloat(tolerance))}
+	tolerance float32) (*Mesh, error) {
+	if err := validVertices(pointCount, vertexCloud, strideInBytes); err != nil {
+		return nil, err
+	}
+	return createdMesh(owner(w.handle), C.NewtonMeshCreateConvexHull(w.ptr(), C.int(pointCount), (*C.dFloat)(&vertexCloud[0]),
+		C.int(strideInBytes), C.dFloat(tolerance)), "convex mesh")
 }
 
 func (w *World) CreateDelaunayTetrahedralizationMesh(pointCount int, vertexCloud []float32, strideInBytes,
-	materialID int, textureMatrix []float32) *Mesh {
-	return &Mesh{C.NewtonMeshCreateDelaunayTetrahedralization(w.handle, C.int(pointCount),
+	materialID int, textureMatrix *[16]float32) (*Mesh, error) {
+	if err := validVertices(pointCount, vertexCloud, strideInBytes); err != nil {
+		return nil, err
+	}
+	return createdMesh(owner(w.handle), C.NewtonMeshCreateDelaunayTetrahedralization(w.ptr(), C.int(pointCount),
 		(*C.dFloat)(&vertexCloud[0]), C.int(strideInBytes), C.int(materialID),
-		(*C.dFloat)(&textureMatrix[0]))}
+		matrixPtr(textureMatrix)), "delaunay tetrahedralization mesh")
 }
 
 func (w *World) CreateVoronoiConvexDecompositionMesh(pointCount int, vertexCloud []float32, strideInBytes,
-	materialID int, textureMatrix []float32, borderConvexSize float32) *Mesh {
-	return &Mesh{C.NewtonMeshCreateVoronoiConvexDecomposition(w.handle, C.int(pointCount),
+	materialID int, textureMatrix *[16]float32, borderConvexSize float32) (*Mesh, error) {
+	if err := validVertices(pointCount, vertexCloud, strideInBytes); err != nil {
+		return nil, err
+	}
+	return createdMesh(owner(w.handle), C.NewtonMeshCreateVoronoiConvexDecomposition(w.ptr(), C.int(pointCount),
 		(*C.dFloat)(&vertexCloud[0]), C.int(strideInBytes), C.int(materialID),
-		(*C.dFloat)(&textureMatrix[0]), C.dFloat(borderConvexSize))}
+		matrixPtr(textureMatrix), C.dFloat(borderConvexSize)), "voronoi convex decomposition mesh")
 }
 
+//Destroy releases the mesh, it can't be used afterwards
 func (m *Mesh) Destroy() {
-	C.NewtonMeshDestroy(m.handle)
+	C.NewtonMeshDestroy(m.ptr())
+	m.res.destroyed()
+	m.handle = nil
 }
 
 func (m *Mesh) ApplyTransform(matrix *[16]float32) {
-	C.NewtonMesApplyTransform(m.handle, (*C.dFloat)(&matrix[0]))
+	C.NewtonMesApplyTransform(m.ptr(), (*C.dFloat)(&matrix[0]))
 }
 
 func (m *Mesh) CalculateOOBB(matrix *[16]float32, x, y, z *float32) {
-	C.NewtonMeshCalculateOOBB(m.handle, (*C.dFloat)(&matrix[0]), (*C.dFloat)(x),
+	C.NewtonMeshCalculateOOBB(m.ptr(), (*C.dFloat)(&matrix[0]), (*C.dFloat)(x),
 		(*C.dFloat)(y), (*C.dFloat)(z))
 }
 
 func (m *Mesh) CalculateVertexNormals(angleInRadians float32) {
-	C.NewtonMeshCalculateVertexNormals(m.handle, C.dFloat(angleInRadians))
+	C.NewtonMeshCalculateVertexNormals(m.ptr(), C.dFloat(angleInRadians))
 }
 
 func (m *Mesh) ApplySphericalMapping(material int) {
-	C.NewtonMeshApplySphericalMapping(m.handle, C.int(material))
+	C.NewtonMeshApplySphericalMapping(m.ptr(), C.int(material))
 }
 
 func (m *Mesh) ApplyBoxMapping(front, side, top int) {
-	C.NewtonMeshApplyBoxMapping(m.handle, C.int(front), C.int(side), C.int(top))
+	C.NewtonMeshApplyBoxMapping(m.ptr(), C.int(front), C.int(side), C.int(top))
 }
 
 func (m *Mesh) ApplyCylindricalMapping(cylinderMaterial, capMaterial int) {
-	C.NewtonMeshApplyCylindricalMapping(m.handle, C.int(cylinderMaterial), C.int(capMaterial))
+	C.NewtonMeshApplyCylindricalMapping(m.ptr(), C.int(cylinderMaterial), C.int(capMaterial))
 }
 
 func (m *Mesh) IsOpenMesh() bool {
-	return gbool[int(C.NewtonMeshIsOpenMesh(m.handle))]
+	return gbool[int(C.NewtonMeshIsOpenMesh(m.ptr()))]
 }
 
 func (m *Mesh) FixTJoints() {
-	C.NewtonMeshFixTJoints(m.handle)
+	C.NewtonMeshFixTJoints(m.ptr())
 }
 
 func (m *Mesh) Polygonize() {
-	C.NewtonMeshPolygonize(m.handle)
+	C.NewtonMeshPolygonize(m.ptr())
 }
 
 func (m *Mesh) Triangulate() {
-	C.NewtonMeshTriangulate(m.handle)
+	C.NewtonMeshTriangulate(m.ptr())
 }
 
 func (m *Mesh) Union(clipper *Mesh, clipperMatrix *[16]float32) {
-	C.NewtonMeshUnion(m.handle, clipper.handle, (*C.dFloat)(&clipperMatrix[0]))
+	C.NewtonMeshUnion(m.ptr(), clipper.ptr(), (*C.dFloat)(&clipperMatrix[0]))
 }
 
 func (m *Mesh) Difference(clipper *Mesh, clipperMatrix *[16]float32) {
-	C.NewtonMeshDifference(m.handle, clipper.handle, (*C.dFloat)(&clipperMatrix[0]))
+	C.NewtonMeshDifference(m.ptr(), clipper.ptr(), (*C.dFloat)(&clipperMatrix[0]))
 }
 
 func (m *Mesh) Intersection(clipper *Mesh, clipperMatrix *[16]float32) {
-	C.NewtonMeshIntersection(m.handle, clipper.handle, (*C.dFloat)(&clipperMatrix[0]))
+	C.NewtonMeshIntersection(m.ptr(), clipper.ptr(), (*C.dFloat)(&clipperMatrix[0]))
 }
 
 func (m *Mesh) Clip(clipper *Mesh, clipperMatrix *[16]float32) (topMesh, bottomMesh *Mesh) {
 	topMesh = new(Mesh)
 	bottomMesh = new(Mesh)
 
-	C.NewtonMeshClip(m.handle, clipper.handle, (*C.dFloat)(&clipperMatrix[0]),
+	C.NewtonMeshClip(m.ptr(), clipper.ptr(), (*C.dFloat)(&clipperMatrix[0]),
 		(**C.NewtonMesh)(unsafe.Pointer(topMesh.handle)),
 		(**C.NewtonMesh)(unsafe.Pointer(bottomMesh.handle)))
 	return
 }
 
 func (m *Mesh) RemoveUnusedVertices(vertexRemapTable []int) {
-	C.NewtonRemoveUnusedVertices(m.handle, (*C.int)(unsafe.Pointer(&vertexRemapTable[0])))
+	C.NewtonRemoveUnusedVertices(m.ptr(), (*C.int)(unsafe.Pointer(&vertexRemapTable[0])))
 }
 
 func (m *Mesh) BeginFace() {
-	C.NewtonMeshBeginFace(m.handle)
+	C.NewtonMeshBeginFace(m.ptr())
 }
 
 func (m *Mesh) AddFace(vertexCount int, vertex []float32, strideInBytes, materialIndex int) {
-	C.NewtonMeshAddFace(m.handle, C.int(vertexCount), (*C.dFloat)(&vertex[0]), C.int(strideInBytes),
+	C.NewtonMeshAddFace(m.ptr(), C.int(vertexCount), (*C.dFloat)(&vertex[0]), C.int(strideInBytes),
 		C.int(materialIndex))
 }
 
 func (m *Mesh) EndFace() {
-	C.NewtonMeshEndFace(m.handle)
+	C.NewtonMeshEndFace(m.ptr())
 }
 
 func (m *Mesh) BuildFromVertexListIndexList(faceCount int, faceIndexCount, faceMaterialIndex []int,
@@ -420,12 +584,22 @@ func (m *Mesh) BuildFromVertexListIndexList(faceCount int, faceIndexCount, faceM
 	uv0 []float32, uv0StrideInBytes int, uv0Index []int,
 	uv1 []float32, uv1StrideInBytes int, uv1Index []int) {
 
-	C.NewtonMeshBuildFromVertexListIndexList(m.handle, C.int(faceCount),
-		(*C.int)(unsafe.Pointer(&faceIndexCount[0])), (*C.int)(unsafe.Pointer(&faceMaterialIndex[0])),
-		(*C.dFloat)(&vertex[0]), C.int(vertexStrideInBytes), (*C.int)(unsafe.Pointer(&vertexIndex[0])),
-		(*C.dFloat)(&normal[0]), C.int(normalStrideInBytes), (*C.int)(unsafe.Pointer(&normalIndex[0])),
-		(*C.dFloat)(&uv0[0]), C.int(uv0StrideInBytes), (*C.int)(unsafe.Pointer(&uv0Index[0])),
-		(*C.dFloat)(&uv1[0]), C.int(uv1StrideInBytes), (*C.int)(unsafe.Pointer(&uv1Index[0])))
+	//go ints are wider than c ints on 64 bit platforms, so the index lists are copied
+	C.NewtonMeshBuildFromVertexListIndexList(m.ptr(), C.int(faceCount),
+		&cInts(faceIndexCount)[0], &cInts(faceMaterialIndex)[0],
+		(*C.dFloat)(&vertex[0]), C.int(vertexStrideInBytes), &cInts(vertexIndex)[0],
+		(*C.dFloat)(&normal[0]), C.int(normalStrideInBytes), &cInts(normalIndex)[0],
+		(*C.dFloat)(&uv0[0]), C.int(uv0StrideInBytes), &cInts(uv0Index)[0],
+		(*C.dFloat)(&uv1[0]), C.int(uv1StrideInBytes), &cInts(uv1Index)[0])
+}
+
+//cInts copies the go ints to c ints
+func cInts(ints []int) []C.int {
+	result := make([]C.int, len(ints))
+	for i := range ints {
+		result[i] = C.int(ints[i])
+	}
+	return result
 }
 
 func (m *Mesh) VertexStreams(vertexStrideInByte int, vertex []float32,
@@ -433,7 +607,7 @@ func (m *Mesh) VertexStreams(vertexStrideInByte int, vertex []float32,
 	uv0StrideInByte int, uv0 []float32,
 	uv1StrideInByte int, uv1 []float32) {
 
-	C.NewtonMeshGetVertexStreams(m.handle, C.int(vertexStrideInByte), (*C.dFloat)(&vertex[0]),
+	C.NewtonMeshGetVertexStreams(m.ptr(), C.int(vertexStrideInByte), (*C.dFloat)(&vertex[0]),
 		C.int(normalStrideInByte), (*C.dFloat)(&normal[0]),
 		C.int(uv0StrideInByte), (*C.dFloat)(&uv0[0]),
 		C.int(uv1StrideInByte), (*C.dFloat)(&uv1[0]))
@@ -443,7 +617,7 @@ func (m *Mesh) IndirectVertexStreams(vertexStrideInByte int, vertex []float32, v
 	normalStrideInByte int, normal []float32, normalIndices []int, normalCount *int,
 	uv0StrideInByte int, uv0 []float32, uv0Indices []int, uv0Count *int,
 	uv1StrideInByte int, uv1 []float32, uv1Indices []int, uv1Count *int) {
-	C.NewtonMeshGetIndirectVertexStreams(m.handle,
+	C.NewtonMeshGetIndirectVertexStreams(m.ptr(),
 		C.int(vertexStrideInByte), (*C.dFloat)(&vertex[0]), (*C.int)(unsafe.Pointer(&vertexIndices[0])),
 		(*C.int)(unsafe.Pointer(vertexCount)),
 		C.int(normalStrideInByte), (*C.dFloat)(&normal[0]), (*C.int)(unsafe.Pointer(&normalIndices[0])),
@@ -463,107 +637,108 @@ type MeshHandle struct {
 // the difference between a mesh material, and a mesh material material
 
 func (m *Mesh) BeginHandle() *MeshHandle {
-	return &MeshHandle{C.NewtonMeshBeginHandle(m.handle)}
+	return &MeshHandle{C.NewtonMeshBeginHandle(m.ptr())}
 }
 
 func (m *Mesh) EndHandle(handle *MeshHandle) {
-	C.NewtonMeshEndHandle(m.handle, handle.handle)
+	C.NewtonMeshEndHandle(m.ptr(), handle.handle)
 }
 func (m *Mesh) FirstMaterial(handle *MeshHandle) int {
-	return int(C.NewtonMeshFirstMaterial(m.handle, handle.handle))
+	return int(C.NewtonMeshFirstMaterial(m.ptr(), handle.handle))
 }
 
 func (m *Mesh) NextMaterial(handle *MeshHandle, materialID int) int {
-	return int(C.NewtonMeshNextMaterial(m.handle, handle.handle, C.int(materialID)))
+	return int(C.NewtonMeshNextMaterial(m.ptr(), handle.handle, C.int(materialID)))
 }
 
 func (m *Mesh) MaterialGetMaterial(handle *MeshHandle, materialId int) int {
-	return int(C.NewtonMeshMaterialGetMaterial(m.handle, handle.handle, C.int(materialId)))
+	return int(C.NewtonMeshMaterialGetMaterial(m.ptr(), handle.handle, C.int(materialId)))
 }
 
 func (m *Mesh) MaterialGetIndexCount(handle *MeshHandle, materialId int) int {
-	return int(C.NewtonMeshMaterialGetIndexCount(m.handle, handle.handle, C.int(materialId)))
+	return int(C.NewtonMeshMaterialGetIndexCount(m.ptr(), handle.handle, C.int(materialId)))
 }
 
 func (m *Mesh) MaterialGetIndexStream(handle *MeshHandle, materialId int, index []int) {
-	C.NewtonMeshMaterialGetIndexStream(m.handle, handle.handle, C.int(materialId),
+	C.NewtonMeshMaterialGetIndexStream(m.ptr(), handle.handle, C.int(materialId),
 		(*C.int)(unsafe.Pointer(&index[0])))
 }
 
 func (m *Mesh) MaterialGetIndexStreamShort(handle *MeshHandle, materialId int, index []int16) {
-	C.NewtonMeshMaterialGetIndexStreamShort(m.handle, handle.handle, C.int(materialId),
+	C.NewtonMeshMaterialGetIndexStreamShort(m.ptr(), handle.handle, C.int(materialId),
 		(*C.short)(unsafe.Pointer(&index[0])))
 }
 
+//The segment and layer functions return nil when there are no more
 func (m *Mesh) CreateFirstSingleSegment() *Mesh {
-	return &Mesh{C.NewtonMeshCreateFirstSingleSegment(m.handle)}
+	return newMesh(C.NewtonMeshCreateFirstSingleSegment(m.ptr()))
 }
 
 func (m *Mesh) CreateNextSingleSegment(segment *Mesh) *Mesh {
-	return &Mesh{C.NewtonMeshCreateNextSingleSegment(m.handle, segment.handle)}
+	return newMesh(C.NewtonMeshCreateNextSingleSegment(m.ptr(), segment.ptr()))
 }
 
 func (m *Mesh) CreateFirstLayer() *Mesh {
-	return &Mesh{C.NewtonMeshCreateFirstLayer(m.handle)}
+	return newMesh(C.NewtonMeshCreateFirstLayer(m.ptr()))
 }
 
 func (m *Mesh) CreateNextLayer(segment *Mesh) *Mesh {
-	return &Mesh{C.NewtonMeshCreateNextLayer(m.handle, segment.handle)}
+	return newMesh(C.NewtonMeshCreateNextLayer(m.ptr(), segment.ptr()))
 }
 
 func (m *Mesh) TotalFaceCount() int {
-	return int(C.NewtonMeshGetTotalFaceCount(m.handle))
+	return int(C.NewtonMeshGetTotalFaceCount(m.ptr()))
 }
 
 func (m *Mesh) TotalIndexCount() int {
-	return int(C.NewtonMeshGetTotalIndexCount(m.handle))
+	return int(C.NewtonMeshGetTotalIndexCount(m.ptr()))
 }
 
 func (m *Mesh) Faces(faceIndexCount, faceMaterial, faceIndices []int) {
 	//untested
-	C.NewtonMeshGetFaces(m.handle, (*C.int)(unsafe.Pointer(&faceIndexCount[0])),
+	C.NewtonMeshGetFaces(m.ptr(), (*C.int)(unsafe.Pointer(&faceIndexCount[0])),
 		(*C.int)(unsafe.Pointer(&faceMaterial[0])),
 		(*unsafe.Pointer)(unsafe.Pointer(&faceIndices[0])))
 }
 
 func (m *Mesh) PointCount() int {
-	return int(C.NewtonMeshGetPointCount(m.handle))
+	return int(C.NewtonMeshGetPointCount(m.ptr()))
 }
 
 func (m *Mesh) PointStrideInByte() int {
-	return int(C.NewtonMeshGetPointStrideInByte(m.handle))
+	return int(C.NewtonMeshGetPointStrideInByte(m.ptr()))
 }
 
 func (m *Mesh) PointArray(size int) []float64 {
-	cArray := C.NewtonMeshGetPointArray(m.handle)
+	cArray := C.NewtonMeshGetPointArray(m.ptr())
 	return goFloat64s(cArray, size)
 }
 
 func (m *Mesh) NormalArray(size int) []float64 {
-	cArray := C.NewtonMeshGetNormalArray(m.handle)
+	cArray := C.NewtonMeshGetNormalArray(m.ptr())
 	return goFloat64s(cArray, size)
 }
 
 func (m *Mesh) UV0Array(size int) []float64 {
-	cArray := C.NewtonMeshGetUV0Array(m.handle)
+	cArray := C.NewtonMeshGetUV0Array(m.ptr())
 	return goFloat64s(cArray, size)
 }
 
 func (m *Mesh) UV1Array(size int) []float64 {
-	cArray := C.NewtonMeshGetUV1Array(m.handle)
+	cArray := C.NewtonMeshGetUV1Array(m.ptr())
 	return goFloat64s(cArray, size)
 }
 
 func (m *Mesh) VertexCount() int {
-	return int(C.NewtonMeshGetVertexCount(m.handle))
+	return int(C.NewtonMeshGetVertexCount(m.ptr()))
 }
 
 func (m *Mesh) VertexStrideInByte() int {
-	return int(C.NewtonMeshGetVertexStrideInByte(m.handle))
+	return int(C.NewtonMeshGetVertexStrideInByte(m.ptr()))
 }
 
 func (m *Mesh) VertexArray(size int) []float64 {
-	cArray := C.NewtonMeshGetVertexArray(m.handle)
+	cArray := C.NewtonMeshGetVertexArray(m.ptr())
 	return goFloat64s(cArray, size)
 }
 
@@ -571,52 +746,79 @@ type MeshVertex struct {
 	handle unsafe.Pointer
 }
 
+//FirstVertex and NextVertex return nil when there are no more vertexs
 func (m *Mesh) FirstVertex() *MeshVertex {
-	return &MeshVertex{C.NewtonMeshGetFirstVertex(m.handle)}
+	handle := C.NewtonMeshGetFirstVertex(m.ptr())
+	if handle == nil {
+		return nil
+	}
+	return &MeshVertex{handle}
 }
 
 func (m *Mesh) NextVertex(vertex *MeshVertex) *MeshVertex {
-	return &MeshVertex{C.NewtonMeshGetNextVertex(m.handle, vertex.handle)}
+	handle := C.NewtonMeshGetNextVertex(m.ptr(), vertex.handle)
+	if handle == nil {
+		return nil
+	}
+	return &MeshVertex{handle}
 }
 
 func (m *Mesh) VertexIndex(vertex *MeshVertex) int {
-	return int(C.NewtonMeshGetVertexIndex(m.handle, vertex.handle))
+	return int(C.NewtonMeshGetVertexIndex(m.ptr(), vertex.handle))
 }
 
 type MeshPoint struct {
 	handle unsafe.Pointer
 }
 
+//FirstPoint and NextPoint return nil when there are no more points
 func (m *Mesh) FirstPoint() *MeshPoint {
-	return &MeshPoint{C.NewtonMeshGetFirstPoint(m.handle)}
+	handle := C.NewtonMeshGetFirstPoint(m.ptr())
+	if handle == nil {
+		return nil
+	}
+	return &MeshPoint{handle}
 }
 
 func (m *Mesh) NextPoint(Point *MeshPoint) *MeshPoint {
-	return &MeshPoint{C.NewtonMeshGetNextPoint(m.handle, Point.handle)}
+	handle := C.NewtonMeshGetNextPoint(m.ptr(), Point.handle)
+	if handle == nil {
+		return nil
+	}
+	return &MeshPoint{handle}
 }
 
 func (m *Mesh) PointIndex(Point *MeshPoint) int {
-	return int(C.NewtonMeshGetPointIndex(m.handle, Point.handle))
+	return int(C.NewtonMeshGetPointIndex(m.ptr(), Point.handle))
 }
 
 func (m *Mesh) VertexIndexFromPoint(point *MeshPoint) int {
-	return int(C.NewtonMeshGetVertexIndexFromPoint(m.handle, point.handle))
+	return int(C.NewtonMeshGetVertexIndexFromPoint(m.ptr(), point.handle))
 }
 
 type MeshEdge struct {
 	handle unsafe.Pointer
 }
 
+//FirstEdge and NextEdge return nil when there are no more edges
 func (m *Mesh) FirstEdge() *MeshEdge {
-	return &MeshEdge{C.NewtonMeshGetFirstEdge(m.handle)}
+	handle := C.NewtonMeshGetFirstEdge(m.ptr())
+	if handle == nil {
+		return nil
+	}
+	return &MeshEdge{handle}
 }
 
 func (m *Mesh) NextEdge(Edge *MeshEdge) *MeshEdge {
-	return &MeshEdge{C.NewtonMeshGetNextEdge(m.handle, Edge.handle)}
+	handle := C.NewtonMeshGetNextEdge(m.ptr(), Edge.handle)
+	if handle == nil {
+		return nil
+	}
+	return &MeshEdge{handle}
 }
 
 func (m *Mesh) EdgeIndices(edge *MeshEdge, v0, v1 []int) {
-	C.NewtonMeshGetEdgeIndices(m.handle, edge.handle, (*C.int)(unsafe.Pointer(&v0[0])),
+	C.NewtonMeshGetEdgeIndices(m.ptr(), edge.handle, (*C.int)(unsafe.Pointer(&v0[0])),
 		(*C.int)(unsafe.Pointer(&v1[0])))
 }
 
@@ -624,38 +826,55 @@ type MeshFace struct {
 	handle unsafe.Pointer
 }
 
+//FirstFace and NextFace return nil when there are no more faces
 func (m *Mesh) FirstFace() *MeshFace {
-	return &MeshFace{C.NewtonMeshGetFirstFace(m.handle)}
+	handle := C.NewtonMeshGetFirstFace(m.ptr())
+	if handle == nil {
+		return nil
+	}
+	return &MeshFace{handle}
 }
 
 func (m *Mesh) NextFace(Face *MeshFace) *MeshFace {
-	return &MeshFace{C.NewtonMeshGetNextFace(m.handle, Face.handle)}
+	handle := C.NewtonMeshGetNextFace(m.ptr(), Face.handle)
+	if handle == nil {
+		return nil
+	}
+	return &MeshFace{handle}
 }
 
 func (m *Mesh) IsFaceOpen(face *MeshFace) bool {
-	return gbool[int(C.NewtonMeshIsFaceOpen(m.handle, face.handle))]
+	return gbool[int(C.NewtonMeshIsFaceOpen(m.ptr(), face.handle))]
 }
 
 func (m *Mesh) FaceMaterial(face *MeshFace) int {
-	return int(C.NewtonMeshGetFaceMaterial(m.handle, face.handle))
+	return int(C.NewtonMeshGetFaceMaterial(m.ptr(), face.handle))
 }
 
 func (m *Mesh) FaceIndexCount(face *MeshFace) int {
-	return int(C.NewtonMeshGetFaceIndexCount(m.handle, face.handle))
+	return int(C.NewtonMeshGetFaceIndexCount(m.ptr(), face.handle))
 }
 
 func (m *Mesh) FaceIndices(Face *MeshFace, indices []int) {
-	C.NewtonMeshGetFaceIndices(m.handle, Face.handle, (*C.int)(unsafe.Pointer(&indices[0])))
+	cIndices := make([]C.int, len(indices))
+	C.NewtonMeshGetFaceIndices(m.ptr(), Face.handle, &cIndices[0])
+	for i := range cIndices {
+		indices[i] = int(cIndices[i])
+	}
 }
 
 func (m *Mesh) FacePointIndices(Face *MeshFace, indices []int) {
-	C.NewtonMeshGetFacePointIndices(m.handle, Face.handle, (*C.int)(unsafe.Pointer(&indices[0])))
+	cIndices := make([]C.int, len(indices))
+	C.NewtonMeshGetFacePointIndices(m.ptr(), Face.handle, &cIndices[0])
+	for i := range cIndices {
+		indices[i] = int(cIndices[i])
+	}
 }
 
 func (m *Mesh) CalculateFaceNormal(face *MeshFace, normal []float64) {
-	C.NewtonMeshCalculateFaceNormal(m.handle, face.handle, (*C.dFloat64)(&normal[0]))
+	C.NewtonMeshCalculateFaceNormal(m.ptr(), face.handle, (*C.dFloat64)(&normal[0]))
 }
 
 func (m *Mesh) SetFaceMaterial(face *MeshFace, matId int) {
-	C.NewtonMeshSetFaceMaterial(m.handle, face.handle, C.int(matId))
+	C.NewtonMeshSetFaceMaterial(m.ptr(), face.handle, C.int(matId))
 }
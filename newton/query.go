@@ -0,0 +1,218 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import "sort"
+
+//RayHit is a single intersection of a ray with a body
+type RayHit struct {
+	Body        *Body
+	Point       Vec3 //world space
+	Normal      Vec3
+	CollisionID int
+	Fraction    float32 //distance along the ray, 0 at the start and 1 at the end
+}
+
+//QueryOptions limits which bodies a ray cast, sweep or overlap query can hit
+type QueryOptions struct {
+	ExcludeMaterialGroups []int
+	ExcludeBodies         []*Body
+	Mask                  uint32 //only bodies on one of these layers are hit, 0 for every layer
+}
+
+//excluded returns true if the body should be skipped by the query
+func (o *QueryOptions) excluded(body *Body) bool {
+	if o.Mask != 0 && body.CollisionLayer()&o.Mask == 0 {
+		return true
+	}
+	for i := range o.ExcludeBodies {
+		if o.ExcludeBodies[i].handle == body.handle {
+			return true
+		}
+	}
+
+	if len(o.ExcludeMaterialGroups) == 0 {
+		return false
+	}
+	groupID := body.MaterialGroupID()
+	for i := range o.ExcludeMaterialGroups {
+		if o.ExcludeMaterialGroups[i] == groupID {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *QueryOptions) prefilter(body *Body, collision *Collision, userData interface{}) uint {
+	if o.excluded(body) {
+		return 0
+	}
+	return 1
+}
+
+func newRayHit(p0, p1 Vec3, body *Body, hitNormal *[3]float32, collisionID int, intersectParam float32) RayHit {
+	return RayHit{
+		Body:        body,
+		Point:       p0.Add(p1.Sub(p0).Scale(intersectParam)),
+		Normal:      Vec3(*hitNormal),
+		CollisionID: collisionID,
+		Fraction:    intersectParam,
+	}
+}
+
+//RayCastClosest returns the first body hit by the ray from p0 to p1.
+// The bool is false if nothing was hit
+func (w *World) RayCastClosest(p0, p1 Vec3, opts QueryOptions) (RayHit, bool) {
+	var hit RayHit
+	found := false
+
+	w.RayCast((*[3]float32)(&p0), (*[3]float32)(&p1),
+		func(body *Body, hitNormal *[3]float32, collisionID int, userData interface{}, intersectParam float32) float32 {
+			if !found || intersectParam < hit.Fraction {
+				hit = newRayHit(p0, p1, body, hitNormal, collisionID, intersectParam)
+				found = true
+			}
+			//clip the ray to this hit, so only closer bodies are reported after it
+			return intersectParam
+		}, nil, opts.prefilter)
+
+	return hit, found
+}
+
+//RayCastAll returns every body hit by the ray from p0 to p1, sorted from
+// closest to farthest
+func (w *World) RayCastAll(p0, p1 Vec3, opts QueryOptions) []RayHit {
+	var hits []RayHit
+
+	w.RayCast((*[3]float32)(&p0), (*[3]float32)(&p1),
+		func(body *Body, hitNormal *[3]float32, collisionID int, userData interface{}, intersectParam float32) float32 {
+			hits = append(hits, newRayHit(p0, p1, body, hitNormal, collisionID, intersectParam))
+			//keep the whole ray so every body is reported
+			return 1
+		}, nil, opts.prefilter)
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Fraction < hits[j].Fraction })
+	return hits
+}
+
+//SweepHit is the first contact of a shape swept through the world with a body
+type SweepHit struct {
+	Body        *Body
+	Point       Vec3 //world space
+	Normal      Vec3
+	Penetration float32
+	Fraction    float32 //time of impact, 0 at the start of the sweep and 1 at the end
+}
+
+//maxSweepContacts is the most contacts collected for a single body in a sweep
+const maxSweepContacts = 16
+
+//SweepShape moves the shape from the matrix from to the position to, and returns
+// the first contact with every body along the way, sorted by time of impact
+func (w *World) SweepShape(shape *Collision, from Mat4, to Vec3, opts QueryOptions) []SweepHit {
+	var hits []SweepHit
+
+	//Newton only reports the contacts at the earliest time of impact, so cast
+	// again past each body that's hit until the path is clear
+	hitBodies := make(map[owner]bool)
+	prefilter := func(body *Body, collision *Collision, userData interface{}) uint {
+		if hitBodies[owner(body.handle)] || opts.excluded(body) {
+			return 0
+		}
+		return 1
+	}
+
+	target := [16]float32{}
+	copy(target[:], to[:])
+
+	for {
+		var hitParam float32
+		info := w.ConvexCast((*[16]float32)(&from), &target, shape, &hitParam, nil, prefilter,
+			maxSweepContacts, 0)
+		if len(info) == 0 {
+			break
+		}
+
+		count := len(hits)
+		for i := range info {
+			if hitBodies[owner(info[i].HitBody.handle)] {
+				continue
+			}
+			hitBodies[owner(info[i].HitBody.handle)] = true
+			hits = append(hits, SweepHit{
+				Body:        info[i].HitBody,
+				Point:       Vec4(*info[i].Point).Vec3(),
+				Normal:      Vec4(*info[i].Normal).Vec3(),
+				Penetration: info[i].Penetration,
+				Fraction:    hitParam,
+			})
+		}
+		if len(hits) == count {
+			break
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Fraction < hits[j].Fraction })
+	return hits
+}
+
+//OverlapShape returns every body intersecting the shape placed at matrix, sorted by
+// distance from the shape's position
+func (w *World) OverlapShape(shape *Collision, matrix Mat4, opts QueryOptions) []*Body {
+	var p0, p1 [3]float32
+	shape.CalculateAABB((*[16]float32)(&matrix), &p0, &p1)
+
+	var bodies []*Body
+	w.ForEachBodyInAABBDo(&p0, &p1, func(body *Body, userData interface{}) {
+		if opts.excluded(body) {
+			return
+		}
+
+		bodyMatrix := body.Transform()
+		if w.CollisionIntersectionTest(shape, (*[16]float32)(&matrix), body.Collision(),
+			(*[16]float32)(&bodyMatrix), 0) {
+			bodies = append(bodies, body)
+		}
+	}, nil)
+
+	position := matrix.Position()
+	sort.Slice(bodies, func(i, j int) bool {
+		return bodies[i].Position().Sub(position).Len() < bodies[j].Position().Sub(position).Len()
+	})
+	return bodies
+}
+
+//OverlapSphere returns every body intersecting the sphere
+func (w *World) OverlapSphere(center Vec3, radius float32, opts QueryOptions) ([]*Body, error) {
+	shape, err := w.CreateSphere(radius, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer shape.Destroy()
+
+	return w.OverlapShape(shape, TranslationMatrix(center), opts), nil
+}
+
+//OverlapBox returns every body intersecting the box of the given size placed at matrix
+func (w *World) OverlapBox(size Vec3, matrix Mat4, opts QueryOptions) ([]*Body, error) {
+	shape, err := w.CreateBox(size[0], size[1], size[2], 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer shape.Destroy()
+
+	return w.OverlapShape(shape, matrix, opts), nil
+}
+
+//OverlapCapsule returns every body intersecting the capsule placed at matrix.
+// Like CreateCapsule, the capsule runs along the matrix's front (x) axis
+func (w *World) OverlapCapsule(radius, height float32, matrix Mat4, opts QueryOptions) ([]*Body, error) {
+	shape, err := w.CreateCapsule(radius, height, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer shape.Destroy()
+
+	return w.OverlapShape(shape, matrix, opts), nil
+}
@@ -0,0 +1,232 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+/*
+#include <stdint.h>
+#include "Newton.h"
+#include "callback.h"
+*/
+import "C"
+import (
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+//The registry replaces the old package level callback variables.
+// Handlers that belong to a Newton object (a world, body, joint or collision)
+// are kept in a handlers map keyed by that object's pointer, and handlers that
+// only live for the length of a single call into Newton (ray casts, iterators,
+// serialization, etc) are kept in a call that is passed through Newton's
+// userData pointer as a cgo.Handle.  Either way a callback only ever sees
+// the handler that was registered for its own world, object or query.
+
+//handlers is a concurrency safe map of Newton owners to their Go handlers
+type handlers[T any] struct {
+	sync.RWMutex
+	m map[owner]T
+}
+
+func newHandlers[T any]() *handlers[T] {
+	return &handlers[T]{m: make(map[owner]T)}
+}
+
+func (h *handlers[T]) get(o owner) (T, bool) {
+	h.RLock()
+	defer h.RUnlock()
+	v, ok := h.m[o]
+	return v, ok
+}
+
+func (h *handlers[T]) set(o owner, v T) {
+	h.Lock()
+	h.m[o] = v
+	h.Unlock()
+}
+
+func (h *handlers[T]) remove(o owner) {
+	h.Lock()
+	delete(h.m, o)
+	h.Unlock()
+}
+
+//call holds the go side of a single query into Newton.  It's only valid
+// until the query returns
+type call struct {
+	handle   cgo.Handle
+	userData interface{}
+
+	rayFilter         RayFilterHandler
+	rayPrefilter      RayPrefilterHandler
+	bodyIterator      BodyIteratorHandler
+	jointIterator     JointIteratorHandler
+	buoyancyPlane     BuoyancyPlaneHandler
+	collisionIterator CollisionIterator
+	serialize         SerializeCallback
+	deserialize       DeserializeCallback
+}
+
+//begin registers the call and returns the value to pass to Newton as userData
+func (c *call) begin() C.uintptr_t {
+	c.handle = cgo.NewHandle(c)
+	return C.uintptr_t(c.handle)
+}
+
+//end releases the call, Newton must not call back with it afterwards
+func (c *call) end() {
+	c.handle.Delete()
+}
+
+//callFromUserData looks up the call passed to Newton in begin
+func callFromUserData(userData unsafe.Pointer) *call {
+	return cgo.Handle(uintptr(userData)).Value().(*call)
+}
+
+type materialPair struct {
+	matid0, matid1 int
+}
+
+func newMaterialPair(matid0, matid1 int) materialPair {
+	if matid1 < matid0 {
+		return materialPair{matid1, matid0}
+	}
+	return materialPair{matid0, matid1}
+}
+
+//materialCallback holds the handlers for a single material pair
+type materialCallback struct {
+	world           *World
+	pair            materialPair
+	onAABBOverlap   OnAABBOverlapHandler
+	contactsProcess ContactsProcessHandler
+}
+
+//worldCallbacks holds the handlers that are registered against a world
+// rather than any one object in it, along with the meshes and collisions
+// the world is tracking
+type worldCallbacks struct {
+	sync.RWMutex
+	world          *World
+	bodyLeaveWorld BodyLeaveWorldHandler
+	materials      map[materialPair]cgo.Handle
+
+	materialGroups   int
+	materialDefaults map[materialPair]*materialDefaults
+	materialData     map[materialPair]interface{}
+	materialNames    map[string]int
+	pairCallbacks    bool //see installPairCallbacks
+
+	updates       uint64   //number of updates finished
+	pendingUpdate *float32 //timestep of an update started with UpdateAsync
+	triggers      map[*Body]*triggerHandlers
+	interpolated  map[*Body]*interpolation  //bodies interpolated by the world's steppers
+	oneWay        map[*Body]*oneWayPlatform //see Body.SetOneWay
+	contacts      *contactReport
+
+	autoRelease bool
+	leakReport  func(leaks []Leak)
+	resources   map[*resource]struct{}
+	pending     []*resource
+}
+
+var worlds = newHandlers[*worldCallbacks]()
+
+//callbacks returns the world's handlers, creating them if they don't exist yet
+func (w *World) callbacks() *worldCallbacks {
+	worlds.Lock()
+	defer worlds.Unlock()
+
+	cb, ok := worlds.m[owner(w.handle)]
+	if !ok {
+		cb = &worldCallbacks{
+			materials:        make(map[materialPair]cgo.Handle),
+			materialDefaults: make(map[materialPair]*materialDefaults),
+			materialData:     make(map[materialPair]interface{}),
+			materialNames:    make(map[string]int),
+			resources:        make(map[*resource]struct{}),
+		}
+		worlds.m[owner(w.handle)] = cb
+	}
+	return cb
+}
+
+//setMaterialCallback installs the handlers for the material pair, passing them to Newton
+// as the pair's userData.  Any handlers previously set for the pair are released once
+// the new ones are installed.  cb must be locked
+func (w *World) setMaterialCallback(cb *worldCallbacks, matid0, matid1 int, callback *materialCallback) {
+	pair := newMaterialPair(matid0, matid1)
+	callback.world = w
+	callback.pair = pair
+
+	h := cgo.NewHandle(callback)
+	C.SetCollisionCB(w.ptr(), C.int(matid0), C.int(matid1), C.uintptr_t(h))
+	if old, ok := cb.materials[pair]; ok {
+		old.Delete()
+	}
+	cb.materials[pair] = h
+}
+
+//releaseCallbacks removes every handler registered against the world
+func (w *World) releaseCallbacks() {
+	cb, ok := worlds.get(owner(w.handle))
+	if !ok {
+		return
+	}
+	worlds.remove(owner(w.handle))
+
+	cb.Lock()
+	defer cb.Unlock()
+	releaseMaterialCallbacks(cb)
+}
+
+//releaseMaterialCallbacks releases the handlers of every material pair.  cb must be locked
+func releaseMaterialCallbacks(cb *worldCallbacks) {
+	for _, h := range cb.materials {
+		h.Delete()
+	}
+	clear(cb.materials)
+}
+
+//materialCallbackFromUserData looks up the handlers for a material pair from the
+// userData set in setMaterialCallback
+func materialCallbackFromUserData(userData unsafe.Pointer) *materialCallback {
+	if userData == nil {
+		return nil
+	}
+	return cgo.Handle(uintptr(userData)).Value().(*materialCallback)
+}
+
+//installPairCallbacks installs the collision callbacks for every material pair
+// that doesn't already have them.  Collision filters and surface velocities are
+// applied from the callbacks, so they need to run whatever the bodies' material groups
+func (w *World) installPairCallbacks() {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	if cb.pairCallbacks {
+		return
+	}
+	cb.pairCallbacks = true
+
+	first := w.DefaultMaterialGroupID()
+	for id0 := first; id0 <= first+cb.materialGroups; id0++ {
+		for id1 := id0; id1 <= first+cb.materialGroups; id1++ {
+			w.installPairCallback(cb, id0, id1)
+		}
+	}
+}
+
+//installPairCallback installs the collision callbacks for the pair, if they're needed
+// for every pair and it doesn't already have them.  cb must be locked
+func (w *World) installPairCallback(cb *worldCallbacks, id0, id1 int) {
+	if !cb.pairCallbacks {
+		return
+	}
+	if _, ok := cb.materials[newMaterialPair(id0, id1)]; ok {
+		return
+	}
+	//no handlers, but the pair is still known to the Material in the callbacks
+	w.setMaterialCallback(cb, id0, id1, &materialCallback{})
+}
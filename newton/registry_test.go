@@ -0,0 +1,167 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+//seenBodies records the bodies a world's handlers are called with, and any that
+// aren't from that world
+type seenBodies struct {
+	sync.Mutex
+	world   map[*Body]bool
+	seen    map[*Body]bool
+	foreign []string
+}
+
+func (s *seenBodies) see(handler string, bodies ...*Body) {
+	s.Lock()
+	defer s.Unlock()
+	for _, body := range bodies {
+		s.seen[body] = true
+		if !s.world[body] {
+			s.foreign = append(s.foreign, fmt.Sprintf("%s called with body %p", handler, body))
+		}
+	}
+}
+
+//TestWorldsConcurrent updates several worlds on their own goroutines, each with its own
+// force and torque and material handlers, and checks none of the handlers are called
+// with another world's bodies
+func TestWorldsConcurrent(t *testing.T) {
+	const (
+		worldCount = 4
+		steps      = 120
+	)
+
+	var wg sync.WaitGroup
+	results := make([]*seenBodies, worldCount)
+	for i := range results {
+		w, err := CreateWorld()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Destroy()
+
+		//a different number of boxes in each world, so their bodies can't line up
+		createTestScene(t, w, 2+i)
+		s := &seenBodies{world: make(map[*Body]bool), seen: make(map[*Body]bool)}
+		for body := range w.Bodies() {
+			s.world[body] = true
+		}
+		results[i] = s
+
+		force := [3]float32{0, -10, 0}
+		for body := range w.Bodies() {
+			body.SetForceAndTorqueCallback(func(body *Body, timestep float32, threadIndex int) {
+				s.see("force and torque", body)
+				body.SetForce(&force)
+			})
+		}
+
+		id := w.DefaultMaterialGroupID()
+		w.SetMaterialCollisionCallback(id, id, i,
+			func(material *Material, body0, body1 *Body, threadIndex int) int {
+				s.see("AABB overlap", body0, body1)
+				if data := material.UserData(); data != i {
+					s.Lock()
+					s.foreign = append(s.foreign, fmt.Sprintf("AABB overlap has user data %v", data))
+					s.Unlock()
+				}
+				return 1
+			},
+			func(contact *Joint, timestep float32, threadIndex int) {
+				s.see("contacts process", contact.Body0(), contact.Body1())
+			})
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for step := 0; step < steps; step++ {
+				w.Update(testTimestep)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, s := range results {
+		for _, foreign := range s.foreign {
+			t.Errorf("world %d: %s", i, foreign)
+		}
+		if len(s.seen) != len(s.world) {
+			t.Errorf("world %d: handlers saw %d of its %d bodies", i, len(s.seen), len(s.world))
+		}
+	}
+}
+
+//TestQueriesConcurrent runs ray casts and AABB queries on two worlds at once, and checks
+// each query's handlers only see the user data and bodies it was called with
+func TestQueriesConcurrent(t *testing.T) {
+	const (
+		worldCount = 2
+		queries    = 500
+	)
+
+	var wg sync.WaitGroup
+	results := make([]*seenBodies, worldCount)
+	for i := range results {
+		w, err := CreateWorld()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Destroy()
+
+		createTestScene(t, w, 2+i)
+		s := &seenBodies{world: make(map[*Body]bool), seen: make(map[*Body]bool)}
+		for body := range w.Bodies() {
+			s.world[body] = true
+		}
+		results[i] = s
+
+		checkData := func(handler string, data interface{}) {
+			if data != i {
+				s.Lock()
+				s.foreign = append(s.foreign, fmt.Sprintf("%s has user data %v", handler, data))
+				s.Unlock()
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			//straight down through the boxes and into the floor, and around everything
+			p0, p1 := [3]float32{0, 100, 0}, [3]float32{0, -100, 0}
+			boxMin, boxMax := [3]float32{-100, -100, -100}, [3]float32{100, 100, 100}
+			for q := 0; q < queries; q++ {
+				w.RayCast(&p0, &p1, func(body *Body, hitNormal *[3]float32, collisionID int,
+					userData interface{}, intersectParam float32) float32 {
+					s.see("ray filter", body)
+					checkData("ray filter", userData)
+					return intersectParam
+				}, i, func(body *Body, collision *Collision, userData interface{}) uint {
+					s.see("ray prefilter", body)
+					checkData("ray prefilter", userData)
+					return 1
+				})
+				w.ForEachBodyInAABBDo(&boxMin, &boxMax, func(body *Body, userData interface{}) {
+					s.see("AABB iterator", body)
+					checkData("AABB iterator", userData)
+				}, i)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, s := range results {
+		for _, foreign := range s.foreign {
+			t.Errorf("world %d: %s", i, foreign)
+		}
+		if len(s.seen) != len(s.world) {
+			t.Errorf("world %d: queries saw %d of its %d bodies", i, len(s.seen), len(s.world))
+		}
+	}
+}
@@ -0,0 +1,593 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	ErrWorldFormat      = errors.New("newton: invalid world data")
+	ErrUnknownCallback  = errors.New("newton: no callback registered with that name")
+	ErrDeformableSave   = errors.New("newton: deformable bodies can't be serialized")
+	errCollisionMissing = errors.New("newton: body's collision isn't in the world data")
+)
+
+//UserDataCodec converts user data to and from bytes, so it can be saved with a world
+type UserDataCodec interface {
+	EncodeUserData(data interface{}) ([]byte, error)
+	DecodeUserData(data []byte) (interface{}, error)
+}
+
+//GobCodec saves user data with encoding/gob.  Every concrete type stored as user
+// data needs to be registered with gob.Register
+type GobCodec struct{}
+
+func (GobCodec) EncodeUserData(data interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	err := gob.NewEncoder(buf).Encode(&data)
+	return buf.Bytes(), err
+}
+
+func (GobCodec) DecodeUserData(data []byte) (interface{}, error) {
+	var result interface{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&result)
+	return result, err
+}
+
+var userDataCodec struct {
+	sync.RWMutex
+	codec UserDataCodec
+}
+
+//SetUserDataCodec sets how the world, body and joint user data is saved by
+// World.Serialize and loaded by LoadWorld.  With no codec user data isn't saved
+func SetUserDataCodec(codec UserDataCodec) {
+	userDataCodec.Lock()
+	userDataCodec.codec = codec
+	userDataCodec.Unlock()
+}
+
+func encodeUserData(o owner) ([]byte, error) {
+	userDataCodec.RLock()
+	codec := userDataCodec.codec
+	userDataCodec.RUnlock()
+
+	data, ok := ownerData.get(o)
+	if codec == nil || !ok {
+		return nil, nil
+	}
+	return codec.EncodeUserData(data)
+}
+
+func decodeUserData(data []byte) (interface{}, bool, error) {
+	userDataCodec.RLock()
+	codec := userDataCodec.codec
+	userDataCodec.RUnlock()
+
+	if codec == nil || data == nil {
+		return nil, false, nil
+	}
+	result, err := codec.DecodeUserData(data)
+	return result, err == nil, err
+}
+
+//named holds callbacks registered by name, so they can be bound again to the
+// bodies of a loaded world
+type named[T any] struct {
+	sync.RWMutex
+	m map[string]T
+}
+
+func (n *named[T]) get(name string) (T, bool) {
+	n.RLock()
+	defer n.RUnlock()
+	v, ok := n.m[name]
+	return v, ok
+}
+
+func (n *named[T]) set(name string, v T) {
+	n.Lock()
+	n.m[name] = v
+	n.Unlock()
+}
+
+var (
+	namedForceAndTorque = &named[ApplyForceAndTorque]{m: make(map[string]ApplyForceAndTorque)}
+	namedTransform      = &named[TransformCallback]{m: make(map[string]TransformCallback)}
+
+	forceAndTorqueNames = newHandlers[string]()
+	transformNames      = newHandlers[string]()
+)
+
+//RegisterForceAndTorque registers the callback under name for SetNamedForceAndTorqueCallback
+func RegisterForceAndTorque(name string, callback ApplyForceAndTorque) {
+	namedForceAndTorque.set(name, callback)
+}
+
+//RegisterTransformCallback registers the callback under name for SetNamedTransformCallback
+func RegisterTransformCallback(name string, callback TransformCallback) {
+	namedTransform.set(name, callback)
+}
+
+//SetNamedForceAndTorqueCallback sets the body's force and torque callback to the one
+// registered as name.  Unlike SetForceAndTorqueCallback, the callback is saved by
+// World.Serialize and bound again by LoadWorld
+func (b *Body) SetNamedForceAndTorqueCallback(name string) error {
+	callback, ok := namedForceAndTorque.get(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCallback, name)
+	}
+	b.SetForceAndTorqueCallback(callback)
+	forceAndTorqueNames.set(owner(b.handle), name)
+	return nil
+}
+
+//SetNamedTransformCallback sets the body's transform callback to the one registered
+// as name.  Unlike SetTransformCallback, the callback is saved by World.Serialize
+// and bound again by LoadWorld
+func (b *Body) SetNamedTransformCallback(name string) error {
+	callback, ok := namedTransform.get(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCallback, name)
+	}
+	b.SetTransformCallback(callback)
+	transformNames.set(owner(b.handle), name)
+	return nil
+}
+
+//kinds of JointDefinition
+const (
+	JointBall      = "ball"
+	JointHinge     = "hinge"
+	JointSlider    = "slider"
+	JointCorkscrew = "corkscrew"
+	JointUniversal = "universal"
+	JointUpVector  = "up vector"
+)
+
+//jointSpec is how a joint was created.  Points and directions are kept in the
+// child body's space, so the joint can be created again wherever the bodies are
+type jointSpec struct {
+	sync.Mutex
+	kind          string
+	child, parent *Body
+	pivot         Vec3
+	pins          []Vec3
+
+	cone                        bool
+	conePin                     Vec3
+	maxConeAngle, maxTwistAngle float32
+}
+
+var jointSpecs = newHandlers[*jointSpec]()
+
+func newJointSpec(kind string, child, parent *Body, pivot *[3]float32, pins ...*[3]float32) *jointSpec {
+	inverse := child.Transform().Inverse()
+
+	spec := &jointSpec{kind: kind, child: child, parent: parent}
+	if pivot != nil {
+		spec.pivot = inverse.TransformPoint(Vec3(*pivot))
+	}
+	for _, pin := range pins {
+		spec.pins = append(spec.pins, inverse.RotateVector(Vec3(*pin)))
+	}
+	return spec
+}
+
+//JointDefinition is how a joint was created.  Points and directions are in the
+// child body's space
+type JointDefinition struct {
+	Kind          string
+	Child, Parent *Body //Parent is nil for joints attached to the world
+	Pivot         Vec3
+	Pins          []Vec3
+}
+
+//Definition returns how the joint was created, false if it wasn't created by one
+// of the World.Create joint functions
+func (j *Joint) Definition() (JointDefinition, bool) {
+	spec, ok := jointSpecs.get(owner(j.ptr()))
+	if !ok {
+		return JointDefinition{}, false
+	}
+	spec.Lock()
+	defer spec.Unlock()
+
+	return JointDefinition{
+		Kind:   spec.kind,
+		Child:  spec.child,
+		Parent: spec.parent,
+		Pivot:  spec.pivot,
+		Pins:   append([]Vec3(nil), spec.pins...),
+	}, true
+}
+
+func (spec *jointSpec) setConeLimits(pin *[3]float32, maxConeAngle, maxTwistAngle float32) {
+	spec.Lock()
+	defer spec.Unlock()
+
+	spec.cone = true
+	spec.conePin = spec.child.Transform().Inverse().RotateVector(Vec3(*pin))
+	spec.maxConeAngle = maxConeAngle
+	spec.maxTwistAngle = maxTwistAngle
+}
+
+//world encoding
+// magic and version, followed by a gob encoded savedWorld
+var worldMagic = [4]byte{'N', 'W', 'L', 'D'}
+
+const worldVersion = 1
+
+type savedWorld struct {
+	BroadphaseAlgorithm int
+	MaterialGroups      int
+	Materials           []savedMaterial
+	Collisions          [][]byte
+	Bodies              []savedBody
+	Joints              []savedJoint
+	UserData            []byte
+}
+
+type savedMaterial struct {
+	Matid0, Matid1  int
+	Set             uint8
+	Thickness       float32
+	Softness        float32
+	Elasticity      float32
+	Collidable      int
+	StaticFriction  float32
+	KineticFriction float32
+}
+
+type savedBody struct {
+	Type                    int
+	Collision               int
+	Matrix                  Mat4
+	Mass, Ixx, Iyy, Izz     float32
+	CentreOfMass            Vec3
+	Velocity                Vec3
+	Omega                   Vec3
+	LinearDamping           float32
+	AngularDamping          Vec3
+	MaterialGroupID         int
+	ContinuousCollisionMode int
+	JointRecursiveCollision int
+	AutoSleep               int
+	FreezeState             int
+	SleepState              int
+	ForceAndTorque          string
+	Transform               string
+	UserData                []byte
+}
+
+type savedJoint struct {
+	Kind                        string
+	Child, Parent               int //index into Bodies, a Parent of -1 is the world
+	Pivot                       Vec3
+	Pins                        []Vec3
+	Cone                        bool
+	ConePin                     Vec3
+	MaxConeAngle, MaxTwistAngle float32
+	Stiffness                   float32
+	CollisionState              int
+	UserData                    []byte
+}
+
+//Serialize writes the world's bodies, collisions, material defaults and joints to out,
+// so it can be loaded again with LoadWorld.
+// Go side state is saved as well: user data if a codec has been set with SetUserDataCodec,
+// and force and torque and transform callbacks set with SetNamedForceAndTorqueCallback
+// and SetNamedTransformCallback.  Other callbacks aren't saved.
+// Joints are saved if they were created with one of the World.Create joint functions
+func (w *World) Serialize(out io.Writer) error {
+	saved := savedWorld{BroadphaseAlgorithm: w.BroadphaseAlgorithm()}
+
+	cb := w.callbacks()
+	cb.RLock()
+	saved.MaterialGroups = cb.materialGroups
+	for pair, d := range cb.materialDefaults {
+		saved.Materials = append(saved.Materials, savedMaterial{
+			Matid0:          pair.matid0,
+			Matid1:          pair.matid1,
+			Set:             d.set,
+			Thickness:       d.thickness,
+			Softness:        d.softness,
+			Elasticity:      d.elasticity,
+			Collidable:      d.collidable,
+			StaticFriction:  d.staticFriction,
+			KineticFriction: d.kineticFriction,
+		})
+	}
+	cb.RUnlock()
+
+	var err error
+	if saved.UserData, err = encodeUserData(owner(w.handle)); err != nil {
+		return err
+	}
+
+	collisions := make(map[owner]int)
+	bodies := make(map[owner]int)
+	for body := range w.Bodies() {
+		if body.Type() == BodyDeformable {
+			return ErrDeformableSave
+		}
+
+		collision := body.Collision()
+		index, ok := collisions[owner(collision.handle)]
+		if !ok {
+			data, err := collision.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			index = len(saved.Collisions)
+			collisions[owner(collision.handle)] = index
+			saved.Collisions = append(saved.Collisions, data)
+		}
+
+		sb := savedBody{
+			Type:                    body.Type(),
+			Collision:               index,
+			Matrix:                  body.Transform(),
+			LinearDamping:           body.LinearDamping(),
+			MaterialGroupID:         body.MaterialGroupID(),
+			ContinuousCollisionMode: body.ContinuousCollisionMode(),
+			JointRecursiveCollision: body.JointRecursiveCollision(),
+			AutoSleep:               body.AutoSleep(),
+			FreezeState:             body.FreezeState(),
+			SleepState:              body.SleepState(),
+		}
+		body.MassMatrix(&sb.Mass, &sb.Ixx, &sb.Iyy, &sb.Izz)
+		body.CentreOfMass((*[3]float32)(&sb.CentreOfMass))
+		body.Velocity((*[3]float32)(&sb.Velocity))
+		body.Omega((*[3]float32)(&sb.Omega))
+		body.AngularDamping((*[3]float32)(&sb.AngularDamping))
+		sb.ForceAndTorque, _ = forceAndTorqueNames.get(owner(body.handle))
+		sb.Transform, _ = transformNames.get(owner(body.handle))
+		if sb.UserData, err = encodeUserData(owner(body.handle)); err != nil {
+			return err
+		}
+
+		bodies[owner(body.handle)] = len(saved.Bodies)
+		saved.Bodies = append(saved.Bodies, sb)
+	}
+
+	for _, joint := range w.joints() {
+		spec, ok := jointSpecs.get(owner(joint.handle))
+		if !ok {
+			continue
+		}
+
+		spec.Lock()
+		sj := savedJoint{
+			Kind:           spec.kind,
+			Child:          bodies[owner(spec.child.handle)],
+			Parent:         -1,
+			Pivot:          spec.pivot,
+			Pins:           spec.pins,
+			Cone:           spec.cone,
+			ConePin:        spec.conePin,
+			MaxConeAngle:   spec.maxConeAngle,
+			MaxTwistAngle:  spec.maxTwistAngle,
+			Stiffness:      joint.Stiffness(),
+			CollisionState: joint.CollisionState(),
+		}
+		if spec.parent != nil {
+			sj.Parent = bodies[owner(spec.parent.handle)]
+		}
+		spec.Unlock()
+
+		if sj.UserData, err = encodeUserData(owner(joint.handle)); err != nil {
+			return err
+		}
+		saved.Joints = append(saved.Joints, sj)
+	}
+
+	if _, err := out.Write(append(worldMagic[:], worldVersion)); err != nil {
+		return err
+	}
+	return gob.NewEncoder(out).Encode(&saved)
+}
+
+//LoadWorld creates a new world from one written by World.Serialize.  Named callbacks
+// must be registered, and the user data codec set, before the world is loaded
+func LoadWorld(r io.Reader) (*World, error) {
+	header := make([]byte, len(worldMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrWorldFormat
+	}
+	if !bytes.Equal(header[:len(worldMagic)], worldMagic[:]) || header[len(worldMagic)] != worldVersion {
+		return nil, ErrWorldFormat
+	}
+
+	var saved savedWorld
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWorldFormat, err)
+	}
+
+	w, err := CreateWorld()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.load(&saved); err != nil {
+		w.Destroy()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *World) load(saved *savedWorld) error {
+	w.SetBroadphaseAlgorithm(saved.BroadphaseAlgorithm)
+	if err := w.loadUserData(owner(w.handle), saved.UserData); err != nil {
+		return err
+	}
+
+	for i := 0; i < saved.MaterialGroups; i++ {
+		w.CreateMaterialGroupID()
+	}
+	for _, m := range saved.Materials {
+		if m.Set&materialThickness != 0 {
+			w.SetMaterialSurfaceThickness(m.Matid0, m.Matid1, m.Thickness)
+		}
+		if m.Set&materialSoftness != 0 {
+			w.SetMaterialDefaultSoftness(m.Matid0, m.Matid1, m.Softness)
+		}
+		if m.Set&materialElasticity != 0 {
+			w.SetMaterialDefaultElasticity(m.Matid0, m.Matid1, m.Elasticity)
+		}
+		if m.Set&materialCollidable != 0 {
+			w.SetMaterialDefaultCollidable(m.Matid0, m.Matid1, m.Collidable)
+		}
+		if m.Set&materialFriction != 0 {
+			w.SetMaterialDefaultFriction(m.Matid0, m.Matid1, m.StaticFriction, m.KineticFriction)
+		}
+	}
+
+	//the bodies hold their own reference to their collision
+	collisions := make([]*Collision, len(saved.Collisions))
+	defer func() {
+		for _, collision := range collisions {
+			if collision != nil {
+				collision.Destroy()
+			}
+		}
+	}()
+	for i := range saved.Collisions {
+		collision, err := w.UnmarshalCollision(saved.Collisions[i])
+		if err != nil {
+			return err
+		}
+		collisions[i] = collision
+	}
+
+	bodies := make([]*Body, len(saved.Bodies))
+	for i := range saved.Bodies {
+		sb := &saved.Bodies[i]
+		if sb.Collision < 0 || sb.Collision >= len(collisions) {
+			return errCollisionMissing
+		}
+
+		var body *Body
+		var err error
+		matrix := [16]float32(sb.Matrix)
+		if sb.Type == BodyKinematic {
+			body, err = w.CreateKinematicBody(collisions[sb.Collision], &matrix)
+		} else {
+			body, err = w.CreateDynamicBody(collisions[sb.Collision], &matrix)
+		}
+		if err != nil {
+			return err
+		}
+		bodies[i] = body
+
+		body.SetMassMatrix(sb.Mass, sb.Ixx, sb.Iyy, sb.Izz)
+		body.SetCentreOfMass((*[3]float32)(&sb.CentreOfMass))
+		body.SetVelocity((*[3]float32)(&sb.Velocity))
+		body.SetOmega((*[3]float32)(&sb.Omega))
+		body.SetLinearDamping(sb.LinearDamping)
+		body.SetAngularDamping((*[3]float32)(&sb.AngularDamping))
+		body.SetMaterialGroupID(sb.MaterialGroupID)
+		body.SetContinuousCollisionMode(uint(sb.ContinuousCollisionMode))
+		body.SetJointRecursiveCollision(uint(sb.JointRecursiveCollision))
+		body.SetAutoSleep(sb.AutoSleep)
+		body.SetFreezeState(sb.FreezeState)
+		body.SetSleepState(sb.SleepState)
+
+		if sb.ForceAndTorque != "" {
+			if err := body.SetNamedForceAndTorqueCallback(sb.ForceAndTorque); err != nil {
+				return err
+			}
+		}
+		if sb.Transform != "" {
+			if err := body.SetNamedTransformCallback(sb.Transform); err != nil {
+				return err
+			}
+		}
+		if err := w.loadUserData(owner(body.handle), sb.UserData); err != nil {
+			return err
+		}
+	}
+
+	for i := range saved.Joints {
+		joint, err := w.loadJoint(&saved.Joints[i], bodies)
+		if err != nil {
+			return err
+		}
+		if err := w.loadUserData(owner(joint.handle), saved.Joints[i].UserData); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *World) loadUserData(o owner, data []byte) error {
+	userData, ok, err := decodeUserData(data)
+	if err != nil {
+		return err
+	}
+	if ok {
+		ownerData.set(o, userData)
+	}
+	return nil
+}
+
+func (w *World) loadJoint(sj *savedJoint, bodies []*Body) (*Joint, error) {
+	if sj.Child < 0 || sj.Child >= len(bodies) || sj.Parent >= len(bodies) {
+		return nil, ErrWorldFormat
+	}
+	child := bodies[sj.Child]
+	var parent *Body
+	if sj.Parent >= 0 {
+		parent = bodies[sj.Parent]
+	}
+
+	matrix := child.Transform()
+	pivot := [3]float32(matrix.TransformPoint(sj.Pivot))
+	pins := make([][3]float32, len(sj.Pins))
+	for i := range sj.Pins {
+		pins[i] = [3]float32(matrix.RotateVector(sj.Pins[i]))
+	}
+	pin := func(i int) *[3]float32 {
+		if i >= len(pins) {
+			return nil
+		}
+		return &pins[i]
+	}
+
+	var joint *Joint
+	var err error
+	switch sj.Kind {
+	case JointBall:
+		joint, err = w.CreateBall(&pivot, child, parent)
+	case JointHinge:
+		joint, err = w.CreateHinge(&pivot, pin(0), child, parent)
+	case JointSlider:
+		joint, err = w.CreateSlider(&pivot, pin(0), child, parent)
+	case JointCorkscrew:
+		joint, err = w.CreateCorkscrew(&pivot, pin(0), child, parent)
+	case JointUniversal:
+		joint, err = w.CreateUniversal(&pivot, pin(0), pin(1), child, parent)
+	case JointUpVector:
+		joint, err = w.CreateUpVector(pin(0), child)
+	default:
+		return nil, fmt.Errorf("%w: unknown joint %q", ErrWorldFormat, sj.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sj.Cone {
+		conePin := [3]float32(matrix.RotateVector(sj.ConePin))
+		joint.SetBallConeLimits(&conePin, sj.MaxConeAngle, sj.MaxTwistAngle)
+	}
+	joint.SetStiffness(sj.Stiffness)
+	joint.SetCollisionState(sj.CollisionState)
+	return joint, nil
+}
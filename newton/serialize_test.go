@@ -0,0 +1,225 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+//sortedBodies returns the world's bodies ordered by position, so the bodies of a
+// loaded world line up with the ones it was saved from
+func sortedBodies(w *World) []*Body {
+	bodies := slices.Collect(w.Bodies())
+	slices.SortFunc(bodies, func(a, b *Body) int {
+		ma, mb := a.Transform(), b.Transform()
+		if ma[12] != mb[12] {
+			return int(math.Copysign(1, float64(ma[12]-mb[12])))
+		}
+		return int(math.Copysign(1, float64(ma[13]-mb[13])))
+	})
+	return bodies
+}
+
+func nearVec(a, b []float32) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > 1e-4 {
+			return false
+		}
+	}
+	return true
+}
+
+func compareBodyStates(t *testing.T, when string, got, want []bodyState) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: %d bodies, want %d", when, len(got), len(want))
+	}
+	for i := range want {
+		if !nearVec(got[i].matrix[:], want[i].matrix[:]) {
+			t.Errorf("%s: body %d transform is %v, want %v", when, i, got[i].matrix, want[i].matrix)
+		}
+		if !nearVec(got[i].velocity[:], want[i].velocity[:]) {
+			t.Errorf("%s: body %d velocity is %v, want %v", when, i, got[i].velocity, want[i].velocity)
+		}
+		if !nearVec(got[i].omega[:], want[i].omega[:]) {
+			t.Errorf("%s: body %d omega is %v, want %v", when, i, got[i].omega, want[i].omega)
+		}
+	}
+}
+
+//createSavedScene is the test scene with its boxes falling under a named callback,
+// the first two boxes joined by a ball and the last hinged to the world
+func createSavedScene(t *testing.T) *World {
+	t.Helper()
+
+	RegisterForceAndTorque("test gravity", func(body *Body, timestep float32, threadIndex int) {
+		force := [3]float32{0, -10, 0}
+		body.SetForce(&force)
+	})
+
+	w, err := CreateWorld()
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxes := createTestScene(t, w, 3)
+	for _, box := range boxes {
+		if err := box.SetNamedForceAndTorqueCallback("test gravity"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pivot := [3]float32{0.3, 3.75, 0}
+	if _, err := w.CreateBall(&pivot, boxes[1], boxes[0]); err != nil {
+		t.Fatal(err)
+	}
+	pivot = [3]float32{1.2, 6, 0}
+	pin := [3]float32{0, 0, 1}
+	if _, err := w.CreateHinge(&pivot, &pin, boxes[2], nil); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestSerializeWorld(t *testing.T) {
+	const (
+		before = 10 //steps before saving
+		after  = 10 //steps after loading, before the boxes reach the floor
+	)
+
+	w := createSavedScene(t)
+	defer w.Destroy()
+	for i := 0; i < before; i++ {
+		w.Update(testTimestep)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := w.Serialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadWorld(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Destroy()
+
+	bodies, loadedBodies := sortedBodies(w), sortedBodies(loaded)
+	compareBodyStates(t, "loaded", bodyStates(loadedBodies), bodyStates(bodies))
+	for i, body := range loadedBodies {
+		want, _ := forceAndTorqueNames.get(owner(bodies[i].handle))
+		if got, _ := forceAndTorqueNames.get(owner(body.handle)); got != want {
+			t.Errorf("body %d force and torque callback is %q, want %q", i, got, want)
+		}
+	}
+
+	//the joints, with their bodies matched up by position
+	index := func(bodies []*Body, body *Body) int {
+		if body == nil {
+			return -1
+		}
+		for i := range bodies {
+			if bodies[i].handle == body.handle {
+				return i
+			}
+		}
+		return -2
+	}
+	definitions := func(w *World, bodies []*Body) map[[2]int]JointDefinition {
+		defs := make(map[[2]int]JointDefinition)
+		for _, joint := range w.joints() {
+			def, ok := joint.Definition()
+			if !ok {
+				t.Fatal("joint has no definition")
+			}
+			defs[[2]int{index(bodies, def.Child), index(bodies, def.Parent)}] = def
+		}
+		return defs
+	}
+	want, got := definitions(w, bodies), definitions(loaded, loadedBodies)
+	if len(got) != len(want) {
+		t.Fatalf("loaded %d joints, want %d", len(got), len(want))
+	}
+	for key, wantDef := range want {
+		gotDef, ok := got[key]
+		if !ok {
+			t.Errorf("no joint between bodies %v", key)
+			continue
+		}
+		if gotDef.Kind != wantDef.Kind || !nearVec(gotDef.Pivot[:], wantDef.Pivot[:]) ||
+			len(gotDef.Pins) != len(wantDef.Pins) {
+			t.Errorf("joint between bodies %v is %+v, want %+v", key, gotDef, wantDef)
+			continue
+		}
+		for i := range wantDef.Pins {
+			if !nearVec(gotDef.Pins[i][:], wantDef.Pins[i][:]) {
+				t.Errorf("joint between bodies %v pin %d is %v, want %v", key, i, gotDef.Pins[i], wantDef.Pins[i])
+			}
+		}
+	}
+
+	//both worlds carry on the same way, which needs the named callbacks bound again
+	for i := 0; i < after; i++ {
+		w.Update(testTimestep)
+		loaded.Update(testTimestep)
+	}
+	compareBodyStates(t, "stepped", bodyStates(loadedBodies), bodyStates(bodies))
+}
+
+func TestLoadWorldUnknownJoint(t *testing.T) {
+	w := createSavedScene(t)
+	defer w.Destroy()
+
+	buf := &bytes.Buffer{}
+	if err := w.Serialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	header := buf.Next(len(worldMagic) + 1)
+	var saved savedWorld
+	if err := gob.NewDecoder(buf).Decode(&saved); err != nil {
+		t.Fatal(err)
+	}
+	saved.Joints[0].Kind = "weld"
+
+	data := bytes.NewBuffer(slices.Clone(header))
+	if err := gob.NewEncoder(data).Encode(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadWorld(data); !errors.Is(err, ErrWorldFormat) {
+		if loaded != nil {
+			loaded.Destroy()
+		}
+		t.Errorf("got error %v, want ErrWorldFormat", err)
+	}
+}
+
+func TestLoadWorldHeader(t *testing.T) {
+	valid := func() []byte {
+		buf := bytes.NewBuffer(append(worldMagic[:], worldVersion))
+		if err := gob.NewEncoder(buf).Encode(&savedWorld{}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", append([]byte("NWLX"), valid()[len(worldMagic):]...)},
+		{"newer version", func() []byte { data := valid(); data[len(worldMagic)]++; return data }()},
+		{"older version", func() []byte { data := valid(); data[len(worldMagic)] = 0; return data }()},
+		{"truncated", func() []byte { data := valid(); return data[:len(data)-2] }()},
+	}
+
+	for _, test := range tests {
+		if _, err := LoadWorld(bytes.NewReader(test.data)); !errors.Is(err, ErrWorldFormat) {
+			t.Errorf("%s: got error %v, want ErrWorldFormat", test.name, err)
+		}
+	}
+}
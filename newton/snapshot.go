@@ -0,0 +1,234 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	ErrSnapshotMismatch = errors.New("newton: snapshot doesn't match the world's bodies and joints")
+	ErrSnapshotFormat   = errors.New("newton: invalid snapshot data")
+)
+
+//BodyState is the dynamic state of a single body
+type BodyState struct {
+	Matrix      Mat4
+	Velocity    Vec3
+	Omega       Vec3
+	Force       Vec3 //force accumulator
+	Torque      Vec3 //torque accumulator
+	SleepState  int
+	FreezeState int
+}
+
+//JointState is the state of a single joint
+type JointState struct {
+	CollisionState int
+	Stiffness      float32
+}
+
+//Snapshot is the dynamic state of every body and joint in a world at one point in time.
+// Bodies and joints are stored in the order the world iterates them, so a snapshot
+// can only be restored to a world with the same bodies and joints created in the
+// same order, such as the same world or a copy of it on another machine
+type Snapshot struct {
+	Bodies []BodyState
+	Joints []JointState
+}
+
+//joints returns every joint in the world in iteration order
+func (w *World) joints() []*Joint {
+	var joints []*Joint
+	w.ForEachJointDo(func(joint *Joint, userData interface{}) {
+		joints = append(joints, joint)
+	}, nil)
+	return joints
+}
+
+//Snapshot saves the state of every body and joint in the world.  The world itself
+// isn't changed, so taking a snapshot doesn't disturb the simulation
+func (w *World) Snapshot() *Snapshot {
+	s := &Snapshot{}
+
+	for body := range w.Bodies() {
+		state := BodyState{
+			Matrix:      body.Transform(),
+			SleepState:  body.SleepState(),
+			FreezeState: body.FreezeState(),
+		}
+		body.Velocity((*[3]float32)(&state.Velocity))
+		body.Omega((*[3]float32)(&state.Omega))
+		body.ForceAcc((*[3]float32)(&state.Force))
+		body.TorqueAcc((*[3]float32)(&state.Torque))
+		s.Bodies = append(s.Bodies, state)
+	}
+
+	for _, joint := range w.joints() {
+		s.Joints = append(s.Joints, JointState{
+			CollisionState: joint.CollisionState(),
+			Stiffness:      joint.Stiffness(),
+		})
+	}
+
+	return s
+}
+
+//Restore puts every body and joint in the world back to the state saved in the
+// snapshot.  Returns ErrSnapshotMismatch if the world doesn't have the same number
+// of bodies and joints as the snapshot, in which case the world isn't changed.
+// Newton's contact cache is cleared, since the contacts in it are from the world's
+// current state rather than the snapshot's
+func (w *World) Restore(s *Snapshot) error {
+	joints := w.joints()
+	if w.BodyCount() != len(s.Bodies) || len(joints) != len(s.Joints) {
+		return ErrSnapshotMismatch
+	}
+
+	i := 0
+	for body := range w.Bodies() {
+		state := &s.Bodies[i]
+		body.SetTransform(state.Matrix)
+		body.SetVelocity((*[3]float32)(&state.Velocity))
+		body.SetOmega((*[3]float32)(&state.Omega))
+		body.SetForce((*[3]float32)(&state.Force))
+		body.SetTorque((*[3]float32)(&state.Torque))
+		body.SetFreezeState(state.FreezeState)
+		body.SetSleepState(state.SleepState)
+		i++
+	}
+
+	for i, joint := range joints {
+		joint.SetCollisionState(s.Joints[i].CollisionState)
+		joint.SetStiffness(s.Joints[i].Stiffness)
+	}
+
+	w.InvalidateCache()
+	return nil
+}
+
+//snapshot encoding, little endian
+// header: magic, version, body count, joint count
+// followed by a bodyRecord for each body and a jointRecord for each joint
+var snapshotMagic = [4]byte{'N', 'S', 'N', 'P'}
+
+const snapshotVersion = 1
+
+type snapshotHeader struct {
+	Magic      [4]byte
+	Version    uint8
+	BodyCount  uint32
+	JointCount uint32
+}
+
+type bodyRecord struct {
+	Matrix      [16]float32
+	Velocity    [3]float32
+	Omega       [3]float32
+	Force       [3]float32
+	Torque      [3]float32
+	SleepState  uint8
+	FreezeState uint8
+}
+
+type jointRecord struct {
+	CollisionState uint8
+	Stiffness      float32
+}
+
+//MarshalBinary encodes the snapshot in a compact binary form
+func (s *Snapshot) MarshalBinary() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.Grow(binary.Size(snapshotHeader{}) + len(s.Bodies)*binary.Size(bodyRecord{}) +
+		len(s.Joints)*binary.Size(jointRecord{}))
+
+	header := snapshotHeader{
+		Magic:      snapshotMagic,
+		Version:    snapshotVersion,
+		BodyCount:  uint32(len(s.Bodies)),
+		JointCount: uint32(len(s.Joints)),
+	}
+	if err := binary.Write(buf, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+
+	for i := range s.Bodies {
+		state := &s.Bodies[i]
+		record := bodyRecord{
+			Matrix:      state.Matrix,
+			Velocity:    state.Velocity,
+			Omega:       state.Omega,
+			Force:       state.Force,
+			Torque:      state.Torque,
+			SleepState:  uint8(state.SleepState),
+			FreezeState: uint8(state.FreezeState),
+		}
+		if err := binary.Write(buf, binary.LittleEndian, &record); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range s.Joints {
+		record := jointRecord{
+			CollisionState: uint8(s.Joints[i].CollisionState),
+			Stiffness:      s.Joints[i].Stiffness,
+		}
+		if err := binary.Write(buf, binary.LittleEndian, &record); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+//UnmarshalBinary decodes a snapshot encoded with MarshalBinary
+func (s *Snapshot) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	var header snapshotHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return ErrSnapshotFormat
+	}
+	if header.Magic != snapshotMagic || header.Version != snapshotVersion {
+		return ErrSnapshotFormat
+	}
+	size := int64(header.BodyCount)*int64(binary.Size(bodyRecord{})) +
+		int64(header.JointCount)*int64(binary.Size(jointRecord{}))
+	if int64(r.Len()) != size {
+		return ErrSnapshotFormat
+	}
+
+	s.Bodies = make([]BodyState, header.BodyCount)
+	for i := range s.Bodies {
+		var record bodyRecord
+		if err := binary.Read(r, binary.LittleEndian, &record); err != nil {
+			return ErrSnapshotFormat
+		}
+		s.Bodies[i] = BodyState{
+			Matrix:      record.Matrix,
+			Velocity:    record.Velocity,
+			Omega:       record.Omega,
+			Force:       record.Force,
+			Torque:      record.Torque,
+			SleepState:  int(record.SleepState),
+			FreezeState: int(record.FreezeState),
+		}
+	}
+
+	s.Joints = make([]JointState, header.JointCount)
+	for i := range s.Joints {
+		var record jointRecord
+		if err := binary.Read(r, binary.LittleEndian, &record); err != nil {
+			return ErrSnapshotFormat
+		}
+		s.Joints[i] = JointState{
+			CollisionState: int(record.CollisionState),
+			Stiffness:      record.Stiffness,
+		}
+	}
+
+	return nil
+}
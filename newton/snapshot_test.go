@@ -0,0 +1,135 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"math"
+	"testing"
+)
+
+const testTimestep = float32(1.0 / 60)
+
+//createTestScene fills the world with a static floor and count boxes dropped onto it,
+// all pulled down by gravity
+func createTestScene(t *testing.T, w *World, count int) []*Body {
+	t.Helper()
+
+	floorShape, err := w.CreateBox(100, 1, 100, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer floorShape.Destroy()
+	floor := Identity()
+	if _, err := w.CreateDynamicBody(floorShape, (*[16]float32)(&floor)); err != nil {
+		t.Fatal(err)
+	}
+
+	boxShape, err := w.CreateBox(1, 1, 1, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boxShape.Destroy()
+
+	gravity := func(body *Body, timestep float32, threadIndex int) {
+		force := [3]float32{0, -10, 0}
+		body.SetForce(&force)
+	}
+
+	bodies := make([]*Body, count)
+	for i := range bodies {
+		m := TranslationMatrix(Vec3{float32(i) * 0.6, 3 + float32(i)*1.5, 0})
+		body, err := w.CreateDynamicBody(boxShape, (*[16]float32)(&m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body.SetMassMatrix(1, 1, 1, 1)
+		body.SetForceAndTorqueCallback(gravity)
+		bodies[i] = body
+	}
+	return bodies
+}
+
+type bodyState struct {
+	matrix   Mat4
+	velocity [3]float32
+	omega    [3]float32
+}
+
+func bodyStates(bodies []*Body) []bodyState {
+	states := make([]bodyState, len(bodies))
+	for i, body := range bodies {
+		states[i].matrix = body.Transform()
+		body.Velocity(&states[i].velocity)
+		body.Omega(&states[i].omega)
+	}
+	return states
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	const (
+		before = 90  //steps before the snapshot, so it's taken with the boxes landed and stacked
+		after  = 120 //steps after it, replayed from the snapshot
+	)
+
+	w, err := CreateWorld()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	bodies := createTestScene(t, w, 4)
+	for i := 0; i < before; i++ {
+		w.Update(testTimestep)
+	}
+
+	s := w.Snapshot()
+	for i := 0; i < after; i++ {
+		w.Update(testTimestep)
+	}
+	want := bodyStates(bodies)
+
+	if err := w.Restore(s); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < after; i++ {
+		w.Update(testTimestep)
+	}
+	got := bodyStates(bodies)
+
+	const tolerance = 1e-4
+	near := func(a, b []float32) bool {
+		for i := range a {
+			if math.Abs(float64(a[i]-b[i])) > tolerance {
+				return false
+			}
+		}
+		return true
+	}
+	for i := range bodies {
+		if !near(got[i].matrix[:], want[i].matrix[:]) {
+			t.Errorf("body %d transform is %v after restoring, want %v", i, got[i].matrix, want[i].matrix)
+		}
+		if !near(got[i].velocity[:], want[i].velocity[:]) {
+			t.Errorf("body %d velocity is %v after restoring, want %v", i, got[i].velocity, want[i].velocity)
+		}
+		if !near(got[i].omega[:], want[i].omega[:]) {
+			t.Errorf("body %d omega is %v after restoring, want %v", i, got[i].omega, want[i].omega)
+		}
+	}
+}
+
+func TestRestoreMismatch(t *testing.T) {
+	w, err := CreateWorld()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Destroy()
+
+	createTestScene(t, w, 2)
+	s := w.Snapshot()
+	createTestScene(t, w, 1)
+	if err := w.Restore(s); err != ErrSnapshotMismatch {
+		t.Fatalf("Restore returned %v, want ErrSnapshotMismatch", err)
+	}
+}
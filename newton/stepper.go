@@ -0,0 +1,206 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+/*
+#include "Newton.h"
+#include "callback.h"
+*/
+import "C"
+import (
+	"fmt"
+	"sync"
+)
+
+//Stepper advances a world in fixed timesteps from a variable frame time.
+// Time that doesn't add up to a whole step is carried over to the next frame,
+// and is available as Alpha for interpolating between the last two steps
+// when rendering
+type Stepper struct {
+	world       *World
+	timestep    float32
+	maxSteps    int
+	accumulator float32
+	updating    bool
+}
+
+//NewStepper returns a stepper that updates world timestep seconds at a time.
+// No more than maxSteps updates are run for a single frame, any time beyond that
+// is dropped so a slow frame can't leave the world further and further behind.
+// A maxSteps of 0 means there is no limit.  NewStepper panics if timestep isn't
+// greater than 0, since the stepper could never catch up with the frame time
+func NewStepper(world *World, timestep float32, maxSteps int) *Stepper {
+	if !(timestep > 0) {
+		panic(fmt.Sprintf("newton: stepper timestep must be greater than 0, got %v", timestep))
+	}
+	return &Stepper{
+		world:    world,
+		timestep: timestep,
+		maxSteps: maxSteps,
+	}
+}
+
+func (s *Stepper) Timestep() float32 { return s.timestep }
+
+func (s *Stepper) MaxSteps() int { return s.maxSteps }
+
+func (s *Stepper) SetMaxSteps(maxSteps int) { s.maxSteps = maxSteps }
+
+//Alpha is how far the leftover time is into the next step, from 0 up to 1
+func (s *Stepper) Alpha() float32 {
+	alpha := s.accumulator / s.timestep
+	if alpha < 0 {
+		return 0
+	}
+	return alpha
+}
+
+//steps adds the frame time and returns how many whole steps to run
+func (s *Stepper) steps(realDelta float32) int {
+	s.accumulator += realDelta
+	if s.maxSteps > 0 {
+		if max := float32(s.maxSteps) * s.timestep; s.accumulator > max {
+			s.accumulator = max
+		}
+	}
+
+	steps := int(s.accumulator / s.timestep)
+	s.accumulator -= float32(steps) * s.timestep
+	return steps
+}
+
+//Advance runs as many fixed steps as realDelta seconds, plus any time left over
+// from earlier frames, add up to.  Returns the number of steps run
+func (s *Stepper) Advance(realDelta float32) int {
+	s.Wait()
+
+	steps := s.steps(realDelta)
+	for i := 0; i < steps; i++ {
+		s.world.Update(s.timestep)
+		s.commit()
+	}
+	return steps
+}
+
+//AdvanceAsync is the same as Advance, except the last step is run with UpdateAsync
+// so the caller can render while it finishes.  The step isn't visible to
+// InterpolatedMatrix until the next call to Advance, AdvanceAsync or Wait, so
+// rendering trails the simulation by one step
+func (s *Stepper) AdvanceAsync(realDelta float32) int {
+	s.Wait()
+
+	steps := s.steps(realDelta)
+	for i := 0; i < steps-1; i++ {
+		s.world.Update(s.timestep)
+		s.commit()
+	}
+	if steps > 0 {
+		s.world.UpdateAsync(s.timestep)
+		s.updating = true
+	}
+	return steps
+}
+
+//Wait blocks until the step started by AdvanceAsync has finished
+func (s *Stepper) Wait() {
+	if !s.updating {
+		return
+	}
+	s.world.WaitForUpdateToFinish()
+	s.updating = false
+	s.commit()
+}
+
+//Interpolate records the body's transform at every step, so it can be drawn
+// in between steps with InterpolatedMatrix
+func (s *Stepper) Interpolate(body *Body) {
+	matrix := body.Transform()
+	rec := &interpolation{
+		stepper:  s,
+		previous: matrix,
+		current:  matrix,
+		next:     matrix,
+	}
+	interpolated.set(owner(body.ptr()), rec)
+
+	cb := s.world.callbacks()
+	cb.Lock()
+	if cb.interpolated == nil {
+		cb.interpolated = make(map[*Body]*interpolation)
+	}
+	cb.interpolated[body] = rec
+	cb.Unlock()
+	C.SetTransformCallback(body.ptr())
+}
+
+//commit makes the transforms written by the last step current
+func (s *Stepper) commit() {
+	cb := s.world.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+
+	for body, rec := range cb.interpolated {
+		if body.handle == nil {
+			delete(cb.interpolated, body)
+			continue
+		}
+		if rec.stepper != s {
+			continue
+		}
+		rec.Lock()
+		rec.previous = rec.current
+		if rec.moved {
+			rec.current = rec.next
+			rec.moved = false
+		}
+		rec.Unlock()
+	}
+}
+
+//interpolation holds a body's transform at the last two steps, along with
+// the one being written by the step in progress
+type interpolation struct {
+	sync.Mutex
+	stepper                 *Stepper
+	previous, current, next Mat4
+	moved                   bool
+}
+
+//interpolated is looked up by body from the transform callback, each world keeps its
+// own bodies as well so a stepper only commits the bodies in its world
+var interpolated = newHandlers[*interpolation]()
+
+//record is called from the body's transform callback
+func (rec *interpolation) record(matrix *[16]float32) {
+	rec.Lock()
+	rec.next = *matrix
+	rec.moved = true
+	rec.Unlock()
+}
+
+//resetInterpolation moves the body's interpolated transforms straight to matrix,
+// so a body that's been placed somewhere isn't drawn sliding there
+func resetInterpolation(body *C.NewtonBody, matrix Mat4) {
+	rec, ok := interpolated.get(owner(body))
+	if !ok {
+		return
+	}
+	rec.Lock()
+	rec.previous, rec.current, rec.next = matrix, matrix, matrix
+	rec.moved = false
+	rec.Unlock()
+}
+
+//InterpolatedMatrix returns the body's transform alpha of the way between its
+// last two steps, see Stepper.Alpha.  Bodies that aren't being interpolated
+// by a Stepper return their current transform
+func (b *Body) InterpolatedMatrix(alpha float32) Mat4 {
+	rec, ok := interpolated.get(owner(b.ptr()))
+	if !ok {
+		return b.Transform()
+	}
+	rec.Lock()
+	defer rec.Unlock()
+	return rec.previous.Interpolate(rec.current, alpha)
+}
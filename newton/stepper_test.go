@@ -0,0 +1,61 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestStepperSteps(t *testing.T) {
+	//a timestep that's exact in binary, so the leftover time is too
+	const timestep = 0.25
+
+	tests := []struct {
+		name     string
+		maxSteps int
+		frames   []float32
+		steps    []int
+		alpha    float32 //after the last frame
+	}{
+		{"shorter than a step", 0, []float32{0.125}, []int{0}, 0.5},
+		{"exactly a step", 0, []float32{0.25}, []int{1}, 0},
+		{"several steps", 0, []float32{0.875}, []int{3}, 0.5},
+		{"carried over", 0, []float32{0.125, 0.125, 0.375}, []int{0, 1, 1}, 0.5},
+		{"no limit", 0, []float32{2.5}, []int{10}, 0},
+		{"under the limit", 4, []float32{0.875}, []int{3}, 0.5},
+		{"clamped", 2, []float32{1.125}, []int{2}, 0},
+		{"clamped time is dropped", 2, []float32{1.125, 0.125}, []int{2, 0}, 0.5},
+		{"clamped with carry over", 2, []float32{0.125, 0.5}, []int{0, 2}, 0},
+		{"negative frame", 0, []float32{0.375, -0.25}, []int{1, 0}, 0},
+	}
+
+	for _, test := range tests {
+		s := NewStepper(nil, timestep, test.maxSteps)
+		steps := make([]int, len(test.frames))
+		for i, frame := range test.frames {
+			steps[i] = s.steps(frame)
+		}
+		if !reflect.DeepEqual(steps, test.steps) {
+			t.Errorf("%s: ran %v steps, want %v", test.name, steps, test.steps)
+		}
+		if alpha := s.Alpha(); alpha != test.alpha {
+			t.Errorf("%s: alpha is %v, want %v", test.name, alpha, test.alpha)
+		}
+	}
+}
+
+func TestStepperTimestep(t *testing.T) {
+	for _, timestep := range []float32{0, -1, float32(math.NaN())} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewStepper with a timestep of %v didn't panic", timestep)
+				}
+			}()
+			NewStepper(nil, timestep, 0)
+		}()
+	}
+}
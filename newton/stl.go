@@ -0,0 +1,122 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var ErrSTLFormat = errors.New("newton: invalid stl")
+
+//ParseSTL reads the triangles of a binary or ASCII STL file
+func ParseSTL(r io.Reader) ([][3]Vec3, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	//ASCII files start with solid, but so do some binary ones, so the binary size is
+	// checked first
+	header := 80 + 4
+	if len(data) >= header {
+		count := int(binary.LittleEndian.Uint32(data[80:]))
+		if len(data) == header+count*binary.Size(stlTriangle{}) {
+			return parseBinarySTL(data[header:], count)
+		}
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("solid")) {
+		return parseASCIISTL(data)
+	}
+	return nil, ErrSTLFormat
+}
+
+func parseBinarySTL(data []byte, count int) ([][3]Vec3, error) {
+	r := bytes.NewReader(data)
+	triangles := make([][3]Vec3, count)
+	for i := range triangles {
+		var record stlTriangle
+		if err := binary.Read(r, binary.LittleEndian, &record); err != nil {
+			return nil, ErrSTLFormat
+		}
+		for v := range record.Vertices {
+			triangles[i][v] = record.Vertices[v]
+		}
+	}
+	return triangles, nil
+}
+
+func parseASCIISTL(data []byte) ([][3]Vec3, error) {
+	var triangles [][3]Vec3
+	var facet []Vec3
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "facet":
+			facet = facet[:0]
+		case "vertex":
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("%w: line %d: expected 3 values", ErrSTLFormat, line)
+			}
+			var v Vec3
+			for i := range v {
+				f, err := strconv.ParseFloat(fields[i+1], 32)
+				if err != nil {
+					return nil, fmt.Errorf("%w: line %d: %v", ErrSTLFormat, line, err)
+				}
+				v[i] = float32(f)
+			}
+			facet = append(facet, v)
+		case "endfacet":
+			if len(facet) != 3 {
+				return nil, fmt.Errorf("%w: line %d: facet has %d vertices", ErrSTLFormat, line, len(facet))
+			}
+			triangles = append(triangles, [3]Vec3{facet[0], facet[1], facet[2]})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return triangles, nil
+}
+
+//LoadSTL reads an STL file into a new mesh, with a face for each triangle
+func (w *World) LoadSTL(r io.Reader) (*Mesh, error) {
+	triangles, err := ParseSTL(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(triangles) == 0 {
+		return nil, ErrNoVertices
+	}
+
+	mesh, err := w.CreateMesh()
+	if err != nil {
+		return nil, err
+	}
+	mesh.BeginFace()
+	face := make([]float32, 9)
+	for _, t := range triangles {
+		copy(face[0:3], t[0][:])
+		copy(face[3:6], t[1][:])
+		copy(face[6:9], t[2][:])
+		mesh.AddFace(3, face, 12, 0)
+	}
+	mesh.EndFace()
+	return mesh, nil
+}
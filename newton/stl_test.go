@@ -0,0 +1,97 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var stlTriangles = [][3]Vec3{
+	{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}},
+	{{0, 0, 1}, {1, 0, 1}, {0, 1, 1}},
+}
+
+func binarySTL(t *testing.T, header string, triangles [][3]Vec3) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := writeBinarySTL(buf, triangles); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	copy(data[:80], append([]byte(header), make([]byte, 80)...))
+	return data
+}
+
+func asciiSTL(t *testing.T, triangles [][3]Vec3) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := writeASCIISTL(buf, triangles); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseSTL(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want [][3]Vec3
+	}{
+		{"binary", binarySTL(t, "newton", stlTriangles), stlTriangles},
+		//a binary file whose header starts like an ASCII one is still read as binary,
+		// since its size matches the triangle count
+		{"binary starting with solid", binarySTL(t, "solid part", stlTriangles), stlTriangles},
+		{"binary with no triangles", binarySTL(t, "", nil), [][3]Vec3{}},
+		{"ascii", asciiSTL(t, stlTriangles), stlTriangles},
+		{"ascii with leading space", append([]byte("\n  "), asciiSTL(t, stlTriangles)...), stlTriangles},
+		{"ascii with no triangles", []byte("solid empty\nendsolid empty\n"), nil},
+		{"ascii without names", []byte(`solid
+facet normal 0 0 1
+outer loop
+vertex 0 0 0
+vertex 1 0 0
+vertex 0 1 0
+endloop
+endfacet
+endsolid
+`), stlTriangles[:1]},
+	}
+
+	for _, test := range tests {
+		got, err := ParseSTL(bytes.NewReader(test.data))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestParseSTLErrors(t *testing.T) {
+	binary := binarySTL(t, "newton", stlTriangles)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not stl", []byte("this isn't an stl file")},
+		{"truncated binary", binary[:len(binary)-1]},
+		{"binary with extra data", append(append([]byte(nil), binary...), 0)},
+		{"short vertex", []byte("solid\nfacet\nouter loop\nvertex 0 0\nendloop\nendfacet\nendsolid\n")},
+		{"bad vertex", []byte("solid\nfacet\nouter loop\nvertex 0 x 0\nendloop\nendfacet\nendsolid\n")},
+		{"two vertices", []byte("solid\nfacet\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nendloop\nendfacet\nendsolid\n")},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseSTL(bytes.NewReader(test.data)); !errors.Is(err, ErrSTLFormat) {
+			t.Errorf("%s: got error %v, want ErrSTLFormat", test.name, err)
+		}
+	}
+}
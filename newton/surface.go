@@ -0,0 +1,85 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+//Surface velocity
+// A body with a surface velocity drags whatever touches it along as if its surface
+// were moving, without the body itself moving.  It's applied in the contacts
+// callback by turning each contact's first tangent direction to face the way the
+// surfaces should slide, and accelerating the contact along it until the bodies
+// slide past each other at the surface velocity.
+
+//surfaceVelocity is a body's surface velocity, in world or local space
+type surfaceVelocity struct {
+	velocity Vec3
+	local    bool
+}
+
+var surfaceVelocities = newHandlers[surfaceVelocity]()
+
+//SetSurfaceVelocity makes the body's surface move at velocity, in world space, so the
+// bodies touching it are carried along, such as on a conveyor belt.  The velocity is
+// only applied along the contact surfaces, so it can't push bodies into or away from
+// the body.  A zero velocity removes it
+func (b *Body) SetSurfaceVelocity(velocity Vec3) {
+	b.setSurfaceVelocity(surfaceVelocity{velocity: velocity})
+}
+
+//SetLocalSurfaceVelocity is SetSurfaceVelocity with the velocity in the body's space,
+// so it turns with the body, such as for a tank tread
+func (b *Body) SetLocalSurfaceVelocity(velocity Vec3) {
+	b.setSurfaceVelocity(surfaceVelocity{velocity: velocity, local: true})
+}
+
+func (b *Body) setSurfaceVelocity(v surfaceVelocity) {
+	if v.velocity == (Vec3{}) {
+		surfaceVelocities.remove(owner(b.ptr()))
+		return
+	}
+	b.World().installPairCallbacks()
+	surfaceVelocities.set(owner(b.ptr()), v)
+}
+
+//SurfaceVelocity returns the body's surface velocity in world space
+func (b *Body) SurfaceVelocity() Vec3 {
+	v, ok := surfaceVelocities.get(owner(b.handle))
+	if !ok {
+		return Vec3{}
+	}
+	if v.local {
+		return b.Transform().RotateVector(v.velocity)
+	}
+	return v.velocity
+}
+
+//applySurfaceVelocity sets the tangent acceleration of each of the contacts between
+// the joint's bodies, if either has a surface velocity
+func applySurfaceVelocity(contact *Joint, timestep float32) {
+	body0, body1 := contact.Body0(), contact.Body1()
+	_, ok0 := surfaceVelocities.get(owner(body0.handle))
+	_, ok1 := surfaceVelocities.get(owner(body1.handle))
+	if !ok0 && !ok1 {
+		return
+	}
+
+	//the velocity body0 needs relative to body1 for the surfaces to slide at their
+	// surface velocities
+	relative := body1.SurfaceVelocity().Sub(body0.SurfaceVelocity())
+	for c := range contact.Contacts() {
+		material := c.Material()
+
+		var position, normal [3]float32
+		material.ContactPositionAndNormal(body0, &position, &normal)
+		n := Vec3(normal)
+		tangent := relative.Sub(n.Scale(relative.Dot(n)))
+		speed := tangent.Len()
+		if speed < 1e-4 {
+			continue
+		}
+
+		direction := [3]float32(tangent.Scale(1 / speed))
+		material.ContactRotateTangentDirections(&direction)
+		material.SetContactTangentAcceleration((speed-material.ContactTangentSpeed(0))/timestep, 0)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+)
+
+//TestSurfaceVelocity rests a box on a static floor and gives one of them a surface
+// velocity.  A moving floor is a conveyor that carries the box along, and a moving box
+// is a tread that drives itself the other way.  Newton puts the static body on a
+// different side of the contact joint to the dynamic one, so between them the surface
+// velocity is applied as both body0 and body1
+func TestSurfaceVelocity(t *testing.T) {
+	const steps = 120
+
+	tests := []struct {
+		name     string
+		conveyor bool //the floor has the surface velocity, rather than the box
+		want     float32
+	}{
+		{"conveyor", true, 1},
+		{"tread", false, -1},
+	}
+
+	var asBody0, asBody1 atomic.Bool
+	for _, test := range tests {
+		w, err := CreateWorld()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer w.Destroy()
+
+		floorShape, err := w.CreateBox(10, 1, 10, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer floorShape.Destroy()
+		boxShape, err := w.CreateBox(1, 1, 1, 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer boxShape.Destroy()
+
+		m := Identity()
+		floor, err := w.CreateDynamicBody(floorShape, (*[16]float32)(&m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m = TranslationMatrix(Vec3{0, 1, 0})
+		box, err := w.CreateDynamicBody(boxShape, (*[16]float32)(&m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		box.SetMassMatrix(1, 1, 1, 1)
+		box.SetForceAndTorqueCallback(func(body *Body, timestep float32, threadIndex int) {
+			force := [3]float32{0, -10, 0}
+			body.SetForce(&force)
+		})
+
+		moving := box
+		if test.conveyor {
+			moving = floor
+		}
+		moving.SetSurfaceVelocity(Vec3{1, 0, 0})
+
+		id := w.DefaultMaterialGroupID()
+		w.SetMaterialCollisionCallback(id, id, nil, nil, func(contact *Joint, timestep float32, threadIndex int) {
+			if contact.Body0() == moving {
+				asBody0.Store(true)
+			} else if contact.Body1() == moving {
+				asBody1.Store(true)
+			}
+		})
+
+		for i := 0; i < steps; i++ {
+			w.Update(testTimestep)
+		}
+
+		if v := box.LinearVelocity(); math.Abs(float64(v[0]-test.want)) > 0.1 ||
+			math.Abs(float64(v[2])) > 0.1 {
+			t.Errorf("%s: box velocity is %v, want %v along X", test.name, v, test.want)
+		}
+	}
+
+	if !asBody0.Load() || !asBody1.Load() {
+		t.Errorf("surface velocity applied as body0 %v, as body1 %v, want both", asBody0.Load(), asBody1.Load())
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+//Trigger events
+// A body whose collision is a trigger volume still gets contact joints with
+// the bodies overlapping it, it just doesn't push them away.  After each
+// update the contact joints of every body with trigger handlers are compared
+// with the bodies that were inside it after the last update, so enter, stay
+// and exit events cost nothing for worlds that don't use them.
+
+//TriggerHandler is called with the body that entered, stayed in or left a trigger
+type TriggerHandler func(other *Body)
+
+//triggerHandlers holds the handlers for a trigger body, and the bodies inside it as
+// of the last update
+type triggerHandlers struct {
+	enter, stay, exit TriggerHandler
+	inside            map[*Body]bool
+}
+
+//setTrigger updates the handlers for the trigger body
+func (w *World) setTrigger(body *Body, set func(t *triggerHandlers)) {
+	cb := w.callbacks()
+	cb.Lock()
+	defer cb.Unlock()
+	if cb.triggers == nil {
+		cb.triggers = make(map[*Body]*triggerHandlers)
+	}
+	t, ok := cb.triggers[body]
+	if !ok {
+		t = &triggerHandlers{inside: make(map[*Body]bool)}
+		cb.triggers[body] = t
+	}
+	set(t)
+}
+
+//OnTriggerEnter calls handler after each update with every body that started
+// overlapping the trigger during it.  The trigger body's collision should be a trigger
+// volume (see Collision.SetIsTriggerVolume), otherwise bodies bounce off it
+// as normal and enter as they touch it
+func (w *World) OnTriggerEnter(trigger *Body, handler TriggerHandler) {
+	w.setTrigger(trigger, func(t *triggerHandlers) { t.enter = handler })
+}
+
+//OnTriggerStay calls handler after each update with every body that is still
+// overlapping the trigger, not including those that just entered
+func (w *World) OnTriggerStay(trigger *Body, handler TriggerHandler) {
+	w.setTrigger(trigger, func(t *triggerHandlers) { t.stay = handler })
+}
+
+//OnTriggerExit calls handler after each update with every body that stopped
+// overlapping the trigger.  Bodies that are destroyed while inside don't exit
+func (w *World) OnTriggerExit(trigger *Body, handler TriggerHandler) {
+	w.setTrigger(trigger, func(t *triggerHandlers) { t.exit = handler })
+}
+
+//RemoveTrigger removes all of the trigger body's handlers
+func (w *World) RemoveTrigger(trigger *Body) {
+	cb := w.callbacks()
+	cb.Lock()
+	delete(cb.triggers, trigger)
+	cb.Unlock()
+}
+
+//TriggerOverlaps returns the bodies inside the trigger as of the last update
+func (w *World) TriggerOverlaps(trigger *Body) []*Body {
+	cb := w.callbacks()
+	cb.RLock()
+	defer cb.RUnlock()
+	t, ok := cb.triggers[trigger]
+	if !ok {
+		return nil
+	}
+	overlaps := make([]*Body, 0, len(t.inside))
+	for body := range t.inside {
+		overlaps = append(overlaps, body)
+	}
+	return overlaps
+}
+
+//triggerEvent is a single call to a trigger handler
+type triggerEvent struct {
+	handler TriggerHandler
+	other   *Body
+}
+
+//updateTriggers compares the bodies overlapping each trigger with the last update
+// and calls the handlers.  The handlers are called once the triggers are unlocked,
+// so they can add or remove triggers
+func (w *World) updateTriggers() {
+	cb := w.callbacks()
+	var events []triggerEvent
+
+	cb.Lock()
+	for body, t := range cb.triggers {
+		if body.handle == nil {
+			delete(cb.triggers, body)
+			continue
+		}
+
+		current := make(map[*Body]bool, len(t.inside))
+		for joint := range body.ContactJoints() {
+			if joint.ContactCount() == 0 {
+				continue
+			}
+			other := joint.Body0()
+			if other == body {
+				other = joint.Body1()
+			}
+			current[other] = true
+		}
+
+		for other := range current {
+			handler := t.stay
+			if !t.inside[other] {
+				handler = t.enter
+			}
+			if handler != nil {
+				events = append(events, triggerEvent{handler, other})
+			}
+		}
+		for other := range t.inside {
+			if !current[other] && other.handle != nil && t.exit != nil {
+				events = append(events, triggerEvent{t.exit, other})
+			}
+		}
+		t.inside = current
+	}
+	cb.Unlock()
+
+	for _, e := range events {
+		e.handler(e.other)
+	}
+}
@@ -0,0 +1,473 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package urdf
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"strings"
+
+	"bitbucket.org/tshannon/gonewton/newton"
+)
+
+//Options for building a robot, the zero value places the robot at the origin and can't
+// load mesh geometry
+type Options struct {
+	//Base places the root link, nil is the origin
+	Base *newton.Mat4
+	//FixedBase makes the root link static, so the robot is fixed in place.  A root link
+	// named world is always static
+	FixedBase bool
+	//OpenMesh opens the mesh files collisions refer to, such as
+	// package://robot/meshes/arm.stl.  OBJ and STL files are supported
+	OpenMesh func(filename string) (io.ReadCloser, error)
+	//HullTolerance is the tolerance of the convex hulls built from meshes
+	HullTolerance float32
+}
+
+//MeshFS returns an OpenMesh function that opens meshes from fsys, with any package://
+// or file:// prefix removed from their names
+func MeshFS(fsys fs.FS) func(filename string) (io.ReadCloser, error) {
+	return func(filename string) (io.ReadCloser, error) {
+		name := strings.TrimPrefix(strings.TrimPrefix(filename, "package://"), "file://")
+		f, err := fsys.Open(strings.TrimPrefix(name, "/"))
+		if err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+}
+
+//Robot is the bodies and joints built from a URDF description
+type Robot struct {
+	Name   string
+	Bodies map[string]*newton.Body  //by link name, links joined by fixed joints share a body
+	Joints map[string]*newton.Joint //by joint name, fixed and floating joints have none
+}
+
+//Destroy destroys the robot's joints and bodies
+func (r *Robot) Destroy(w *newton.World) {
+	for name, joint := range r.Joints {
+		w.DestroyJoint(joint)
+		delete(r.Joints, name)
+	}
+	destroyed := make(map[*newton.Body]bool)
+	for name, body := range r.Bodies {
+		if !destroyed[body] {
+			w.DestroyBody(body)
+			destroyed[body] = true
+		}
+		delete(r.Bodies, name)
+	}
+}
+
+//Load reads a URDF file and builds its robot in w, see Parse and Description.Build
+func Load(w *newton.World, r io.Reader, opts *Options) (*Robot, error) {
+	d, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	return d.Build(w, opts)
+}
+
+//builder is the state of a single Build
+type builder struct {
+	d        *Description
+	w        *newton.World
+	opts     Options
+	robot    *Robot
+	order    []string               //links, parents before their children
+	matrices map[string]newton.Mat4 //world transform of each link
+	bodyLink map[string]string      //the first link of the body each link is part of
+}
+
+//Build creates the robot's bodies and joints in w, in the pose where every joint is at
+// zero.
+//
+// Each link becomes a body, except for links joined by a fixed joint which are merged
+// into a single body.  The collisions of a body are combined into a compound, with
+// cylinders turned to run along Z and meshes built into convex hulls.  Masses, centres
+// of mass and inertias are combined as well, but Newton only supports principal
+// inertias, so the products of inertia are dropped.  A body without mass is static.
+//
+// Revolute and continuous joints become hinges and prismatic joints become sliders,
+// with joint callbacks holding them within their limits.  Floating joints leave the
+// child body free, and planar joints aren't supported.  Bodies aren't given a force
+// and torque callback, so set one to apply gravity
+func (d *Description) Build(w *newton.World, opts *Options) (robot *Robot, err error) {
+	root, err := d.root()
+	if err != nil {
+		return nil, err
+	}
+
+	b := &builder{
+		d:        d,
+		w:        w,
+		robot:    &Robot{Name: d.Name, Bodies: make(map[string]*newton.Body), Joints: make(map[string]*newton.Joint)},
+		matrices: make(map[string]newton.Mat4),
+		bodyLink: make(map[string]string),
+	}
+	if opts != nil {
+		b.opts = *opts
+	}
+	defer func() {
+		if err != nil {
+			b.robot.Destroy(w)
+		}
+	}()
+
+	base := newton.Identity()
+	if b.opts.Base != nil {
+		base = *b.opts.Base
+	}
+	b.place(root.Name, base, root.Name)
+
+	for _, link := range b.order {
+		if b.bodyLink[link] == link {
+			static := link == root.Name && (b.opts.FixedBase || link == "world")
+			if err := b.body(link, static); err != nil {
+				return nil, fmt.Errorf("link %q: %w", link, err)
+			}
+		}
+	}
+
+	for i := range d.Joints {
+		if err := b.joint(&d.Joints[i]); err != nil {
+			return nil, fmt.Errorf("joint %q: %w", d.Joints[i].Name, err)
+		}
+	}
+	return b.robot, nil
+}
+
+//matrix returns the transform of the origin
+func (o *Origin) matrix() newton.Mat4 {
+	m := newton.RotationMatrix(newton.Vec3{1, 0, 0}, o.RPY[0]).
+		Mul(newton.RotationMatrix(newton.Vec3{0, 1, 0}, o.RPY[1])).
+		Mul(newton.RotationMatrix(newton.Vec3{0, 0, 1}, o.RPY[2]))
+	m.SetPosition(newton.Vec3(o.XYZ))
+	return m
+}
+
+//place sets the world transform of the link and the links below it
+func (b *builder) place(link string, matrix newton.Mat4, bodyLink string) {
+	b.order = append(b.order, link)
+	b.matrices[link] = matrix
+	b.bodyLink[link] = bodyLink
+
+	for i := range b.d.Joints {
+		joint := &b.d.Joints[i]
+		if joint.Parent.Link != link {
+			continue
+		}
+		child := joint.Child.Link
+		if joint.Type == Fixed {
+			b.place(child, joint.Origin.matrix().Mul(matrix), bodyLink)
+		} else {
+			b.place(child, joint.Origin.matrix().Mul(matrix), child)
+		}
+	}
+}
+
+//tensor is a 3x3 inertia tensor
+type tensor [3][3]float32
+
+//rotation returns the rotation part of m
+func rotation(m newton.Mat4) tensor {
+	var r tensor
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			r[row][col] = m[row*4+col]
+		}
+	}
+	return r
+}
+
+//rotate returns t, given in the axes of r, in the axes r is relative to
+func (t tensor) rotate(r tensor) tensor {
+	var result tensor
+	for a := 0; a < 3; a++ {
+		for b := 0; b < 3; b++ {
+			for i := 0; i < 3; i++ {
+				for j := 0; j < 3; j++ {
+					result[a][b] += r[i][a] * t[i][j] * r[j][b]
+				}
+			}
+		}
+	}
+	return result
+}
+
+//inertialMass is a link's mass and inertia in its body's space
+type inertialMass struct {
+	mass    float32
+	centre  newton.Vec3
+	inertia tensor
+}
+
+//body builds the body that starts at link, from it and the links fixed to it
+func (b *builder) body(link string, static bool) error {
+	matrix := b.matrices[link]
+	inverse := matrix.Inverse()
+
+	var subs []*newton.Collision
+	var offsets [][16]float32
+	defer func() {
+		for _, sub := range subs {
+			sub.Destroy()
+		}
+	}()
+
+	var masses []inertialMass
+	var links []string
+	for _, name := range b.order {
+		if b.bodyLink[name] != link {
+			continue
+		}
+		links = append(links, name)
+		l := b.d.link(name)
+		relative := b.matrices[name].Mul(inverse)
+
+		if in := l.Inertial; in != nil && in.Mass.Value > 0 {
+			frame := in.Origin.matrix().Mul(relative)
+			i := in.Inertia
+			masses = append(masses, inertialMass{
+				mass:   in.Mass.Value,
+				centre: frame.Position(),
+				inertia: tensor{
+					{i.IXX, i.IXY, i.IXZ},
+					{i.IXY, i.IYY, i.IYZ},
+					{i.IXZ, i.IYZ, i.IZZ},
+				}.rotate(rotation(frame)),
+			})
+		}
+
+		for c := range l.Collisions {
+			sub, offset, err := b.collision(&l.Collisions[c], relative)
+			if err != nil {
+				return err
+			}
+			subs = append(subs, sub)
+			offsets = append(offsets, offset)
+		}
+	}
+
+	var collision *newton.Collision
+	var err error
+	switch len(subs) {
+	case 0:
+		collision, err = b.w.CreateNull()
+	case 1:
+		collision = subs[0]
+		subs = nil
+	default:
+		collision, err = b.w.CreateCompoundCollision(0)
+		if err == nil {
+			collision.CompoundBeginAddRemove()
+			for i, sub := range subs {
+				collision.SetSubCollisionMatrix(collision.CompoundAddSubCollision(sub), &offsets[i])
+			}
+			collision.CompoundEndAddRemove()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	//the body holds its own reference to the collision
+	defer collision.Destroy()
+
+	m := [16]float32(matrix)
+	body, err := b.w.CreateDynamicBody(collision, &m)
+	if err != nil {
+		return err
+	}
+	for _, name := range links {
+		b.robot.Bodies[name] = body
+	}
+
+	if static || len(masses) == 0 {
+		return nil
+	}
+
+	total, centre, inertia := combine(masses)
+	body.SetMassMatrix(total, inertia[0][0], inertia[1][1], inertia[2][2])
+	body.SetCentreOfMass((*[3]float32)(&centre))
+	return nil
+}
+
+//combine returns the total mass, centre of mass and inertia about that centre of
+// the masses
+func combine(masses []inertialMass) (float32, newton.Vec3, tensor) {
+	var total float32
+	var centre newton.Vec3
+	for _, m := range masses {
+		total += m.mass
+		centre = centre.Add(m.centre.Scale(m.mass))
+	}
+	centre = centre.Scale(1 / total)
+
+	//each mass's inertia moved to the shared centre with the parallel axis theorem
+	var inertia tensor
+	for _, m := range masses {
+		d := m.centre.Sub(centre)
+		for row := 0; row < 3; row++ {
+			for col := 0; col < 3; col++ {
+				inertia[row][col] += m.inertia[row][col] - m.mass*d[row]*d[col]
+			}
+			inertia[row][row] += m.mass * d.Dot(d)
+		}
+	}
+	return total, centre, inertia
+}
+
+//xToZ turns Newton's cylinders, which run along X, to run along Z
+var xToZ = newton.RotationMatrix(newton.Vec3{0, 1, 0}, -math.Pi/2)
+
+//collision creates a collision of a link, placed by relative, the link's transform in its
+// body's space.  It returns the collision's offset in the body as well
+func (b *builder) collision(c *Collision, relative newton.Mat4) (*newton.Collision, [16]float32, error) {
+	offset := c.Origin.matrix().Mul(relative)
+	m := [16]float32(offset)
+	g := &c.Geometry
+
+	var collision *newton.Collision
+	var err error
+	switch {
+	case g.Box != nil:
+		collision, err = b.w.CreateBox(g.Box.Size[0], g.Box.Size[1], g.Box.Size[2], 0, &m)
+	case g.Cylinder != nil:
+		m = [16]float32(xToZ.Mul(offset))
+		collision, err = b.w.CreateCylinder(g.Cylinder.Radius, g.Cylinder.Length, 0, &m)
+	case g.Sphere != nil:
+		collision, err = b.w.CreateSphere(g.Sphere.Radius, 0, &m)
+	case g.Mesh != nil:
+		collision, err = b.mesh(g.Mesh.Filename, g.Mesh.Scale, offset)
+		m = [16]float32(newton.Identity())
+	default:
+		err = fmt.Errorf("%w: collision %q has no geometry", ErrFormat, c.Name)
+	}
+	return collision, m, err
+}
+
+//mesh loads a mesh file and builds a convex hull from it, with its scale and offset
+// applied to its vertices
+func (b *builder) mesh(filename string, scale *Vector, offset newton.Mat4) (*newton.Collision, error) {
+	if b.opts.OpenMesh == nil {
+		return nil, fmt.Errorf("%w: mesh %s without Options.OpenMesh", ErrUnsupported, filename)
+	}
+
+	var load func(r io.Reader) (*newton.Mesh, error)
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".obj":
+		load = b.w.LoadOBJ
+	case ".stl":
+		load = b.w.LoadSTL
+	default:
+		return nil, fmt.Errorf("%w: mesh format of %s", ErrUnsupported, filename)
+	}
+
+	f, err := b.opts.OpenMesh(filename)
+	if err != nil {
+		return nil, err
+	}
+	mesh, err := load(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("mesh %s: %w", filename, err)
+	}
+	defer mesh.Destroy()
+
+	transform := newton.Identity()
+	if scale != nil {
+		for row := 0; row < 3; row++ {
+			transform[row*5] = scale[row]
+		}
+	}
+	m := [16]float32(transform.Mul(offset))
+	mesh.ApplyTransform(&m)
+
+	return b.w.CreateConvexHullFromMesh(mesh, b.opts.HullTolerance, 0)
+}
+
+//joint connects the bodies of the joint's links
+func (b *builder) joint(j *Joint) error {
+	switch j.Type {
+	case Fixed, Floating:
+		return nil
+	case Revolute, Continuous, Prismatic:
+	default:
+		return fmt.Errorf("%w: %s joint", ErrUnsupported, j.Type)
+	}
+
+	child, parent := b.robot.Bodies[j.Child.Link], b.robot.Bodies[j.Parent.Link]
+	matrix := b.matrices[j.Child.Link]
+	pivot := [3]float32(matrix.Position())
+	axis := matrix.RotateVector(newton.Vec3(j.axis()))
+	if axis.Len() == 0 {
+		return fmt.Errorf("%w: axis has no length", ErrFormat)
+	}
+	pin := [3]float32(axis.Normalize())
+
+	var joint *newton.Joint
+	var err error
+	switch j.Type {
+	case Revolute, Continuous:
+		joint, err = b.w.CreateHinge(&pivot, &pin, child, parent)
+		if err == nil && j.Type == Revolute && j.Limit != nil {
+			newton.SetHingeCallback(joint, hingeLimits(j.Limit.Lower, j.Limit.Upper))
+		}
+	case Prismatic:
+		joint, err = b.w.CreateSlider(&pivot, &pin, child, parent)
+		if err == nil && j.Limit != nil {
+			newton.SetSliderCallback(joint, sliderLimits(j.Limit.Lower, j.Limit.Upper))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	//neighbouring links usually overlap at the joint
+	joint.SetCollisionState(0)
+	b.robot.Joints[j.Name] = joint
+	return nil
+}
+
+//hingeLimits keeps a hinge between lower and upper radians, stopping it at a limit
+// while still letting it move away
+func hingeLimits(lower, upper float32) newton.HingeCallback {
+	return func(joint *newton.Joint, desc *newton.HingeSliderUpdateDesc) uint {
+		angle := joint.HingeJointAngle()
+		switch {
+		case angle < lower:
+			desc.SetAcceleration(joint.HingeCalculateStopAlpha(desc, lower))
+			desc.SetMinFriction(0)
+		case angle > upper:
+			desc.SetAcceleration(joint.HingeCalculateStopAlpha(desc, upper))
+			desc.SetMaxFriction(0)
+		default:
+			return 0
+		}
+		return 1
+	}
+}
+
+//sliderLimits keeps a slider between lower and upper
+func sliderLimits(lower, upper float32) newton.SliderCallback {
+	return func(joint *newton.Joint, desc *newton.HingeSliderUpdateDesc) uint {
+		position := joint.SliderJointPosit()
+		switch {
+		case position < lower:
+			desc.SetAcceleration(joint.SliderCalculateStopAccel(desc, lower))
+			desc.SetMinFriction(0)
+		case position > upper:
+			desc.SetAcceleration(joint.SliderCalculateStopAccel(desc, upper))
+			desc.SetMaxFriction(0)
+		default:
+			return 0
+		}
+		return 1
+	}
+}
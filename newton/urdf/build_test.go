@@ -0,0 +1,139 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package urdf
+
+import (
+	"math"
+	"testing"
+
+	"bitbucket.org/tshannon/gonewton/newton"
+)
+
+func near(a, b []float32) bool {
+	for i := range a {
+		if math.Abs(float64(a[i]-b[i])) > 1e-5 {
+			return false
+		}
+	}
+	return true
+}
+
+func nearTensor(a, b tensor) bool {
+	return near(a[0][:], b[0][:]) && near(a[1][:], b[1][:]) && near(a[2][:], b[2][:])
+}
+
+func TestOriginMatrix(t *testing.T) {
+	const quarter = math.Pi / 2
+
+	//the rows are where the x, y and z axes end up, URDF rolls about X first, then
+	// pitches about Y and yaws about Z, all about the parent's fixed axes
+	tests := []struct {
+		name   string
+		origin Origin
+		want   newton.Mat4
+	}{
+		{"identity", Origin{}, newton.Identity()},
+		{"translation", Origin{XYZ: Vector{1, 2, 3}}, newton.Mat4{
+			1, 0, 0, 0,
+			0, 1, 0, 0,
+			0, 0, 1, 0,
+			1, 2, 3, 1,
+		}},
+		{"yaw", Origin{RPY: Vector{0, 0, quarter}}, newton.Mat4{
+			0, 1, 0, 0,
+			-1, 0, 0, 0,
+			0, 0, 1, 0,
+			0, 0, 0, 1,
+		}},
+		{"roll then yaw", Origin{RPY: Vector{quarter, 0, quarter}}, newton.Mat4{
+			0, 1, 0, 0,
+			0, 0, 1, 0,
+			1, 0, 0, 0,
+			0, 0, 0, 1,
+		}},
+		{"roll then pitch", Origin{XYZ: Vector{4, 5, 6}, RPY: Vector{quarter, quarter, 0}}, newton.Mat4{
+			0, 0, -1, 0,
+			1, 0, 0, 0,
+			0, -1, 0, 0,
+			4, 5, 6, 1,
+		}},
+	}
+
+	for _, test := range tests {
+		if got := test.origin.matrix(); !near(got[:], test.want[:]) {
+			t.Errorf("%s: matrix is %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestTensorRotate(t *testing.T) {
+	tests := []struct {
+		name     string
+		inertia  tensor
+		rotation newton.Mat4
+		want     tensor
+	}{
+		{"identity", tensor{{1, 0, 0}, {0, 2, 0}, {0, 0, 3}}, newton.Identity(),
+			tensor{{1, 0, 0}, {0, 2, 0}, {0, 0, 3}}},
+		//the body's y axis lies along the parent's x axis
+		{"quarter turn", tensor{{1, 0, 0}, {0, 2, 0}, {0, 0, 3}},
+			newton.RotationMatrix(newton.Vec3{0, 0, 1}, math.Pi/2),
+			tensor{{2, 0, 0}, {0, 1, 0}, {0, 0, 3}}},
+		{"eighth turn", tensor{{1, 0, 0}, {0, 3, 0}, {0, 0, 5}},
+			newton.RotationMatrix(newton.Vec3{0, 0, 1}, math.Pi/4),
+			tensor{{2, -1, 0}, {-1, 2, 0}, {0, 0, 5}}},
+		{"products of inertia", tensor{{2, -1, 0}, {-1, 2, 0}, {0, 0, 5}},
+			newton.RotationMatrix(newton.Vec3{0, 0, 1}, -math.Pi/4),
+			tensor{{1, 0, 0}, {0, 3, 0}, {0, 0, 5}}},
+	}
+
+	for _, test := range tests {
+		if got := test.inertia.rotate(rotation(test.rotation)); !nearTensor(got, test.want) {
+			t.Errorf("%s: rotated to %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCombine(t *testing.T) {
+	box := tensor{{1, 0, 0}, {0, 2, 0}, {0, 0, 3}}
+
+	tests := []struct {
+		name    string
+		masses  []inertialMass
+		total   float32
+		centre  newton.Vec3
+		inertia tensor
+	}{
+		{"single", []inertialMass{{2, newton.Vec3{1, 2, 3}, box}}, 2, newton.Vec3{1, 2, 3}, box},
+		//point masses either side of the centre, each m*r^2 about the other axes
+		{"balanced points", []inertialMass{
+			{1, newton.Vec3{1, 0, 0}, tensor{}},
+			{1, newton.Vec3{-1, 0, 0}, tensor{}},
+		}, 2, newton.Vec3{}, tensor{{0, 0, 0}, {0, 2, 0}, {0, 0, 2}}},
+		//two point masses d apart have the inertia of their reduced mass at d,
+		// 1*3/(1+3) * 4^2 = 12
+		{"unbalanced points", []inertialMass{
+			{1, newton.Vec3{0, 0, 0}, tensor{}},
+			{3, newton.Vec3{4, 0, 0}, tensor{}},
+		}, 4, newton.Vec3{3, 0, 0}, tensor{{0, 0, 0}, {0, 12, 0}, {0, 0, 12}}},
+		{"diagonal points", []inertialMass{
+			{1, newton.Vec3{1, 1, 0}, tensor{}},
+			{1, newton.Vec3{-1, -1, 0}, tensor{}},
+		}, 2, newton.Vec3{}, tensor{{2, -2, 0}, {-2, 2, 0}, {0, 0, 4}}},
+		{"points with inertia", []inertialMass{
+			{1, newton.Vec3{0, 1, 0}, box},
+			{1, newton.Vec3{0, -1, 0}, box},
+		}, 2, newton.Vec3{}, tensor{{4, 0, 0}, {0, 4, 0}, {0, 0, 8}}},
+	}
+
+	for _, test := range tests {
+		total, centre, inertia := combine(test.masses)
+		if total != test.total || !near(centre[:], test.centre[:]) {
+			t.Errorf("%s: mass %v at %v, want %v at %v", test.name, total, centre, test.total, test.centre)
+		}
+		if !nearTensor(inertia, test.inertia) {
+			t.Errorf("%s: inertia %v, want %v", test.name, inertia, test.inertia)
+		}
+	}
+}
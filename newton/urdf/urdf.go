@@ -0,0 +1,225 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//Package urdf builds newton bodies and joints from URDF robot descriptions
+package urdf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrFormat      = errors.New("urdf: invalid urdf")
+	ErrUnsupported = errors.New("urdf: unsupported")
+)
+
+//Description is a parsed URDF file, only the parts used for simulation are kept
+type Description struct {
+	Name   string  `xml:"name,attr"`
+	Links  []Link  `xml:"link"`
+	Joints []Joint `xml:"joint"`
+}
+
+type Link struct {
+	Name       string      `xml:"name,attr"`
+	Inertial   *Inertial   `xml:"inertial"`
+	Collisions []Collision `xml:"collision"`
+}
+
+//Inertial is the mass of a link, with its centre of mass and inertia axes at Origin
+type Inertial struct {
+	Origin  Origin  `xml:"origin"`
+	Mass    Value   `xml:"mass"`
+	Inertia Inertia `xml:"inertia"`
+}
+
+type Value struct {
+	Value float32 `xml:"value,attr"`
+}
+
+type Inertia struct {
+	IXX float32 `xml:"ixx,attr"`
+	IXY float32 `xml:"ixy,attr"`
+	IXZ float32 `xml:"ixz,attr"`
+	IYY float32 `xml:"iyy,attr"`
+	IYZ float32 `xml:"iyz,attr"`
+	IZZ float32 `xml:"izz,attr"`
+}
+
+//Origin is a position and roll, pitch and yaw in radians around the fixed X, Y and
+// Z axes
+type Origin struct {
+	XYZ Vector `xml:"xyz,attr"`
+	RPY Vector `xml:"rpy,attr"`
+}
+
+type Collision struct {
+	Name     string   `xml:"name,attr"`
+	Origin   Origin   `xml:"origin"`
+	Geometry Geometry `xml:"geometry"`
+}
+
+//Geometry has one of its shapes set.  Cylinders run along Z
+type Geometry struct {
+	Box *struct {
+		Size Vector `xml:"size,attr"`
+	} `xml:"box"`
+	Cylinder *struct {
+		Radius float32 `xml:"radius,attr"`
+		Length float32 `xml:"length,attr"`
+	} `xml:"cylinder"`
+	Sphere *struct {
+		Radius float32 `xml:"radius,attr"`
+	} `xml:"sphere"`
+	Mesh *struct {
+		Filename string  `xml:"filename,attr"`
+		Scale    *Vector `xml:"scale,attr"`
+	} `xml:"mesh"`
+}
+
+//joint types
+const (
+	Revolute   = "revolute"
+	Continuous = "continuous"
+	Prismatic  = "prismatic"
+	Fixed      = "fixed"
+	Floating   = "floating"
+	Planar     = "planar"
+)
+
+//Joint places the child link at Origin in the parent link's frame.  Axis is in the
+// child's frame, and defaults to X
+type Joint struct {
+	Name   string `xml:"name,attr"`
+	Type   string `xml:"type,attr"`
+	Origin Origin `xml:"origin"`
+	Parent struct {
+		Link string `xml:"link,attr"`
+	} `xml:"parent"`
+	Child struct {
+		Link string `xml:"link,attr"`
+	} `xml:"child"`
+	Axis *struct {
+		XYZ Vector `xml:"xyz,attr"`
+	} `xml:"axis"`
+	Limit *Limit `xml:"limit"`
+}
+
+//Limit is in radians for revolute joints and meters for prismatic ones
+type Limit struct {
+	Lower    float32 `xml:"lower,attr"`
+	Upper    float32 `xml:"upper,attr"`
+	Effort   float32 `xml:"effort,attr"`
+	Velocity float32 `xml:"velocity,attr"`
+}
+
+//axis returns the joint's axis
+func (j *Joint) axis() [3]float32 {
+	if j.Axis == nil {
+		return [3]float32{1, 0, 0}
+	}
+	return j.Axis.XYZ
+}
+
+//Vector is 3 space separated numbers
+type Vector [3]float32
+
+func (v *Vector) UnmarshalXMLAttr(attr xml.Attr) error {
+	fields := strings.Fields(attr.Value)
+	if len(fields) != 3 {
+		return fmt.Errorf("%w: %s should have 3 values", ErrFormat, attr.Name.Local)
+	}
+	for i := range v {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrFormat, attr.Name.Local, err)
+		}
+		v[i] = float32(f)
+	}
+	return nil
+}
+
+//Parse reads a URDF file and checks its links and joints form a single tree
+func Parse(r io.Reader) (*Description, error) {
+	d := &Description{}
+	if err := xml.NewDecoder(r).Decode(d); err != nil {
+		if errors.Is(err, ErrFormat) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if _, err := d.root(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+//link returns the link with the given name, or nil
+func (d *Description) link(name string) *Link {
+	for i := range d.Links {
+		if d.Links[i].Name == name {
+			return &d.Links[i]
+		}
+	}
+	return nil
+}
+
+//root returns the link that isn't the child of any joint, checking that every link
+// has a single parent and can be reached from the root
+func (d *Description) root() (*Link, error) {
+	names := make(map[string]bool, len(d.Links))
+	for _, link := range d.Links {
+		if names[link.Name] {
+			return nil, fmt.Errorf("%w: link %q is defined twice", ErrFormat, link.Name)
+		}
+		names[link.Name] = true
+	}
+
+	parents := make(map[string]string)
+	jointNames := make(map[string]bool, len(d.Joints))
+	for _, joint := range d.Joints {
+		if jointNames[joint.Name] {
+			return nil, fmt.Errorf("%w: joint %q is defined twice", ErrFormat, joint.Name)
+		}
+		jointNames[joint.Name] = true
+		if !names[joint.Parent.Link] || !names[joint.Child.Link] {
+			return nil, fmt.Errorf("%w: joint %q connects a link that doesn't exist", ErrFormat, joint.Name)
+		}
+		if _, ok := parents[joint.Child.Link]; ok {
+			return nil, fmt.Errorf("%w: link %q has more than one parent", ErrFormat, joint.Child.Link)
+		}
+		parents[joint.Child.Link] = joint.Parent.Link
+	}
+
+	var root *Link
+	for i := range d.Links {
+		if _, ok := parents[d.Links[i].Name]; !ok {
+			if root != nil {
+				return nil, fmt.Errorf("%w: links %q and %q both have no parent", ErrFormat,
+					root.Name, d.Links[i].Name)
+			}
+			root = &d.Links[i]
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: no root link", ErrFormat)
+	}
+
+	//with one root and a parent for everything else, a link that doesn't reach the
+	// root is part of a loop
+	for name := range names {
+		for steps := 0; name != root.Name; steps++ {
+			if steps > len(d.Links) {
+				return nil, fmt.Errorf("%w: joints form a loop", ErrFormat)
+			}
+			name = parents[name]
+		}
+	}
+	return root, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package urdf
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+//robot builds a URDF document from the link names and parent child pairs of joints
+func robot(links []string, joints ...[2]string) string {
+	b := &strings.Builder{}
+	b.WriteString(`<robot name="test">`)
+	for _, link := range links {
+		b.WriteString(`<link name="` + link + `"/>`)
+	}
+	for _, joint := range joints {
+		b.WriteString(`<joint name="` + joint[0] + `-` + joint[1] + `" type="fixed">` +
+			`<parent link="` + joint[0] + `"/><child link="` + joint[1] + `"/></joint>`)
+	}
+	b.WriteString(`</robot>`)
+	return b.String()
+}
+
+func TestRoot(t *testing.T) {
+	tests := []struct {
+		name string
+		urdf string
+		root string //empty if the description should be rejected
+	}{
+		{"single link", robot([]string{"base"}), "base"},
+		{"chain", robot([]string{"base", "arm", "hand"}, [2]string{"base", "arm"}, [2]string{"arm", "hand"}), "base"},
+		{"root listed last", robot([]string{"hand", "arm", "base"}, [2]string{"arm", "hand"}, [2]string{"base", "arm"}), "base"},
+		{"branches", robot([]string{"base", "left", "right"}, [2]string{"base", "left"}, [2]string{"base", "right"}), "base"},
+
+		{"no links", robot(nil), ""},
+		{"duplicate link", robot([]string{"base", "arm", "arm"}, [2]string{"base", "arm"}), ""},
+		{"duplicate joint", robot([]string{"base", "arm"}, [2]string{"base", "arm"}, [2]string{"base", "arm"}), ""},
+		{"missing parent", robot([]string{"arm"}, [2]string{"base", "arm"}), ""},
+		{"missing child", robot([]string{"base"}, [2]string{"base", "arm"}), ""},
+		{"two parents", robot([]string{"a", "b", "c"}, [2]string{"a", "c"}, [2]string{"b", "c"}), ""},
+		{"two roots", robot([]string{"a", "b"}), ""},
+		{"loop with no root", robot([]string{"a", "b"}, [2]string{"a", "b"}, [2]string{"b", "a"}), ""},
+		{"loop beside the root", robot([]string{"base", "a", "b", "c"},
+			[2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"c", "a"}), ""},
+		{"link is its own parent", robot([]string{"base", "a"}, [2]string{"a", "a"}), ""},
+	}
+
+	for _, test := range tests {
+		d, err := Parse(strings.NewReader(test.urdf))
+		if test.root == "" {
+			if !errors.Is(err, ErrFormat) {
+				t.Errorf("%s: got error %v, want ErrFormat", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		root, err := d.root()
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if root.Name != test.root {
+			t.Errorf("%s: root is %q, want %q", test.name, root.Name, test.root)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+/*
+#include "Newton.h"
+#include "callback.h"
+#include <stdlib.h>
+*/
+import "C"
+import (
+	"math"
+	"runtime/cgo"
+	"sync"
+	"unsafe"
+)
+
+//User mesh collisions are kept apart from the rest of the callbacks, so the
+// conversion of faces into Newton's polygon soup is only paid for by worlds
+// that create one.  Each user mesh gets its own cgo.Handle as its Newton
+// userData, which is released when Newton destroys the collision.
+
+//MeshCollisionCollideDesc describes the region a body needs faces for, in the user
+// mesh's space
+type MeshCollisionCollideDesc struct {
+	M_boxP0             *[4]float32
+	M_boxP1             *[4]float32
+	M_boxDistanceTravel *[4]float32
+	M_threadNumber      int
+	M_skinThickness     float32
+	M_objBody           *Body
+	M_polySoupBody      *Body
+	M_objCollision      *Collision
+	M_polySoupCollision *Collision
+}
+
+//Face is a convex polygon of a user mesh, with its vertices wound counter clockwise
+// when seen from the front.  Attribute is reported as the face id in contacts.  Faces
+// with fewer than 3 vertices are ignored
+type Face struct {
+	Vertices  []Vec3
+	Attribute int
+}
+
+//UserMesh supplies the geometry of a user mesh collision on demand.  Its methods are
+// called from Newton's update, possibly from several threads at once, and everything is
+// in the collision's space
+type UserMesh interface {
+	//CollideFaces returns the faces that could touch the box in desc
+	CollideFaces(desc *MeshCollisionCollideDesc) []Face
+	//RayHit returns the fraction of the way from p0 to p1 of the first face hit, with the
+	// face's normal and attribute.  A fraction greater than 1 is a miss
+	RayHit(p0, p1 Vec3) (t float32, normal Vec3, faceID int)
+	//AABB returns the bounds of the whole mesh
+	AABB() (p0, p1 Vec3)
+}
+
+//userMesh holds a UserMesh and the C buffers its faces are passed to Newton in,
+// one set for each thread that asks for them
+type userMesh struct {
+	mesh UserMesh
+
+	sync.Mutex
+	buffers map[int]*faceBuffer
+}
+
+type faceBuffer struct {
+	vertex     []C.dFloat
+	indexCount []C.int
+	index      []C.int
+}
+
+//grow returns a C array with room for size values, reusing old if it's big enough
+func grow[T any](old []T, size int) []T {
+	if size <= cap(old) {
+		return old[:size]
+	}
+	if cap(old) > 0 {
+		C.free(unsafe.Pointer(unsafe.SliceData(old)))
+	}
+	var v T
+	size = max(size, 2*cap(old))
+	ptr := C.malloc(C.size_t(uintptr(size) * unsafe.Sizeof(v)))
+	return unsafe.Slice((*T)(ptr), size)
+}
+
+func (b *faceBuffer) free() {
+	for _, ptr := range []unsafe.Pointer{unsafe.Pointer(unsafe.SliceData(b.vertex)),
+		unsafe.Pointer(unsafe.SliceData(b.indexCount)), unsafe.Pointer(unsafe.SliceData(b.index))} {
+		if ptr != nil {
+			C.free(ptr)
+		}
+	}
+}
+
+//buffer returns the buffers for the thread
+func (u *userMesh) buffer(thread int) *faceBuffer {
+	u.Lock()
+	defer u.Unlock()
+	b, ok := u.buffers[thread]
+	if !ok {
+		b = &faceBuffer{}
+		u.buffers[thread] = b
+	}
+	return b
+}
+
+func userMeshFromUserData(userData unsafe.Pointer) *userMesh {
+	return cgo.Handle(uintptr(userData)).Value().(*userMesh)
+}
+
+//CreateUserMeshCollision creates a static collision whose faces come from mesh.  Newton
+// asks for the faces near a body each time it collides with the mesh, so the geometry
+// can be generated or streamed in as it's needed
+func (w *World) CreateUserMeshCollision(mesh UserMesh, shapeID int) (*Collision, error) {
+	p0, p1 := mesh.AABB()
+	h := cgo.NewHandle(&userMesh{mesh: mesh, buffers: make(map[int]*faceBuffer)})
+
+	handle := C.CreateUserMeshCollision(w.ptr(), (*C.dFloat)(&p0[0]), (*C.dFloat)(&p1[0]), C.uintptr_t(h),
+		C.int(shapeID))
+	if handle == nil {
+		h.Delete()
+	}
+	return createdCollision(owner(w.handle), handle, "user mesh")
+}
+
+//export goUserMeshCollide
+func goUserMeshCollide(desc *C.NewtonUserMeshCollisionCollideDesc) {
+	u := userMeshFromUserData(desc.m_userData)
+
+	faces := u.mesh.CollideFaces(&MeshCollisionCollideDesc{
+		M_boxP0:             go4Floats(&desc.m_boxP0[0]),
+		M_boxP1:             go4Floats(&desc.m_boxP1[0]),
+		M_boxDistanceTravel: go4Floats(&desc.m_boxDistanceTravel[0]),
+		M_threadNumber:      int(desc.m_threadNumber),
+		M_skinThickness:     float32(desc.m_skinThickness),
+		M_objBody:           newBody(desc.m_objBody),
+		M_polySoupBody:      newBody(desc.m_polySoupBody),
+		M_objCollision:      newCollision(nil, desc.m_objCollision),
+		M_polySoupCollision: newCollision(nil, desc.m_polySoupCollision),
+	})
+
+	//faces with fewer than 3 vertices have no normal and are skipped
+	valid := make([]Face, 0, len(faces))
+	for _, face := range faces {
+		if len(face.Vertices) >= 3 {
+			valid = append(valid, face)
+		}
+	}
+	faces = valid
+
+	vertexCount, indexCount := faceBufferSizes(faces)
+	b := u.buffer(int(desc.m_threadNumber))
+	b.vertex = grow(b.vertex, vertexCount*3)
+	b.indexCount = grow(b.indexCount, len(faces))
+	b.index = grow(b.index, indexCount)
+	fillFaces(faces, b.vertex, b.indexCount, b.index)
+
+	desc.m_faceCount = C.int(len(faces))
+	desc.m_vertexStrideInBytes = C.int(3 * unsafe.Sizeof(C.dFloat(0)))
+	desc.m_vertex = unsafe.SliceData(b.vertex)
+	desc.m_faceIndexCount = unsafe.SliceData(b.indexCount)
+	desc.m_faceVertexIndex = unsafe.SliceData(b.index)
+}
+
+//faceBufferSizes returns the number of vertices and indices Newton needs for the faces
+func faceBufferSizes(faces []Face) (vertexCount, indexCount int) {
+	for _, face := range faces {
+		//the vertices, then the face normal
+		vertexCount += len(face.Vertices) + 1
+		//the vertex indices, attribute, normal, an edge normal for each vertex and the
+		// face size
+		indexCount += len(face.Vertices)*2 + 3
+	}
+	return vertexCount, indexCount
+}
+
+//fillFaces writes the faces into buffers sized by faceBufferSizes, in the layout of
+// Newton's polygon soup.  It's generic so the layout can be checked without C arrays
+func fillFaces[F ~float32 | ~float64, I ~int32](faces []Face, vertex []F, indexCount, index []I) {
+	v, i := 0, 0
+	for f, face := range faces {
+		n := len(face.Vertices)
+		for k, p := range face.Vertices {
+			vertex[(v+k)*3] = F(p[0])
+			vertex[(v+k)*3+1] = F(p[1])
+			vertex[(v+k)*3+2] = F(p[2])
+			index[i+k] = I(v + k)
+		}
+		normal := faceNormal(face.Vertices)
+		vertex[(v+n)*3] = F(normal[0])
+		vertex[(v+n)*3+1] = F(normal[1])
+		vertex[(v+n)*3+2] = F(normal[2])
+
+		index[i+n] = I(face.Attribute)
+		index[i+n+1] = I(v + n)
+		//faces are treated as separate, so every edge uses the face normal
+		for k := 0; k < n; k++ {
+			index[i+n+2+k] = I(v + n)
+		}
+		index[i+2*n+2] = I(faceSize(face.Vertices))
+
+		indexCount[f] = I(n)
+		v += n + 1
+		i += 2*n + 3
+	}
+}
+
+//faceNormal returns the unit normal of a polygon, using Newell's method so any
+// concave or nearly collinear vertices still give a sensible answer
+func faceNormal(vertices []Vec3) Vec3 {
+	var normal Vec3
+	for k, p := range vertices {
+		q := vertices[(k+1)%len(vertices)]
+		normal[0] += (p[1] - q[1]) * (p[2] + q[2])
+		normal[1] += (p[2] - q[2]) * (p[0] + q[0])
+		normal[2] += (p[0] - q[0]) * (p[1] + q[1])
+	}
+	return normal.Normalize()
+}
+
+//faceSize is the largest distance between two of the vertices, rounded up
+func faceSize(vertices []Vec3) int {
+	var size float32
+	for k, p := range vertices {
+		for _, q := range vertices[k+1:] {
+			size = max(size, p.Sub(q).Len())
+		}
+	}
+	return int(math.Ceil(float64(size)))
+}
+
+//export goUserMeshRayHit
+func goUserMeshRayHit(desc *C.NewtonUserMeshCollisionRayHitDesc) C.dFloat {
+	u := userMeshFromUserData(desc.m_userData)
+
+	t, normal, faceID := u.mesh.RayHit(Vec3(*go3Floats(&desc.m_p0[0])), Vec3(*go3Floats(&desc.m_p1[0])))
+	if t <= 1 {
+		desc.m_normalOut[0] = C.dFloat(normal[0])
+		desc.m_normalOut[1] = C.dFloat(normal[1])
+		desc.m_normalOut[2] = C.dFloat(normal[2])
+		desc.m_userIdOut = C.int(faceID)
+	}
+	return C.dFloat(t)
+}
+
+//export goUserMeshAABBTest
+func goUserMeshAABBTest(userData unsafe.Pointer, boxP0, boxP1 *C.dFloat) C.int {
+	u := userMeshFromUserData(userData)
+
+	p0, p1 := u.mesh.AABB()
+	q0, q1 := go3Floats(boxP0), go3Floats(boxP1)
+	for i := 0; i < 3; i++ {
+		if q1[i] < p0[i] || q0[i] > p1[i] {
+			return 0
+		}
+	}
+	return 1
+}
+
+//export goUserMeshDestroy
+func goUserMeshDestroy(userData unsafe.Pointer) {
+	h := cgo.Handle(uintptr(userData))
+	u := h.Value().(*userMesh)
+	h.Delete()
+
+	u.Lock()
+	defer u.Unlock()
+	for _, b := range u.buffers {
+		b.free()
+	}
+	u.buffers = nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2012 Tim Shannon. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+package newton
+
+import (
+	"reflect"
+	"testing"
+)
+
+//quad is a unit square in the XZ plane, counter clockwise seen from above
+var quad = []Vec3{{0, 0, 0}, {0, 0, 1}, {1, 0, 1}, {1, 0, 0}}
+
+func TestFaceNormal(t *testing.T) {
+	tests := []struct {
+		name     string
+		vertices []Vec3
+		want     Vec3
+	}{
+		{"quad", quad, Vec3{0, 1, 0}},
+		{"quad from below", []Vec3{quad[3], quad[2], quad[1], quad[0]}, Vec3{0, -1, 0}},
+		{"triangle", []Vec3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}}, Vec3{0, 0, 1}},
+		//Newell's method averages over the whole outline
+		{"concave", []Vec3{{0, 0, 0}, {0, 0, 2}, {1, 0, 1}, {2, 0, 2}, {2, 0, 0}}, Vec3{0, 1, 0}},
+	}
+
+	for _, test := range tests {
+		if got := faceNormal(test.vertices); !nearFloats(got[:], test.want[:]) {
+			t.Errorf("%s: normal %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFaceSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		vertices []Vec3
+		want     int
+	}{
+		{"unit quad", quad, 2}, //a diagonal of 1.41
+		{"3 4 5 triangle", []Vec3{{0, 0, 0}, {3, 0, 0}, {0, 0, 4}}, 5},
+		{"long quad", []Vec3{{0, 0, 0}, {0, 0, 1}, {2, 0, 1}, {2, 0, 0}}, 3}, //a diagonal of 2.24
+	}
+
+	for _, test := range tests {
+		if got := faceSize(test.vertices); got != test.want {
+			t.Errorf("%s: size %d, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+func TestFillFaces(t *testing.T) {
+	faces := []Face{
+		{Vertices: []Vec3{{0, 0, 0}, {0, 0, 1}, {1, 0, 0}}, Attribute: 7},
+		{Vertices: quad, Attribute: 9},
+	}
+
+	vertexCount, indexCount := faceBufferSizes(faces)
+	if vertexCount != 3+1+4+1 || indexCount != 2*3+3+2*4+3 {
+		t.Fatalf("buffer sizes %d vertices and %d indices, want 9 and 20", vertexCount, indexCount)
+	}
+
+	vertex := make([]float32, vertexCount*3)
+	counts := make([]int32, len(faces))
+	index := make([]int32, indexCount)
+	fillFaces(faces, vertex, counts, index)
+
+	wantVertex := []float32{
+		0, 0, 0, 0, 0, 1, 1, 0, 0, //the triangle
+		0, 1, 0, //and its normal
+		0, 0, 0, 0, 0, 1, 1, 0, 1, 1, 0, 0, //the quad
+		0, 1, 0, //and its normal
+	}
+	//each face is its n vertex indices, attribute, normal, n edge normals and its size
+	wantIndex := []int32{
+		0, 1, 2, 7, 3, 3, 3, 3, 2,
+		4, 5, 6, 7, 9, 8, 8, 8, 8, 8, 2,
+	}
+	if !nearFloats(vertex, wantVertex) {
+		t.Errorf("vertices %v, want %v", vertex, wantVertex)
+	}
+	if !reflect.DeepEqual(counts, []int32{3, 4}) {
+		t.Errorf("index counts %v, want [3 4]", counts)
+	}
+	if !reflect.DeepEqual(index, wantIndex) {
+		t.Errorf("indices %v, want %v", index, wantIndex)
+	}
+}